@@ -22,149 +22,191 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
+	"fmt"
 	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
 
-	"github.com/electricface/go-gir3/gi"
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
+	"github.com/electricface/go-gir3/cmd/girgen/xmlp"
+	"github.com/electricface/go-gir3/cmd/internal/flagutil"
 )
 
-func checkFi(fi *gi.FunctionInfo) {
-	name := fi.Name()
-	log.Println("function:", name)
-
-	num := fi.NumArg()
-	for i := 0; i < num; i++ {
-		argInfo := fi.Arg(i)
-		transfer := argInfo.OwnershipTransfer()
-		dir := argInfo.Direction()
-
-		argName := argInfo.Name()
-		argTypeInfo := argInfo.Type()
-
-		tag := argTypeInfo.Tag()
-
-		isPtr := argTypeInfo.IsPointer()
-
-		log.Printf("arg %s, ts: %v, dir: %v, isPtr: %v, type.tag: %s", argName, transfer, dir, isPtr, tag)
-
-		argTypeInfo.Unref()
-		argInfo.Unref()
-	}
-
-	retTypeInfo := fi.ReturnType()
-	retTransfer := fi.CallerOwns()
-	log.Printf("return ts: %v isPtr: %v, tag: %v", retTransfer, retTypeInfo.IsPointer(), retTypeInfo.Tag())
-	retTypeInfo.Unref()
-}
-
 var optNamespace string
 var optVersion string
-
-var showMap = make(map[string]bool)
+var optJSON bool
+var optGirOnly bool
+var optGirDirs flagutil.Strings
+var optGoDir string
 
 func init() {
 	flag.StringVar(&optNamespace, "n", "", "namespace")
 	flag.StringVar(&optVersion, "v", "", "version")
+	flag.BoolVar(&optJSON, "json", false, "output json")
+	flag.BoolVar(&optGirOnly, "gir-only", false, "read type information from gir files only, no typelib files needed")
+	flag.Var(&optGirDirs, "gir-dir", "directory to search for gir files, can be repeated")
+	flag.StringVar(&optGoDir, "go-dir", "", "directory of the generated go package, used to show go signatures")
+}
+
+const usageText = `usage: check -n namespace [-v version] [options] command [args]
+
+commands:
+  list [-type types] [regexp]  list infos of the namespace, types are separated by comma,
+                               such as function,object
+  show name                    show details of an info or its members, such as Gtk.Widget.show
+  search regexp                search infos and members in all loaded namespaces
+  deps                         show dependencies of the namespace
+
+options:
+`
+
+func usage() {
+	fmt.Fprint(flag.CommandLine.Output(), usageText)
+	flag.PrintDefaults()
 }
 
 func main() {
-	showMap["struct"] = true
+	flag.Usage = usage
 	flag.Parse()
+	if optNamespace == "" || flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
 
-	repo := gi.DefaultRepository()
-	_, err := repo.Require(optNamespace, optVersion, gi.REPOSITORY_LOAD_FLAG_LAZY)
+	namespaces, err := loadNamespaces()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	num := repo.NumInfo(optNamespace)
-	for i := 0; i < num; i++ {
-		bi := repo.Info(optNamespace, i)
-		name := bi.Name()
-		switch bi.Type() {
-		case gi.INFO_TYPE_FUNCTION:
-			if !showMap["func"] {
-				break
-			}
+	cmd := flag.Arg(0)
+	args := flag.Args()[1:]
+	switch cmd {
+	case "list":
+		err = cmdList(args)
+	case "show":
+		err = cmdShow(args, namespaces)
+	case "search":
+		err = cmdSearch(args, namespaces)
+	case "deps":
+		err = cmdDeps()
+	default:
+		err = fmt.Errorf("unknown command %q", cmd)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
 
-			log.Println(name, "FUNCTION")
-			fi := gi.ToFunctionInfo(bi)
-			checkFi(fi)
-		case gi.INFO_TYPE_CALLBACK:
-		case gi.INFO_TYPE_STRUCT:
-			if !showMap["struct"] {
-				break
-			}
-			log.Println(name, "STRUCT")
+// 已加载的名字空间的版本，键是名字空间
+var nsVersions = make(map[string]string)
 
-			si := gi.ToStructInfo(bi)
-			num := si.NumMethod()
-			for j := 0; j < num; j++ {
-				fi := si.Method(j)
-				checkFi(fi)
-			}
+// loadNamespaces 加载 optNamespace 和它直接、间接依赖的名字空间，返回它们的名字，optNamespace 在最前面。
+func loadNamespaces() ([]string, error) {
+	// 先指定的目录优先
+	for i := len(optGirDirs) - 1; i >= 0; i-- {
+		xmlp.PrependSearchPath(optGirDirs[i])
+	}
+	if optGirOnly {
+		gi.SetGirOnly()
+	}
+	repo := gi.DefaultRepository()
+	err := repo.Require(optNamespace, optVersion, gi.REPOSITORY_LOAD_FLAG_LAZY)
+	if err != nil {
+		return nil, err
+	}
 
-		case gi.INFO_TYPE_BOXED:
-		case gi.INFO_TYPE_ENUM:
-		case gi.INFO_TYPE_FLAGS:
-		case gi.INFO_TYPE_OBJECT:
-			if !showMap["object"] {
-				break
-			}
-			log.Println(name, "OBJECT")
-			oi := gi.ToObjectInfo(bi)
-			num := oi.NumMethod()
-			for j := 0; j < num; j++ {
-				fi := oi.Method(j)
-				checkFi(fi)
+	nsVersions[optNamespace] = optVersion
+	namespaces := []string{optNamespace}
+	loaded := map[string]struct{}{optNamespace: {}}
+	for i := 0; i < len(namespaces); i++ {
+		for _, dep := range repo.ImmediateDependencies(namespaces[i]) {
+			ns, version := splitNsVersion(dep)
+			if _, ok := loaded[ns]; ok {
+				continue
 			}
-		case gi.INFO_TYPE_INTERFACE:
-			if !showMap["interface"] {
-				break
-			}
-			log.Println(name, "INTERFACE")
-			info := gi.ToInterfaceInfo(bi)
-			num := info.NumMethod()
-			for j := 0; j < num; j++ {
-				fi := info.Method(j)
-				checkFi(fi)
+			err = repo.Require(ns, version, gi.REPOSITORY_LOAD_FLAG_LAZY)
+			if err != nil {
+				return nil, err
 			}
+			loaded[ns] = struct{}{}
+			nsVersions[ns] = version
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces, nil
+}
 
-		case gi.INFO_TYPE_CONSTANT:
-		case gi.INFO_TYPE_UNION:
-			if !showMap["union"] {
-				break
-			}
-			log.Println(name, "UNION")
-
-		case gi.INFO_TYPE_VALUE:
-		case gi.INFO_TYPE_SIGNAL:
-		case gi.INFO_TYPE_VFUNC:
-		case gi.INFO_TYPE_PROPERTY:
-		case gi.INFO_TYPE_FIELD:
-		case gi.INFO_TYPE_ARG:
-		case gi.INFO_TYPE_TYPE:
+// splitNsVersion 把 GObject-2.0 拆分为 GObject 和 2.0
+func splitNsVersion(nsVersion string) (ns, version string) {
+	idx := strings.LastIndex(nsVersion, "-")
+	if idx < 0 {
+		return nsVersion, ""
+	}
+	return nsVersion[:idx], nsVersion[idx+1:]
+}
+
+// output 输出结果，有 -json 参数时输出 v 的 json 格式，否则调用 printText 输出文本。
+func output(v interface{}, printText func(w *tabwriter.Writer)) {
+	if optJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err := enc.Encode(v)
+		if err != nil {
+			log.Fatal(err)
 		}
-		bi.Unref()
+		return
 	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	printText(w)
+	err := w.Flush()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+type deps struct {
+	Name string  `json:"name"`
+	Deps []*deps `json:"deps,omitempty"`
 }
 
-//switch bi.Type() {
-//case gi.INFO_TYPE_FUNCTION:
-//case gi.INFO_TYPE_CALLBACK:
-//case gi.INFO_TYPE_STRUCT:
-//case gi.INFO_TYPE_BOXED:
-//case gi.INFO_TYPE_ENUM:
-//case gi.INFO_TYPE_FLAGS:
-//case gi.INFO_TYPE_OBJECT:
-//case gi.INFO_TYPE_INTERFACE:
-//case gi.INFO_TYPE_CONSTANT:
-//case gi.INFO_TYPE_UNION:
-//case gi.INFO_TYPE_VALUE:
-//case gi.INFO_TYPE_SIGNAL:
-//case gi.INFO_TYPE_VFUNC:
-//case gi.INFO_TYPE_PROPERTY:
-//case gi.INFO_TYPE_FIELD:
-//case gi.INFO_TYPE_ARG:
-//case gi.INFO_TYPE_TYPE:
-//}
+func cmdDeps() error {
+	repo := gi.DefaultRepository()
+	var getDeps func(ns string, parents map[string]bool) []*deps
+	getDeps = func(ns string, parents map[string]bool) []*deps {
+		var result []*deps
+		for _, dep := range repo.ImmediateDependencies(ns) {
+			depNs, _ := splitNsVersion(dep)
+			node := &deps{Name: dep}
+			if !parents[depNs] {
+				parents[depNs] = true
+				node.Deps = getDeps(depNs, parents)
+				delete(parents, depNs)
+			}
+			result = append(result, node)
+		}
+		return result
+	}
+
+	name := optNamespace
+	if optVersion != "" {
+		name += "-" + optVersion
+	}
+	root := &deps{
+		Name: name,
+		Deps: getDeps(optNamespace, map[string]bool{optNamespace: true}),
+	}
+	output(root, func(w *tabwriter.Writer) {
+		var pDeps func(node *deps, indent string)
+		pDeps = func(node *deps, indent string) {
+			fmt.Fprintf(w, "%s%s\n", indent, node.Name)
+			for _, dep := range node.Deps {
+				pDeps(dep, indent+"  ")
+			}
+		}
+		pDeps(root, "")
+	})
+	return nil
+}
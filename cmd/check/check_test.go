@@ -0,0 +1,132 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"go/token"
+	"os"
+	"testing"
+
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
+	"github.com/electricface/go-gir3/cmd/internal/flagutil"
+	"github.com/stretchr/testify/assert"
+)
+
+var _namespaces []string
+
+func TestMain(m *testing.M) {
+	optNamespace = "Check"
+	optVersion = "1.0"
+	optGirOnly = true
+	optGirDirs = flagutil.Strings{"testdata"}
+	var err error
+	_namespaces, err = loadNamespaces()
+	if err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func Test_splitNsVersion(t *testing.T) {
+	ns, version := splitNsVersion("GObject-2.0")
+	assert.Equal(t, "GObject", ns)
+	assert.Equal(t, "2.0", version)
+
+	ns, version = splitNsVersion("Foo")
+	assert.Equal(t, "Foo", ns)
+	assert.Equal(t, "", version)
+}
+
+func TestResolveName(t *testing.T) {
+	bi, memberName, err := resolveName("Check.Thing.watch", _namespaces)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "Thing", bi.Name())
+		assert.Equal(t, "watch", memberName)
+		bi.Unref()
+	}
+
+	bi, memberName, err = resolveName("Point", _namespaces)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "Point", bi.Name())
+		assert.Equal(t, "", memberName)
+		bi.Unref()
+	}
+
+	_, _, err = resolveName("Check.NotExist", _namespaces)
+	assert.NotNil(t, err)
+}
+
+func TestShowCallbackArgs(t *testing.T) {
+	bi, memberName, err := resolveName("Check.Thing.watch", _namespaces)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer bi.Unref()
+
+	var d *detail
+	forEachMember(bi, func(mi *gi.BaseInfo) {
+		if mi.Name() == memberName && mi.Type() == gi.INFO_TYPE_FUNCTION {
+			d = newDetail(mi, bi)
+		}
+	})
+	if !assert.NotNil(t, d) {
+		return
+	}
+	assert.Equal(t, "check_thing_watch", d.Symbol)
+	assert.Contains(t, d.Flags, "method")
+	assert.Equal(t, "guint check_thing_watch(CheckThing* thing, CheckFunc func, "+
+		"gpointer user_data, CheckDestroyNotify notify)", d.CSignature)
+
+	if assert.Len(t, d.Args, 3) {
+		fn := d.Args[0]
+		assert.Equal(t, "func", fn.Name)
+		assert.Equal(t, "notified", fn.Scope)
+		assert.Equal(t, 1, fn.Closure)
+		assert.Equal(t, 2, fn.Destroy)
+
+		userData := d.Args[1]
+		assert.True(t, userData.Nullable)
+		assert.Equal(t, -1, userData.Closure)
+	}
+}
+
+func Test_addGoSignatures(t *testing.T) {
+	src := `package check
+
+// check_thing_get_size
+//
+// [ result ] trans: nothing
+//
+func (v Thing) GetSize() (result int32) {
+	return
+}
+
+// not a symbol
+func foo() {}
+`
+	result := make(map[string]string)
+	err := addGoSignatures(result, token.NewFileSet(), "check_auto.go", []byte(src))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"check_thing_get_size": "func (v Thing) GetSize() (result int32)",
+	}, result)
+}
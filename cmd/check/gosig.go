@@ -0,0 +1,107 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+// 生成的 Go 函数的签名，键是 C 符号，由 loadGoSignatures 设置
+var goSignatures map[string]string
+
+// getGoSignature 返回 -go-dir 参数指定的包中 C 函数 symbol 对应的 Go 函数的签名，找不到时返回空字符串。
+func getGoSignature(symbol string) string {
+	if optGoDir == "" {
+		return ""
+	}
+	if goSignatures == nil {
+		var err error
+		goSignatures, err = loadGoSignatures(optGoDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	return goSignatures[symbol]
+}
+
+// loadGoSignatures 解析目录 dir 中 girgen 生成的 Go 代码，返回 C 符号到 Go 函数签名的映射。
+// 生成的函数的文档注释的第一行是 C 符号。
+func loadGoSignatures(dir string) (map[string]string, error) {
+	filenames, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string)
+	fset := token.NewFileSet()
+	for _, filename := range filenames {
+		if strings.HasSuffix(filename, "_test.go") {
+			continue
+		}
+		src, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		err = addGoSignatures(result, fset, filename, src)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func addGoSignatures(result map[string]string, fset *token.FileSet, filename string, src []byte) error {
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	for _, decl := range f.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Doc == nil {
+			continue
+		}
+		symbol := strings.TrimSpace(strings.TrimPrefix(fd.Doc.List[0].Text, "//"))
+		if symbol == "" || strings.ContainsAny(symbol, " \t") {
+			continue
+		}
+		if _, ok := result[symbol]; ok {
+			continue
+		}
+
+		// 只输出函数头
+		sig := &ast.FuncDecl{Recv: fd.Recv, Name: fd.Name, Type: fd.Type}
+		var buf bytes.Buffer
+		err = printer.Fprint(&buf, fset, sig)
+		if err != nil {
+			return err
+		}
+		result[symbol] = buf.String()
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
+)
+
+// item 是 list 和 search 命令输出的一项
+type item struct {
+	// 完整的名字，比如 Gtk.Widget 或 Gtk.Widget.show
+	Name string `json:"name"`
+	// 顶层的 info 为 info 类型，比如 object，成员为 method、constructor、function、vfunc、signal、
+	// property、field 或 value
+	Kind string `json:"kind"`
+	// 函数的 C 符号或者类型的 GType 名字
+	Symbol     string `json:"symbol,omitempty"`
+	Deprecated bool   `json:"deprecated,omitempty"`
+}
+
+func printItems(items []*item) {
+	output(items, func(w *tabwriter.Writer) {
+		for _, it := range items {
+			var deprecated string
+			if it.Deprecated {
+				deprecated = "deprecated"
+			}
+			printRow(w, it.Kind, it.Name, it.Symbol, deprecated)
+		}
+	})
+}
+
+// printRow 输出表格的一行，去掉末尾的空列
+func printRow(w *tabwriter.Writer, columns ...string) {
+	for len(columns) > 0 && columns[len(columns)-1] == "" {
+		columns = columns[:len(columns)-1]
+	}
+	fmt.Fprintln(w, strings.Join(columns, "\t"))
+}
+
+func newItem(bi *gi.BaseInfo) *item {
+	return &item{
+		Name:       bi.Namespace() + "." + bi.Name(),
+		Kind:       bi.Type().String(),
+		Symbol:     getSymbol(bi),
+		Deprecated: bi.IsDeprecated(),
+	}
+}
+
+// getSymbol 返回函数的 C 符号，或者类型的 GType 名字
+func getSymbol(bi *gi.BaseInfo) string {
+	switch bi.Type() {
+	case gi.INFO_TYPE_FUNCTION:
+		return gi.ToFunctionInfo(bi).Symbol()
+	case gi.INFO_TYPE_BOXED, gi.INFO_TYPE_ENUM, gi.INFO_TYPE_FLAGS, gi.INFO_TYPE_INTERFACE,
+		gi.INFO_TYPE_OBJECT, gi.INFO_TYPE_STRUCT, gi.INFO_TYPE_UNION:
+		return gi.ToRegisteredTypeInfo(bi).TypeName()
+	}
+	return ""
+}
+
+// getMemberKind 返回成员 mi 的种类
+func getMemberKind(mi *gi.BaseInfo) string {
+	switch mi.Type() {
+	case gi.INFO_TYPE_FUNCTION:
+		flags := gi.ToFunctionInfo(mi).Flags()
+		if flags&gi.FUNCTION_IS_CONSTRUCTOR != 0 {
+			return "constructor"
+		} else if flags&gi.FUNCTION_IS_METHOD != 0 {
+			return "method"
+		}
+		return "function"
+	case gi.INFO_TYPE_VALUE:
+		return "value"
+	}
+	return mi.Type().String()
+}
+
+// forEachMember 对结构体、联合体、对象、接口和枚举 bi 的每个成员调用 fn，
+// 依次是字段、枚举值、方法、属性、信号和虚函数。
+func forEachMember(bi *gi.BaseInfo, fn func(mi *gi.BaseInfo)) {
+	each := func(num int, get func(i int) *gi.BaseInfo) {
+		for i := 0; i < num; i++ {
+			mi := get(i)
+			fn(mi)
+			mi.Unref()
+		}
+	}
+
+	switch bi.Type() {
+	case gi.INFO_TYPE_STRUCT, gi.INFO_TYPE_BOXED:
+		si := gi.ToStructInfo(bi)
+		each(si.NumField(), func(i int) *gi.BaseInfo { return gi.ToBaseInfo(si.Field(i)) })
+		each(si.NumMethod(), func(i int) *gi.BaseInfo { return gi.ToBaseInfo(si.Method(i)) })
+
+	case gi.INFO_TYPE_UNION:
+		ui := gi.ToUnionInfo(bi)
+		each(ui.NumField(), func(i int) *gi.BaseInfo {
+			fi := ui.Field(i)
+			return gi.ToBaseInfo(&fi)
+		})
+		each(ui.NumMethod(), func(i int) *gi.BaseInfo { return gi.ToBaseInfo(ui.Method(i)) })
+
+	case gi.INFO_TYPE_ENUM, gi.INFO_TYPE_FLAGS:
+		ei := gi.ToEnumInfo(bi)
+		each(ei.NumValue(), func(i int) *gi.BaseInfo { return gi.ToBaseInfo(ei.Value(i)) })
+		each(ei.NumMethod(), func(i int) *gi.BaseInfo { return gi.ToBaseInfo(ei.Method(i)) })
+
+	case gi.INFO_TYPE_OBJECT:
+		oi := gi.ToObjectInfo(bi)
+		each(oi.NumField(), func(i int) *gi.BaseInfo { return gi.ToBaseInfo(oi.Field(i)) })
+		each(oi.NumMethod(), func(i int) *gi.BaseInfo { return gi.ToBaseInfo(oi.Method(i)) })
+		each(oi.NumProperty(), func(i int) *gi.BaseInfo { return gi.ToBaseInfo(oi.Property(i)) })
+		each(oi.NumSignal(), func(i int) *gi.BaseInfo { return gi.ToBaseInfo(oi.Signal(i)) })
+		each(oi.NumVFunc(), func(i int) *gi.BaseInfo { return gi.ToBaseInfo(oi.VFunc(i)) })
+
+	case gi.INFO_TYPE_INTERFACE:
+		ii := gi.ToInterfaceInfo(bi)
+		each(ii.NumMethod(), func(i int) *gi.BaseInfo { return gi.ToBaseInfo(ii.Method(i)) })
+		each(ii.NumProperty(), func(i int) *gi.BaseInfo { return gi.ToBaseInfo(ii.Property(i)) })
+		each(ii.NumSignal(), func(i int) *gi.BaseInfo { return gi.ToBaseInfo(ii.Signal(i)) })
+		each(ii.NumVFunc(), func(i int) *gi.BaseInfo { return gi.ToBaseInfo(ii.VFunc(i)) })
+	}
+}
+
+func newMemberItem(bi, mi *gi.BaseInfo) *item {
+	it := &item{
+		Name:       bi.Namespace() + "." + bi.Name() + "." + mi.Name(),
+		Kind:       getMemberKind(mi),
+		Deprecated: mi.IsDeprecated(),
+	}
+	if mi.Type() == gi.INFO_TYPE_FUNCTION {
+		it.Symbol = gi.ToFunctionInfo(mi).Symbol()
+	}
+	return it
+}
+
+// parseInfoTypes 解析逗号分隔的 info 类型，比如 function,object
+func parseInfoTypes(str string) (map[gi.InfoType]struct{}, error) {
+	if str == "" {
+		return nil, nil
+	}
+	result := make(map[gi.InfoType]struct{})
+	for _, name := range strings.Split(str, ",") {
+		found := false
+		for t := gi.INFO_TYPE_INVALID; t <= gi.INFO_TYPE_UNRESOLVED; t++ {
+			if t.String() == name {
+				result[t] = struct{}{}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown info type %q", name)
+		}
+	}
+	return result, nil
+}
+
+func cmdList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	optType := fs.String("type", "", "info types separated by comma, such as function,object")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	types, err := parseInfoTypes(*optType)
+	if err != nil {
+		return err
+	}
+	var reg *regexp.Regexp
+	if fs.NArg() > 0 {
+		reg, err = regexp.Compile(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+	}
+
+	repo := gi.DefaultRepository()
+	items := []*item{}
+	num := repo.NumInfo(optNamespace)
+	for i := 0; i < num; i++ {
+		bi := repo.Info(optNamespace, i)
+		_, typeOk := types[bi.Type()]
+		if (types == nil || typeOk) && (reg == nil || reg.MatchString(bi.Name())) {
+			items = append(items, newItem(bi))
+		}
+		bi.Unref()
+	}
+	printItems(items)
+	return nil
+}
+
+func cmdSearch(args []string, namespaces []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: search regexp")
+	}
+	reg, err := regexp.Compile(args[0])
+	if err != nil {
+		return err
+	}
+	match := func(it *item) bool {
+		return reg.MatchString(it.Name) || (it.Symbol != "" && reg.MatchString(it.Symbol))
+	}
+
+	repo := gi.DefaultRepository()
+	items := []*item{}
+	for _, ns := range namespaces {
+		num := repo.NumInfo(ns)
+		for i := 0; i < num; i++ {
+			bi := repo.Info(ns, i)
+			if it := newItem(bi); match(it) {
+				items = append(items, it)
+			}
+			forEachMember(bi, func(mi *gi.BaseInfo) {
+				if it := newMemberItem(bi, mi); match(it) {
+					items = append(items, it)
+				}
+			})
+			bi.Unref()
+		}
+	}
+	printItems(items)
+	return nil
+}
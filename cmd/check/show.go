@@ -0,0 +1,608 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
+	"github.com/electricface/go-gir3/cmd/girgen/xmlp"
+)
+
+// detail 是 show 命令输出的一项，不同种类的 info 使用不同的字段。
+type detail struct {
+	item
+
+	// 函数、回调、信号和虚函数
+	Flags       []string     `json:"flags,omitempty"`
+	CSignature  string       `json:"cSignature,omitempty"`
+	GoSignature string       `json:"goSignature,omitempty"`
+	Args        []*argDetail `json:"args,omitempty"`
+	Return      *argDetail   `json:"return,omitempty"`
+
+	// 字段、属性和常量的类型
+	Type string `json:"type,omitempty"`
+	// 常量和枚举值的值
+	Value string `json:"value,omitempty"`
+
+	// 结构体、联合体、对象、接口和枚举
+	Parent     string   `json:"parent,omitempty"`
+	Interfaces []string `json:"interfaces,omitempty"`
+	Size       int      `json:"size,omitempty"`
+	Members    []*item  `json:"members,omitempty"`
+}
+
+type argDetail struct {
+	// 参数的 index，不包括方法的实例参数，返回值为 -1
+	Index           int    `json:"index"`
+	Name            string `json:"name,omitempty"`
+	Direction       string `json:"direction"` // in, out, inout 或 return
+	Type            string `json:"type"`
+	CType           string `json:"cType"`
+	Transfer        string `json:"transfer"`
+	Nullable        bool   `json:"nullable"`
+	Optional        bool   `json:"optional,omitempty"`
+	CallerAllocates bool   `json:"callerAllocates,omitempty"`
+	Scope           string `json:"scope,omitempty"`
+	// 回调的 user_data 参数和 destroy 参数的 index，没有则为 -1
+	Closure int `json:"closure"`
+	Destroy int `json:"destroy"`
+}
+
+func cmdShow(args []string, namespaces []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: show name")
+	}
+	bi, memberName, err := resolveName(args[0], namespaces)
+	if err != nil {
+		return err
+	}
+	defer bi.Unref()
+
+	var details []*detail
+	if memberName == "" {
+		details = append(details, newDetail(bi, nil))
+	} else {
+		forEachMember(bi, func(mi *gi.BaseInfo) {
+			if mi.Name() == memberName {
+				details = append(details, newDetail(mi, bi))
+			}
+		})
+		if len(details) == 0 {
+			return fmt.Errorf("not found %s", args[0])
+		}
+	}
+
+	output(details, func(w *tabwriter.Writer) {
+		for i, d := range details {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			printDetail(w, d)
+		}
+	})
+	return nil
+}
+
+// resolveName 解析 Gtk.Widget.show 这样的名字，返回顶层的 info 和成员的名字，
+// 名字中没有名字空间时使用 -n 参数指定的。
+func resolveName(name string, namespaces []string) (*gi.BaseInfo, string, error) {
+	parts := strings.Split(name, ".")
+	ns := optNamespace
+	for _, ns0 := range namespaces {
+		if ns0 == parts[0] {
+			ns = ns0
+			parts = parts[1:]
+			break
+		}
+	}
+	if len(parts) == 0 || len(parts) > 2 {
+		return nil, "", fmt.Errorf("invalid name %q", name)
+	}
+
+	bi := gi.DefaultRepository().FindByName(ns, parts[0])
+	if bi.IsNil() {
+		return nil, "", fmt.Errorf("not found %s.%s", ns, parts[0])
+	}
+	var memberName string
+	if len(parts) == 2 {
+		memberName = parts[1]
+	}
+	return bi, memberName, nil
+}
+
+// newDetail 返回 bi 的详细信息，container 是成员所在的类型，顶层的 info 为 nil。
+func newDetail(bi, container *gi.BaseInfo) *detail {
+	var d detail
+	if container == nil {
+		d.item = *newItem(bi)
+	} else {
+		d.item = *newMemberItem(container, bi)
+	}
+
+	switch bi.Type() {
+	case gi.INFO_TYPE_FUNCTION, gi.INFO_TYPE_CALLBACK, gi.INFO_TYPE_SIGNAL, gi.INFO_TYPE_VFUNC:
+		setCallableDetail(&d, gi.ToCallableInfo(bi), container)
+
+	case gi.INFO_TYPE_CONSTANT:
+		ci := gi.ToConstantInfo(bi)
+		ti := ci.Type()
+		d.Type = describeType(ti)
+		ti.Unref()
+		d.Value = fmt.Sprintf("%v", ci.Value())
+
+	case gi.INFO_TYPE_FIELD:
+		fi := gi.ToFieldInfo(bi)
+		ti := fi.Type()
+		d.Type = describeType(ti)
+		ti.Unref()
+		flags := fi.Flags()
+		if flags&gi.FIELD_IS_READABLE != 0 {
+			d.Flags = append(d.Flags, "readable")
+		}
+		if flags&gi.FIELD_IS_WRITABLE != 0 {
+			d.Flags = append(d.Flags, "writable")
+		}
+
+	case gi.INFO_TYPE_PROPERTY:
+		pi := gi.ToPropertyInfo(bi)
+		ti := pi.Type()
+		d.Type = describeType(ti)
+		ti.Unref()
+		flags := pi.Flags()
+		for _, f := range []struct {
+			flag gi.ParamFlags
+			name string
+		}{
+			{gi.PARAM_READABLE, "readable"},
+			{gi.PARAM_WRITABLE, "writable"},
+			{gi.PARAM_CONSTRUCT, "construct"},
+			{gi.PARAM_CONSTRUCT_ONLY, "construct-only"},
+		} {
+			if flags&f.flag != 0 {
+				d.Flags = append(d.Flags, f.name)
+			}
+		}
+
+	case gi.INFO_TYPE_VALUE:
+		ei := gi.ToEnumInfo(container)
+		for i := 0; i < ei.NumValue(); i++ {
+			vi := ei.Value(i)
+			if vi.Name() == bi.Name() {
+				d.Value = fmt.Sprintf("%v", vi.Value())
+			}
+			vi.Unref()
+		}
+
+	case gi.INFO_TYPE_OBJECT:
+		oi := gi.ToObjectInfo(bi)
+		if parent := oi.Parent(); parent != nil {
+			d.Parent = parent.Namespace() + "." + parent.Name()
+			parent.Unref()
+		}
+		for i := 0; i < oi.NumInterface(); i++ {
+			ii := oi.Interface(i)
+			d.Interfaces = append(d.Interfaces, ii.Namespace()+"."+ii.Name())
+			ii.Unref()
+		}
+		setMembers(&d, bi)
+
+	case gi.INFO_TYPE_INTERFACE:
+		ii := gi.ToInterfaceInfo(bi)
+		for i := 0; i < ii.NumPrerequisite(); i++ {
+			pi := ii.Prerequisite(i)
+			d.Interfaces = append(d.Interfaces, pi.Namespace()+"."+pi.Name())
+			pi.Unref()
+		}
+		setMembers(&d, bi)
+
+	case gi.INFO_TYPE_STRUCT, gi.INFO_TYPE_BOXED:
+		d.Size = gi.ToStructInfo(bi).Size()
+		setMembers(&d, bi)
+
+	case gi.INFO_TYPE_UNION:
+		d.Size = gi.ToUnionInfo(bi).Size()
+		setMembers(&d, bi)
+
+	case gi.INFO_TYPE_ENUM, gi.INFO_TYPE_FLAGS:
+		d.Type = gi.ToEnumInfo(bi).StorageType().String()
+		setMembers(&d, bi)
+	}
+	return &d
+}
+
+func setMembers(d *detail, bi *gi.BaseInfo) {
+	forEachMember(bi, func(mi *gi.BaseInfo) {
+		d.Members = append(d.Members, newMemberItem(bi, mi))
+	})
+}
+
+func setCallableDetail(d *detail, ci *gi.CallableInfo, container *gi.BaseInfo) {
+	var xFunc *xmlp.FunctionInfo
+	isMethod := false
+	throws := false
+	switch ci.Type() {
+	case gi.INFO_TYPE_FUNCTION:
+		fi := gi.ToFunctionInfo(ci)
+		flags := fi.Flags()
+		for _, f := range []struct {
+			flag gi.FunctionInfoFlags
+			name string
+		}{
+			{gi.FUNCTION_IS_METHOD, "method"},
+			{gi.FUNCTION_IS_CONSTRUCTOR, "constructor"},
+			{gi.FUNCTION_IS_GETTER, "getter"},
+			{gi.FUNCTION_IS_SETTER, "setter"},
+			{gi.FUNCTION_WRAPS_VFUNC, "wraps-vfunc"},
+			{gi.FUNCTION_THROWS, "throws"},
+		} {
+			if flags&f.flag != 0 {
+				d.Flags = append(d.Flags, f.name)
+			}
+		}
+		isMethod = flags&gi.FUNCTION_IS_METHOD != 0
+		throws = flags&gi.FUNCTION_THROWS != 0
+		xFunc = getGirFunction(ci.Namespace(), fi.Symbol())
+		d.GoSignature = getGoSignature(fi.Symbol())
+
+	case gi.INFO_TYPE_VFUNC:
+		flags := gi.ToVFuncInfo(ci).Flags()
+		for _, f := range []struct {
+			flag gi.VFuncInfoFlags
+			name string
+		}{
+			{gi.VFUNC_MUST_CHAIN_UP, "must-chain-up"},
+			{gi.VFUNC_MUST_OVERRIDE, "must-override"},
+			{gi.VFUNC_MUST_NOT_OVERRIDE, "must-not-override"},
+			{gi.VFUNC_THROWS, "throws"},
+		} {
+			if flags&f.flag != 0 {
+				d.Flags = append(d.Flags, f.name)
+			}
+		}
+		throws = flags&gi.VFUNC_THROWS != 0
+	}
+
+	// girParam 返回 GIR 文件中第 i 个参数，-1 为返回值，找不到时返回 nil
+	girParam := func(i int) *xmlp.Parameter {
+		if xFunc == nil {
+			return nil
+		}
+		if i < 0 {
+			return xFunc.ReturnValue
+		}
+		if xFunc.Parameters == nil || i >= len(xFunc.Parameters.Parameters) {
+			return nil
+		}
+		return xFunc.Parameters.Parameters[i]
+	}
+
+	var cParams []string
+	switch ci.Type() {
+	case gi.INFO_TYPE_SIGNAL, gi.INFO_TYPE_VFUNC:
+		// 第一个参数是实例
+		cParams = append(cParams, getCTypeOfInfo(container)+"* self")
+	case gi.INFO_TYPE_FUNCTION:
+		if isMethod {
+			cType := getCTypeOfInfo(container) + "*"
+			name := "self"
+			if xFunc != nil && xFunc.Parameters != nil && xFunc.Parameters.InstanceParameter != nil {
+				instParam := xFunc.Parameters.InstanceParameter
+				name = instParam.Name
+				if t := getGirCType(instParam); t != "" {
+					cType = t
+				}
+			}
+			cParams = append(cParams, cType+" "+name)
+		}
+	}
+
+	numArgs := ci.NumArg()
+	for i := 0; i < numArgs; i++ {
+		argInfo := ci.Arg(i)
+		ti := argInfo.Type()
+		dir := argInfo.Direction()
+		ad := &argDetail{
+			Index:           i,
+			Name:            argInfo.Name(),
+			Direction:       dir.String(),
+			Type:            describeType(ti),
+			CType:           getCType(ti),
+			Transfer:        argInfo.OwnershipTransfer().String(),
+			Nullable:        argInfo.MayBeNil(),
+			Optional:        argInfo.IsOptional(),
+			CallerAllocates: argInfo.IsCallerAllocates(),
+			Closure:         argInfo.Closure(),
+			Destroy:         argInfo.Destroy(),
+		}
+		if dir != gi.DIRECTION_IN && !ad.CallerAllocates {
+			ad.CType += "*"
+		}
+		if t := getGirCType(girParam(i)); t != "" {
+			ad.CType = t
+		}
+		if scope := argInfo.Scope(); scope != gi.SCOPE_TYPE_INVALID {
+			ad.Scope = scope.String()
+		}
+		d.Args = append(d.Args, ad)
+		cParams = append(cParams, ad.CType+" "+ad.Name)
+		ti.Unref()
+		argInfo.Unref()
+	}
+	if throws {
+		cParams = append(cParams, "GError** error")
+	}
+	if ci.Type() == gi.INFO_TYPE_SIGNAL {
+		cParams = append(cParams, "gpointer user_data")
+	}
+
+	retTi := ci.ReturnType()
+	d.Return = &argDetail{
+		Index:     -1,
+		Direction: "return",
+		Type:      describeType(retTi),
+		CType:     getCType(retTi),
+		Transfer:  ci.CallerOwns().String(),
+		Nullable:  ci.MayReturnNil(),
+		Closure:   -1,
+		Destroy:   -1,
+	}
+	if t := getGirCType(girParam(-1)); t != "" {
+		d.Return.CType = t
+	}
+	retTi.Unref()
+
+	var cName string
+	switch ci.Type() {
+	case gi.INFO_TYPE_FUNCTION:
+		cName = gi.ToFunctionInfo(ci).Symbol()
+	case gi.INFO_TYPE_CALLBACK:
+		cName = "(*" + getCTypeOfInfo(gi.ToBaseInfo(ci)) + ")"
+	case gi.INFO_TYPE_SIGNAL:
+		cName = "(*handler)"
+	case gi.INFO_TYPE_VFUNC:
+		cName = "(*" + ci.Name() + ")"
+	}
+	if len(cParams) == 0 {
+		cParams = append(cParams, "void")
+	}
+	d.CSignature = fmt.Sprintf("%s %s(%s)", d.Return.CType, cName, strings.Join(cParams, ", "))
+}
+
+func printDetail(w *tabwriter.Writer, d *detail) {
+	fmt.Fprintf(w, "%s (%s)", d.Name, d.Kind)
+	if d.Symbol != "" {
+		fmt.Fprintf(w, " %s", d.Symbol)
+	}
+	if d.Deprecated {
+		fmt.Fprint(w, " deprecated")
+	}
+	fmt.Fprintln(w)
+
+	if len(d.Flags) > 0 {
+		fmt.Fprintf(w, "flags: %s\n", strings.Join(d.Flags, ", "))
+	}
+	if d.Type != "" {
+		fmt.Fprintf(w, "type: %s\n", d.Type)
+	}
+	if d.Value != "" {
+		fmt.Fprintf(w, "value: %s\n", d.Value)
+	}
+	if d.Parent != "" {
+		fmt.Fprintf(w, "parent: %s\n", d.Parent)
+	}
+	if len(d.Interfaces) > 0 {
+		fmt.Fprintf(w, "interfaces: %s\n", strings.Join(d.Interfaces, ", "))
+	}
+	if d.Size > 0 {
+		fmt.Fprintf(w, "size: %d\n", d.Size)
+	}
+
+	if d.CSignature != "" {
+		fmt.Fprintf(w, "C: %s\n", d.CSignature)
+		if d.GoSignature != "" {
+			fmt.Fprintf(w, "Go: %s\n", d.GoSignature)
+		}
+
+		fmt.Fprintln(w, "\nindex\tname\tdir\ttype\tc type\ttransfer\tnullable\toptional\t"+
+			"caller-allocates\tscope\tclosure\tdestroy")
+		for _, ad := range append(d.Args, d.Return) {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%v\t%v\t%v\t%s\t%d\t%d\n", ad.Index, ad.Name,
+				ad.Direction, ad.Type, ad.CType, ad.Transfer, ad.Nullable, ad.Optional,
+				ad.CallerAllocates, ad.Scope, ad.Closure, ad.Destroy)
+		}
+	}
+
+	if len(d.Members) > 0 {
+		fmt.Fprintln(w, "\nmembers:")
+		for _, m := range d.Members {
+			printRow(w, "  "+m.Kind, m.Name, m.Symbol)
+		}
+	}
+}
+
+// describeType 返回类型 ti 的描述，比如 array<gint32>[length=1]、Gtk.Widget* (object)
+func describeType(ti *gi.TypeInfo) string {
+	tag := ti.Tag()
+	switch tag {
+	case gi.TYPE_TAG_VOID:
+		if ti.IsPointer() {
+			return "gpointer"
+		}
+		return "void"
+
+	case gi.TYPE_TAG_UTF8, gi.TYPE_TAG_FILENAME:
+		return tag.String()
+
+	case gi.TYPE_TAG_ARRAY:
+		elemStr := describeParamType(ti, 0)
+		switch ti.ArrayType() {
+		case gi.ARRAY_TYPE_ARRAY:
+			return "GArray<" + elemStr + ">"
+		case gi.ARRAY_TYPE_PTR_ARRAY:
+			return "GPtrArray<" + elemStr + ">"
+		case gi.ARRAY_TYPE_BYTE_ARRAY:
+			return "GByteArray"
+		}
+		var attrs []string
+		if n := ti.ArrayLength(); n >= 0 {
+			attrs = append(attrs, fmt.Sprintf("length=%d", n))
+		}
+		if n := ti.ArrayFixedSize(); n >= 0 {
+			attrs = append(attrs, fmt.Sprintf("fixed-size=%d", n))
+		}
+		if ti.IsZeroTerminated() {
+			attrs = append(attrs, "zero-terminated")
+		}
+		str := "array<" + elemStr + ">"
+		if len(attrs) > 0 {
+			str += "[" + strings.Join(attrs, ", ") + "]"
+		}
+		return str
+
+	case gi.TYPE_TAG_INTERFACE:
+		bi := ti.Interface()
+		defer bi.Unref()
+		str := bi.Namespace() + "." + bi.Name()
+		if ti.IsPointer() {
+			str += "*"
+		}
+		return str + " (" + bi.Type().String() + ")"
+
+	case gi.TYPE_TAG_GLIST:
+		return "GList<" + describeParamType(ti, 0) + ">"
+	case gi.TYPE_TAG_GSLIST:
+		return "GSList<" + describeParamType(ti, 0) + ">"
+	case gi.TYPE_TAG_GHASH:
+		return "GHashTable<" + describeParamType(ti, 0) + ", " + describeParamType(ti, 1) + ">"
+	case gi.TYPE_TAG_ERROR:
+		return "GError*"
+	}
+
+	str := tag.String()
+	if ti.IsPointer() {
+		str += "*"
+	}
+	return str
+}
+
+func describeParamType(ti *gi.TypeInfo, n int) string {
+	pti := ti.ParamType(n)
+	if pti == nil {
+		return "?"
+	}
+	defer pti.Unref()
+	return describeType(pti)
+}
+
+// getCType 根据类型信息推导出 C 类型，不知道是否有 const 修饰
+func getCType(ti *gi.TypeInfo) string {
+	tag := ti.Tag()
+	switch tag {
+	case gi.TYPE_TAG_VOID:
+		if ti.IsPointer() {
+			return "gpointer"
+		}
+		return "void"
+	case gi.TYPE_TAG_UTF8, gi.TYPE_TAG_FILENAME:
+		return "gchar*"
+	case gi.TYPE_TAG_ARRAY:
+		switch ti.ArrayType() {
+		case gi.ARRAY_TYPE_ARRAY:
+			return "GArray*"
+		case gi.ARRAY_TYPE_PTR_ARRAY:
+			return "GPtrArray*"
+		case gi.ARRAY_TYPE_BYTE_ARRAY:
+			return "GByteArray*"
+		}
+		elem := ti.ParamType(0)
+		if elem == nil {
+			return "gpointer"
+		}
+		defer elem.Unref()
+		return getCType(elem) + "*"
+	case gi.TYPE_TAG_INTERFACE:
+		bi := ti.Interface()
+		defer bi.Unref()
+		cType := getCTypeOfInfo(bi)
+		if ti.IsPointer() && bi.Type() != gi.INFO_TYPE_CALLBACK {
+			cType += "*"
+		}
+		return cType
+	case gi.TYPE_TAG_GLIST:
+		return "GList*"
+	case gi.TYPE_TAG_GSLIST:
+		return "GSList*"
+	case gi.TYPE_TAG_GHASH:
+		return "GHashTable*"
+	case gi.TYPE_TAG_ERROR:
+		return "GError*"
+	}
+
+	// 基本类型的名字就是 C 类型，比如 gint32
+	cType := tag.String()
+	if ti.IsPointer() {
+		cType += "*"
+	}
+	return cType
+}
+
+// getCTypeOfInfo 返回结构体、对象、枚举、回调等 bi 的 C 类型名
+func getCTypeOfInfo(bi *gi.BaseInfo) string {
+	return gi.DefaultRepository().CPrefix(bi.Namespace()) + bi.Name()
+}
+
+// getGirCType 返回 GIR 文件中参数的 c:type，没有则返回空字符串
+func getGirCType(p *xmlp.Parameter) string {
+	if p == nil {
+		return ""
+	}
+	if p.Type != nil {
+		return p.Type.CType
+	}
+	if p.Array != nil {
+		return p.Array.CType
+	}
+	return ""
+}
+
+// 已经尝试过加载 GIR 文件的名字空间
+var girLoaded = make(map[string]bool)
+
+// getGirFunction 在名字空间 ns 的 GIR 文件中查找函数，GIR 文件不存在时返回 nil。
+func getGirFunction(ns, symbol string) *xmlp.FunctionInfo {
+	repo := xmlp.GetLoadedRepo(ns)
+	if repo == nil && !girLoaded[ns] {
+		girLoaded[ns] = true
+		// 不指定版本时，typelib 模式下也可以加载名字空间，这时只能不使用 GIR 文件中的 C 类型。
+		if version := nsVersions[ns]; version != "" {
+			repo, _ = xmlp.Load(ns, version)
+		}
+	}
+	if repo == nil {
+		return nil
+	}
+	return repo.GetFunction(symbol)
+}
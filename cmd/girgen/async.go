@@ -0,0 +1,362 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
+)
+
+// funcSignature 记录 pFunction 生成的 Go 函数的签名，用于生成组合多个函数的包装函数。
+type funcSignature struct {
+	fnName      string
+	symbol      string   // C 函数的符号
+	receiver    string   // 比如 (v File)，为空表示不是方法
+	receiverVar string   // 接收者的变量名
+	params      []string // 形参列表，元素是 "名字 类型"
+	paramArgIdx []int    // 形参对应的 C 函数参数的 index
+	retParams   []string // 返回参数列表，元素是 "名字 类型"
+	deprecated  bool
+	// GCancellable* 参数的 index，没有则为 -1
+	cancellableIdx int
+
+	// 以下只用于 _async 函数
+	cbIdx      int    // GAsyncReadyCallback 参数的 index
+	closureIdx int    // 回调的 user_data 参数的 index
+	resField   string // 回调结构体中 GAsyncResult 字段的名字
+}
+
+// 键是 identifyName，比如 File.load_contents_async
+var _funcSignatures = make(map[string]*funcSignature)
+
+func splitNameType(nameType string) (name, type0 string) {
+	parts := strings.SplitN(nameType, " ", 2)
+	if len(parts) < 2 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// getAsyncPair 判断 identifyName 对应的函数是否为 _async 或 _finish 函数，
+// 如果它们成对出现，返回 _async 函数和 _finish 函数的签名。
+func getAsyncPair(identifyName string) (asyncSig, finishSig *funcSignature) {
+	var base string
+	if strings.HasSuffix(identifyName, "_async") {
+		base = strings.TrimSuffix(identifyName, "_async")
+	} else if strings.HasSuffix(identifyName, "_finish") {
+		base = strings.TrimSuffix(identifyName, "_finish")
+	} else {
+		return nil, nil
+	}
+	asyncSig = _funcSignatures[base+"_async"]
+	finishSig = _funcSignatures[base+"_finish"]
+	if asyncSig == nil || finishSig == nil {
+		return nil, nil
+	}
+	return
+}
+
+// getAsyncCallbackArgIdx 返回 _async 函数的 GAsyncReadyCallback 参数和它的 user_data 参数的 index，
+// 以及回调结构体中 GAsyncResult 字段的名字。
+func getAsyncCallbackArgIdx(fi *gi.FunctionInfo) (cbIdx, closureIdx int, resField string) {
+	cbIdx, closureIdx = -1, -1
+	numArgs := fi.NumArg()
+	for i := 0; i < numArgs; i++ {
+		argInfo := fi.Arg(i)
+		argTypeInfo := argInfo.Type()
+		if argTypeInfo.Tag() == gi.TYPE_TAG_INTERFACE {
+			bi := argTypeInfo.Interface()
+			if bi.Type() == gi.INFO_TYPE_CALLBACK && bi.Namespace() == "Gio" &&
+				bi.Name() == "AsyncReadyCallback" {
+				cbIdx = i
+				closureIdx = argInfo.Closure()
+				cbInfo := gi.ToCallableInfo(bi)
+				if cbInfo.NumArg() > 1 {
+					resArg := cbInfo.Arg(1)
+					resField = "F_" + resArg.Name()
+					resArg.Unref()
+				}
+			}
+			bi.Unref()
+		}
+		argTypeInfo.Unref()
+		argInfo.Unref()
+	}
+	return
+}
+
+// pAsyncFunc 为一对 _async 和 _finish 函数生成阻塞式的包装函数 XXXContext，
+// 它调用 _async 函数，在回调中调用 _finish 函数，然后等待结果或者 ctx 结束。
+func pAsyncFunc(s *SourceFile, asyncSig, finishSig *funcSignature) {
+	if asyncSig.receiver != finishSig.receiver || len(finishSig.params) != 1 ||
+		!strings.HasSuffix(asyncSig.fnName, "Async") {
+		return
+	}
+	cbIdx, closureIdx, resField := asyncSig.cbIdx, asyncSig.closureIdx, asyncSig.resField
+	if cbIdx < 0 || closureIdx < 0 || resField == "" {
+		return
+	}
+	_, resType := splitNameType(finishSig.params[0])
+	if !strings.HasSuffix(resType, "AsyncResult") {
+		return
+	}
+
+	name := strings.TrimSuffix(asyncSig.fnName, "Async") + "Context"
+	// 如果同步函数已经生成了同名的变体，就不再生成
+	if !addContextFuncName(asyncSig.receiver, name) {
+		return
+	}
+
+	var varReg VarReg
+	if asyncSig.receiverVar != "" {
+		varReg.alloc(asyncSig.receiverVar)
+	}
+	var params []string
+	var callArgs []string
+	var varCancellable string
+	var cbVarLines []string
+	for i, param := range asyncSig.params {
+		paramName, type0 := splitNameType(param)
+		varReg.alloc(paramName)
+		switch asyncSig.paramArgIdx[i] {
+		case asyncSig.cancellableIdx:
+			// 由 ctx 创建
+			varCancellable = paramName
+			callArgs = append(callArgs, paramName)
+		case cbIdx:
+			// 回调参数的值不会被使用，回调函数通过 user_data 查找，所以传入零值。
+			cbVarLines = append(cbVarLines, fmt.Sprintf("var %v %v", paramName, type0))
+			callArgs = append(callArgs, paramName)
+		case closureIdx:
+			// 由 varFnId 替换
+			callArgs = append(callArgs, "")
+		default:
+			params = append(params, param)
+			callArgs = append(callArgs, paramName)
+		}
+	}
+
+	var retParams []string
+	var retNames []string
+	hasErr := false
+	for _, retParam := range finishSig.retParams {
+		retName, type0 := splitNameType(retParam)
+		varReg.alloc(retName)
+		if type0 == "error" {
+			hasErr = true
+		}
+		retParams = append(retParams, retParam)
+		retNames = append(retNames, retName)
+	}
+	varCtx := varReg.alloc("ctx")
+	varRelease := varReg.alloc("release")
+	var varErr string
+	if !hasErr {
+		varErr = varReg.alloc("err")
+		retParams = append(retParams, varErr+" error")
+	} else {
+		varErr = retNames[len(retNames)-1]
+	}
+	varResultType := varReg.alloc("asyncResult")
+	varCh := varReg.alloc("ch")
+	varFnId := varReg.alloc("fnId")
+	varArgs := varReg.alloc("args")
+	varRes := varReg.alloc("res")
+	varR := varReg.alloc("r")
+	for i := range callArgs {
+		if callArgs[i] == "" {
+			callArgs[i] = varFnId
+		}
+	}
+
+	callPrefix := ""
+	if asyncSig.receiverVar != "" {
+		callPrefix = asyncSig.receiverVar + "."
+	}
+	cbStructType := getPkgPrefix("Gio") + "AsyncReadyCallbackStruct"
+
+	s.AddGoImport("context")
+	s.GoBody.Pn("// %v 调用 %v 并等待 %v 的结果，返回时异步操作已经完成或者 %v 已经结束。",
+		name, asyncSig.fnName, finishSig.fnName, varCtx)
+	s.GoBody.Pn("// 回调由调用时线程默认的主上下文分派，所以需要有主循环在其他线程运行，不能在主循环的线程中调用。")
+	if asyncSig.deprecated {
+		pFuncDeprecated(s, asyncSig.symbol)
+	}
+	s.GoBody.Pn("func %v %v(%v) (%v) {", asyncSig.receiver, name,
+		strings.Join(append([]string{varCtx + " context.Context"}, params...), ", "),
+		strings.Join(retParams, ", "))
+
+	s.GoBody.Pn("type %v struct {", varResultType)
+	for _, retParam := range retParams {
+		s.GoBody.Pn(retParam)
+	}
+	s.GoBody.Pn("}") // end struct
+	if varCancellable != "" {
+		// ctx 结束时取消异步操作，异步操作持有 cancellable 的引用，所以可以在返回时释放它。
+		s.GoBody.Pn("%v, %v := %vCancellableFromContext(%v)", varCancellable, varRelease,
+			getPkgPrefix("Gio"), varCtx)
+		s.GoBody.Pn("defer %v()", varRelease)
+	}
+	// 带缓冲，ctx 结束后回调仍然可以发送结果
+	s.GoBody.Pn("%v := make(chan %v, 1)", varCh, varResultType)
+	s.GoBody.Pn("var %v unsafe.Pointer", varFnId)
+	s.GoBody.Pn("%v = gi.RegisterFunc(func(%v interface{}) {", varFnId, varArgs)
+	s.GoBody.Pn("gi.UnregisterFunc(%v)", varFnId)
+	s.GoBody.Pn("%v := %v.(*%v).%v", varRes, varArgs, cbStructType, resField)
+	s.GoBody.Pn("var %v %v", varR, varResultType)
+	var rFields []string
+	for _, retName := range retNames {
+		rFields = append(rFields, varR+"."+retName)
+	}
+	if len(rFields) > 0 {
+		s.GoBody.Pn("%v = %v%v(%v)", strings.Join(rFields, ", "), callPrefix, finishSig.fnName, varRes)
+	} else {
+		s.GoBody.Pn("%v%v(%v)", callPrefix, finishSig.fnName, varRes)
+	}
+	s.GoBody.Pn("%v <- %v", varCh, varR)
+	s.GoBody.Pn("})") // end RegisterFunc
+	for _, line := range cbVarLines {
+		s.GoBody.Pn(line)
+	}
+	s.GoBody.Pn("%v%v(%v)", callPrefix, asyncSig.fnName, strings.Join(callArgs, ", "))
+
+	s.GoBody.Pn("select {")
+	s.GoBody.Pn("case %v := <-%v:", varR, varCh)
+	var rExprs []string
+	for _, retParam := range retParams {
+		retName, _ := splitNameType(retParam)
+		rExprs = append(rExprs, varR+"."+retName)
+	}
+	s.GoBody.Pn("return %v", strings.Join(rExprs, ", "))
+	s.GoBody.Pn("case <-%v.Done():", varCtx)
+	s.GoBody.Pn("%v = %v.Err()", varErr, varCtx)
+	s.GoBody.Pn("return")
+	s.GoBody.Pn("}") // end select
+	s.GoBody.Pn("}") // end func
+}
+
+// getCancellableArgIdx 返回类型为 GCancellable* 的输入参数的 index，没有则返回 -1。
+func getCancellableArgIdx(fi *gi.FunctionInfo) int {
+	idx := -1
+	numArgs := fi.NumArg()
+	for i := 0; i < numArgs && idx < 0; i++ {
+		argInfo := fi.Arg(i)
+		argTypeInfo := argInfo.Type()
+		if argInfo.Direction() == gi.DIRECTION_IN && argTypeInfo.Tag() == gi.TYPE_TAG_INTERFACE {
+			bi := argTypeInfo.Interface()
+			if bi.Namespace() == "Gio" && bi.Name() == "Cancellable" {
+				idx = i
+			}
+			bi.Unref()
+		}
+		argTypeInfo.Unref()
+		argInfo.Unref()
+	}
+	return idx
+}
+
+// 已经生成的 XXXContext 函数，键是接收者类型加函数名，用于避免同步函数的变体和异步包装函数重名。
+var _contextFuncNames = make(map[string]struct{})
+
+// addContextFuncName 登记函数名，如果已经登记过了，返回 false。
+func addContextFuncName(receiver, name string) bool {
+	receiverType := ""
+	if receiver != "" {
+		_, receiverType = splitNameType(strings.Trim(receiver, "()"))
+	}
+	key := receiverType + "." + name
+	if _, ok := _contextFuncNames[key]; ok {
+		return false
+	}
+	_contextFuncNames[key] = struct{}{}
+	return true
+}
+
+// pCancellableFunc 为有 GCancellable* 参数的函数生成用 context.Context 代替它的变体 XXXContext。
+func pCancellableFunc(s *SourceFile, sig *funcSignature) {
+	name := sig.fnName + "Context"
+	if sig.cancellableIdx < 0 || !addContextFuncName(sig.receiver, name) {
+		return
+	}
+
+	var varReg VarReg
+	if sig.receiverVar != "" {
+		varReg.alloc(sig.receiverVar)
+	}
+	for _, param := range sig.params {
+		paramName, _ := splitNameType(param)
+		varReg.alloc(paramName)
+	}
+	for _, retParam := range sig.retParams {
+		retName, _ := splitNameType(retParam)
+		varReg.alloc(retName)
+	}
+	varCtx := varReg.alloc("ctx")
+	varRelease := varReg.alloc("release")
+
+	var params []string
+	var callArgs []string
+	var varCancellable string
+	for i, param := range sig.params {
+		paramName, _ := splitNameType(param)
+		if sig.paramArgIdx[i] == sig.cancellableIdx {
+			varCancellable = paramName
+		} else {
+			params = append(params, param)
+		}
+		callArgs = append(callArgs, paramName)
+	}
+	if varCancellable == "" {
+		return
+	}
+
+	callPrefix := ""
+	if sig.receiverVar != "" {
+		callPrefix = sig.receiverVar + "."
+	}
+	retParamsJoined := strings.Join(sig.retParams, ", ")
+	if len(sig.retParams) > 0 {
+		retParamsJoined = "(" + retParamsJoined + ")"
+	}
+
+	s.AddGoImport("context")
+	s.GoBody.Pn("// %v 和 %v 一样，但是用 %v 代替参数 %v，%v 结束时取消操作。",
+		name, sig.fnName, varCtx, varCancellable, varCtx)
+	if sig.deprecated {
+		pFuncDeprecated(s, sig.symbol)
+	}
+	s.GoBody.Pn("func %v %v(%v) %v {", sig.receiver, name,
+		strings.Join(append([]string{varCtx + " context.Context"}, params...), ", "),
+		retParamsJoined)
+	s.GoBody.Pn("%v, %v := %vCancellableFromContext(%v)", varCancellable, varRelease,
+		getPkgPrefix("Gio"), varCtx)
+	s.GoBody.Pn("defer %v()", varRelease)
+	callExpr := fmt.Sprintf("%v%v(%v)", callPrefix, sig.fnName, strings.Join(callArgs, ", "))
+	if len(sig.retParams) > 0 {
+		s.GoBody.Pn("return %v", callExpr)
+	} else {
+		s.GoBody.Pn(callExpr)
+	}
+	s.GoBody.Pn("}") // end func
+}
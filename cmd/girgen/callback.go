@@ -23,79 +23,221 @@ package main
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
-	"github.com/electricface/go-gir3/gi"
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
 )
 
+// getCallbackClosureIdx 返回回调类型 fi 中用于传递 user_data 的参数的位置，没有则返回 -1。
+func getCallbackClosureIdx(fi *gi.CallableInfo) int {
+	numArgs := fi.NumArg()
+	fallbackIdx := -1
+	for i := 0; i < numArgs; i++ {
+		argInfo := fi.Arg(i)
+		argTypeInfo := argInfo.Type()
+		isVoidPtr := argTypeInfo.Tag() == gi.TYPE_TAG_VOID && argTypeInfo.IsPointer()
+		closureIdx := argInfo.Closure()
+		argName := argInfo.Name()
+		argTypeInfo.Unref()
+		argInfo.Unref()
+
+		// 回调类型中 user_data 参数的 closure 指向它自己
+		if closureIdx >= 0 && closureIdx < numArgs {
+			closureArg := fi.Arg(closureIdx)
+			closureArgType := closureArg.Type()
+			ok := closureArgType.Tag() == gi.TYPE_TAG_VOID && closureArgType.IsPointer() &&
+				closureArg.Direction() == gi.DIRECTION_IN
+			closureArgType.Unref()
+			closureArg.Unref()
+			if ok {
+				return closureIdx
+			}
+		}
+
+		if fallbackIdx < 0 && isVoidPtr && argName == "user_data" {
+			fallbackIdx = i
+		}
+	}
+	return fallbackIdx
+}
+
 func pCallback(s *SourceFile, fi *gi.CallableInfo) {
 	name := fi.Name()
+	cSymbol := getCIdentifierPrefix(gi.ToBaseInfo(fi)) + name
 
 	var paramNameTypes []string
+	var paramNames []string
 	var cParamTypeNames []string
 	var fields []string
 	var fieldSetLines []string
+	// 在 Go 处理函数返回后，把 out 和 inout 参数的值写回 C 指针的语句
+	var writeBackLines []string
 
 	var varReg VarReg
+	closureIdx := getCallbackClosureIdx(fi)
+	var varSlot string
+	if closureIdx < 0 {
+		// 没有 user_data 参数，用跳板函数的序号找到 Go 函数
+		varSlot = varReg.alloc("slot")
+	}
+
+	// lenArgMap 的键是数组长度参数的 index，值是数组参数的 index，返回值的为 -1。
+	lenArgMap := make(map[int]int)
 	numArgs := fi.NumArg()
-	foundUserData := false
+	for i := 0; i < numArgs; i++ {
+		argInfo := fi.Arg(i)
+		argTypeInfo := argInfo.Type()
+		varReg.regParam(i, argInfo.Name())
+		if argTypeInfo.Tag() == gi.TYPE_TAG_ARRAY {
+			lenArgIdx := argTypeInfo.ArrayLength()
+			if lenArgIdx >= 0 {
+				lenArgMap[lenArgIdx] = i
+			}
+		}
+		argTypeInfo.Unref()
+		argInfo.Unref()
+	}
+	retTypeInfo := fi.ReturnType()
+	defer retTypeInfo.Unref()
+	if retTypeInfo.Tag() == gi.TYPE_TAG_ARRAY {
+		lenArgIdx := retTypeInfo.ArrayLength()
+		if lenArgIdx >= 0 {
+			lenArgMap[lenArgIdx] = -1
+		}
+	}
+
+	varFn := varReg.alloc("fn")
+	varArgs := varReg.alloc("args")
+
+	// getLenArg 返回数组长度参数的名字、方向和 cgo 类型
+	getLenArg := func(lenArgIdx int) (string, gi.Direction, string) {
+		lenArgInfo := fi.Arg(lenArgIdx)
+		lenTypeInfo := lenArgInfo.Type()
+		dir := lenArgInfo.Direction()
+		cgoType := "C." + getCTypeWithTag(lenTypeInfo.Tag())
+		lenTypeInfo.Unref()
+		lenArgInfo.Unref()
+		return varReg.getParam(lenArgIdx), dir, cgoType
+	}
+
+	// pWriteBackLen 生成把数组 fieldExpr 的长度写回长度参数的语句
+	pWriteBackLen := func(ti *gi.TypeInfo, fieldExpr, goType string) {
+		if ti.Tag() != gi.TYPE_TAG_ARRAY || ti.ArrayLength() < 0 || goType == "unsafe.Pointer" {
+			return
+		}
+		lenName, lenDir, lenCgoType := getLenArg(ti.ArrayLength())
+		if lenDir == gi.DIRECTION_IN {
+			return
+		}
+		writeBackLines = append(writeBackLines,
+			fmt.Sprintf("if %v != nil {", lenName),
+			fmt.Sprintf("*%v = %v(%v.Len)", lenName, lenCgoType, fieldExpr),
+			"}")
+	}
+
 	for i := 0; i < numArgs; i++ {
 		argInfo := fi.Arg(i)
 		argTypeInfo := argInfo.Type()
 
-		paramName := varReg.regParam(i, argInfo.Name())
+		paramName := varReg.getParam(i)
+		paramNames = append(paramNames, paramName)
+		fieldName := "F_" + paramName
+		fieldExpr := varArgs + "." + fieldName
+		_, isLenArg := lenArgMap[i]
 		dir := argInfo.Direction()
+		if dir == gi.DIRECTION_OUT && argInfo.IsCallerAllocates() {
+			// 由调用者分配内存的 out 参数，Go 处理函数直接修改它指向的内容。
+			dir = gi.DIRECTION_IN
+		}
+
 		switch dir {
 		case gi.DIRECTION_IN:
 			parseResult := parseCbArgTypeDirIn(paramName, argTypeInfo)
 			paramNameTypes = append(paramNameTypes, paramName+" "+parseResult.cgoType)
 			cParamTypeNames = append(cParamTypeNames, parseResult.cType+" "+paramName)
 
-			if argInfo.Name() == "user_data" && parseResult.cgoType == "C.gpointer" {
-				// is user_data param
-				foundUserData = true
-				continue
+			if i == closureIdx || isLenArg {
+				// 是 user_data 参数或者数组的长度参数
+				break
+			}
+			_report.addTodoType(newReportEntry("callback", cSymbol, i, argInfo.Name(),
+				dir.String(), argTypeInfo), parseResult.goType)
+
+			expr := parseResult.expr
+			if argTypeInfo.Tag() == gi.TYPE_TAG_ARRAY && argTypeInfo.ArrayLength() >= 0 &&
+				strings.HasSuffix(expr, "}") {
+				lenName, lenDir, _ := getLenArg(argTypeInfo.ArrayLength())
+				if lenDir == gi.DIRECTION_IN {
+					expr = strings.TrimSuffix(expr, "}") + fmt.Sprintf(", Len: int(%v)}", lenName)
+				}
 			}
 
-			fieldName := "F_" + paramName
-			fields = append(fields, fieldName+" "+parseResult.goType)
-
-			fieldSetLine := fmt.Sprintf("%v: %v,", fieldName, parseResult.expr)
-			fieldSetLines = append(fieldSetLines, fieldSetLine)
-
-		case gi.DIRECTION_OUT:
-			parseResult := parseCbArgTypeDirOut(paramName, argTypeInfo)
-			paramNameTypes = append(paramNameTypes, paramName+" "+parseResult.cgoType)
-			cParamTypeNames = append(cParamTypeNames, parseResult.cType+" "+paramName)
-
-			fieldName := "F_" + paramName
 			fields = append(fields, fieldName+" "+parseResult.goType)
+			fieldSetLines = append(fieldSetLines, fmt.Sprintf("%v: %v,", fieldName, expr))
 
-			fieldSetLine := fmt.Sprintf("%v: %v,", fieldName, parseResult.expr)
-			fieldSetLines = append(fieldSetLines, fieldSetLine)
+		case gi.DIRECTION_OUT, gi.DIRECTION_INOUT:
+			parseResult := parseCbArgTypeDirOut(fieldExpr, "(*"+paramName+")", argTypeInfo,
+				argInfo.OwnershipTransfer())
+			paramNameTypes = append(paramNameTypes, paramName+" *"+parseResult.cgoType)
+			cParamTypeNames = append(cParamTypeNames, parseResult.cType+"* "+paramName)
 
-		case gi.DIRECTION_INOUT:
-			parseResult := parseCbArgTypeDirInOut(paramName, argTypeInfo)
-			paramNameTypes = append(paramNameTypes, paramName+" "+parseResult.cgoType)
-			cParamTypeNames = append(cParamTypeNames, parseResult.cType+" "+paramName)
+			if isLenArg {
+				// 由数组参数负责写回长度
+				break
+			}
 
-			fieldName := "F_" + paramName
 			fields = append(fields, fieldName+" "+parseResult.goType)
-
-			fieldSetLine := fmt.Sprintf("%v: %v,", fieldName, parseResult.expr)
-			fieldSetLines = append(fieldSetLines, fieldSetLine)
+			if dir == gi.DIRECTION_INOUT {
+				expr := parseResult.goExpr
+				if argTypeInfo.Tag() == gi.TYPE_TAG_ARRAY && argTypeInfo.ArrayLength() >= 0 &&
+					strings.HasSuffix(expr, "}") {
+					lenName, lenDir, _ := getLenArg(argTypeInfo.ArrayLength())
+					lenExpr := lenName
+					if lenDir != gi.DIRECTION_IN {
+						lenExpr = "*" + lenName
+					}
+					expr = strings.TrimSuffix(expr, "}") + fmt.Sprintf(", Len: int(%v)}", lenExpr)
+				}
+				fieldSetLines = append(fieldSetLines, fmt.Sprintf("%v: %v,", fieldName, expr))
+			}
+			writeBackLines = append(writeBackLines,
+				fmt.Sprintf("if %v != nil {", paramName),
+				fmt.Sprintf("*%v = %v", paramName, parseResult.cExpr),
+				"}")
+			pWriteBackLen(argTypeInfo, fieldExpr, parseResult.goType)
 		}
 
 		argTypeInfo.Unref()
 		argInfo.Unref()
 	}
 
+	// 回调的返回值由 Go 处理函数设置到 F_result 字段
+	cRetType := "void"
+	cgoRetType := ""
+	var retExpr string
+	if !(retTypeInfo.Tag() == gi.TYPE_TAG_VOID && !retTypeInfo.IsPointer()) {
+		varResult := varReg.alloc("result")
+		fieldName := "F_" + varResult
+		fieldExpr := varArgs + "." + fieldName
+		parseResult := parseCbArgTypeDirOut(fieldExpr, "", retTypeInfo, fi.CallerOwns())
+		cRetType = parseResult.cType
+		cgoRetType = " " + parseResult.cgoType
+		retExpr = parseResult.cExpr
+		fields = append(fields, fieldName+" "+parseResult.goType)
+		pWriteBackLen(retTypeInfo, fieldExpr, parseResult.goType)
+	}
+
 	myFuncName := "my" + _optNamespace + name
 
-	s.CBody.Pn("extern void %s(%v);", myFuncName, strings.Join(cParamTypeNames, ", "))
-	s.CBody.Pn("static void* getPointer_%v() {", myFuncName)
-	s.CBody.Pn("return (void*)(%v);", myFuncName)
-	s.CBody.Pn("}")
+	if closureIdx >= 0 {
+		s.CBody.Pn("extern %s %s(%v);", cRetType, myFuncName, strings.Join(cParamTypeNames, ", "))
+		s.CBody.Pn("static void* getPointer_%v() {", myFuncName)
+		s.CBody.Pn("return (void*)(%v);", myFuncName)
+		s.CBody.Pn("}")
+	} else {
+		pCallbackTrampolines(s, myFuncName, cRetType, cParamTypeNames, paramNames)
+	}
 
 	s.GoBody.Pn("type %vStruct struct {", name)
 	for _, field := range fields {
@@ -103,79 +245,236 @@ func pCallback(s *SourceFile, fi *gi.CallableInfo) {
 	}
 	s.GoBody.Pn("}")
 
-	s.GoBody.Pn("func GetPointer_my%v() unsafe.Pointer {", name)
-	s.GoBody.Pn("return unsafe.Pointer(C.getPointer_%v())", myFuncName)
-	s.GoBody.Pn("}")
+	if closureIdx >= 0 {
+		s.GoBody.Pn("func GetPointer_my%v() unsafe.Pointer {", name)
+		s.GoBody.Pn("return unsafe.Pointer(C.getPointer_%v())", myFuncName)
+		s.GoBody.Pn("}")
+	} else {
+		varPool := "_trampolines" + name
+		s.GoBody.Pn("var %v = gi.NewTrampolinePool(%v)", varPool, _cfg.NumTrampolines)
+
+		trampolineType := name + "Trampoline"
+		s.GoBody.Pn("// %v 是为回调 %v 分配的 C 跳板函数，P 是传给 C 函数的函数指针。", trampolineType, name)
+		s.GoBody.Pn("type %v struct {", trampolineType)
+		s.GoBody.Pn("P unsafe.Pointer")
+		s.GoBody.Pn("Slot int")
+		s.GoBody.Pn("}")
+
+		s.GoBody.Pn("// New%v 为回调 %v 分配一个 C 跳板函数，用完后需要调用它的 Free 方法释放。",
+			trampolineType, name)
+		s.GoBody.Pn("func New%v(fn func(v interface{})) (result %v, err error) {", trampolineType, trampolineType)
+		s.GoBody.Pn("slot, err := %v.Alloc(fn)", varPool)
+		s.GoBody.Pn("if err != nil {")
+		s.GoBody.Pn("return")
+		s.GoBody.Pn("}")
+		s.GoBody.Pn("result.P = unsafe.Pointer(C.getPointer_%v(C.int(slot)))", myFuncName)
+		s.GoBody.Pn("result.Slot = slot")
+		s.GoBody.Pn("return")
+		s.GoBody.Pn("}")
+
+		s.GoBody.Pn("// Free 释放跳板函数，之后 C 代码再调用它时不会调用 Go 函数。")
+		s.GoBody.Pn("func (t %v) Free() {", trampolineType)
+		s.GoBody.Pn("%v.Free(t.Slot)", varPool)
+		s.GoBody.Pn("}")
+
+		paramNameTypes = append([]string{varSlot + " C.int"}, paramNameTypes...)
+	}
 
 	s.GoBody.Pn("//export %v", myFuncName)
-	s.GoBody.Pn("func %v(%v) {", myFuncName, strings.Join(paramNameTypes, ", "))
-
-	if foundUserData {
-		varFn := varReg.alloc("fn")
-		s.GoBody.Pn("%v := gi.GetFunc(uint(uintptr(user_data)))", varFn)
-		varArgs := varReg.alloc("args")
-		s.GoBody.Pn("%v := &%vStruct{", varArgs, name)
-		for _, line := range fieldSetLines {
-			s.GoBody.Pn(line)
-		}
+	s.GoBody.Pn("func %v(%v)%v {", myFuncName, strings.Join(paramNameTypes, ", "), cgoRetType)
 
-		s.GoBody.Pn("}") // end struct
-		s.GoBody.Pn("%v(%v)", varFn, varArgs)
+	if closureIdx >= 0 {
+		s.GoBody.Pn("%v := gi.GetFunc(uint(uintptr(%v)))", varFn, varReg.getParam(closureIdx))
+	} else {
+		s.GoBody.Pn("%v := _trampolines%v.Get(int(%v))", varFn, name, varSlot)
+	}
+	// Go 函数已经被注销或者跳板函数已经被释放时，C 代码仍然可能调用回调，这时返回零值。
+	s.GoBody.Pn("if %v == nil {", varFn)
+	if cgoRetType != "" {
+		varZero := varReg.alloc("zero")
+		s.GoBody.Pn("var %v%v", varZero, cgoRetType)
+		s.GoBody.Pn("return %v", varZero)
 	} else {
-		// 没有 user_data 参数
-		// TODO
-		s.GoBody.Pn("// TODO: not found user_data")
+		s.GoBody.Pn("return")
+	}
+	s.GoBody.Pn("}")
+	s.GoBody.Pn("%v := &%vStruct{", varArgs, name)
+	for _, line := range fieldSetLines {
+		s.GoBody.Pn(line)
 	}
 
-	s.GoBody.Pn("}") // end func
-}
+	s.GoBody.Pn("}") // end struct
+	s.GoBody.Pn("%v(%v)", varFn, varArgs)
+	for _, line := range writeBackLines {
+		s.GoBody.Pn(line)
+	}
+	if retExpr != "" {
+		s.GoBody.Pn("return %v", retExpr)
+	}
 
-type parseCbArgTypeDirInOutResult struct {
-	cgoType string
-	cType   string
-	goType  string
-	expr    string
+	s.GoBody.Pn("}") // end func
 }
 
-func parseCbArgTypeDirInOut(paramName string, argTypeInfo *gi.TypeInfo) *parseCbArgTypeDirInOutResult {
-	tag := argTypeInfo.Tag()
-	isPtr := argTypeInfo.IsPointer()
+// pCallbackTrampolines 为没有 user_data 参数的回调生成一组 C 跳板函数，
+// 跳板函数把自己的序号作为第一个参数传给导出的 Go 函数 myFuncName。
+func pCallbackTrampolines(s *SourceFile, myFuncName, cRetType string, cParamTypeNames, paramNames []string) {
+	externParams := append([]string{"int slot"}, cParamTypeNames...)
+	s.CBody.Pn("extern %s %s(%v);", cRetType, myFuncName, strings.Join(externParams, ", "))
 
-	cgoType := "C.gpointer"
-	cType := "gpointer"
-	goType := "unsafe.Pointer" + fmt.Sprintf("/*TODO_CB tag: %v, isPtr: %v*/", tag, isPtr)
-	expr := fmt.Sprintf("unsafe.Pointer(%v)", paramName)
+	retStmt := "return "
+	if cRetType == "void" {
+		retStmt = ""
+	}
+	num := _cfg.NumTrampolines
+	for i := 0; i < num; i++ {
+		callArgs := append([]string{strconv.Itoa(i)}, paramNames...)
+		s.CBody.Pn("static %v %v_%v(%v) {", cRetType, myFuncName, i, strings.Join(cParamTypeNames, ", "))
+		s.CBody.Pn("%v%v(%v);", retStmt, myFuncName, strings.Join(callArgs, ", "))
+		s.CBody.Pn("}")
+	}
 
-	return &parseCbArgTypeDirInOutResult{
-		cgoType: cgoType,
-		cType:   cType,
-		goType:  goType,
-		expr:    expr,
+	s.CBody.Pn("static void* getPointer_%v(int slot) {", myFuncName)
+	s.CBody.P("static void* ptrs[] = {")
+	for i := 0; i < num; i++ {
+		s.CBody.P("(void*)(%v_%v), ", myFuncName, i)
 	}
+	s.CBody.Pn("};")
+	s.CBody.Pn("return ptrs[slot];")
+	s.CBody.Pn("}")
 }
 
 type parseCbArgTypeDirOutResult struct {
-	cgoType string
+	cgoType string // 值的 cgo 类型，out 参数的类型是它的指针
 	cType   string
-	goType  string
-	expr    string
+	goType  string // 回调结构体中字段的类型
+	cExpr   string // 把 Go 值转换为 C 值的表达式
+	goExpr  string // 把 C 值转换为 Go 值的表达式，用于 inout 参数
 }
 
-func parseCbArgTypeDirOut(paramName string, argTypeInfo *gi.TypeInfo) *parseCbArgTypeDirOutResult {
+// parseCbArgTypeDirOut 处理回调的 out、inout 参数和返回值，varGo 是 Go 值，varC 是 C 值。
+// transfer 为 everything 时，字符串会被复制，对象会增加引用计数；
+// transfer 为 nothing 的字符串字段是 unsafe.Pointer 类型，由 Go 处理函数保证指针一直有效。
+func parseCbArgTypeDirOut(varGo, varC string, argTypeInfo *gi.TypeInfo,
+	transfer gi.Transfer) *parseCbArgTypeDirOutResult {
 	tag := argTypeInfo.Tag()
 	isPtr := argTypeInfo.IsPointer()
 
 	cgoType := "C.gpointer"
 	cType := "gpointer"
-	goType := "unsafe.Pointer" + fmt.Sprintf("/*TODO_CB tag: %v, isPtr: %v*/", tag, isPtr)
-	expr := fmt.Sprintf("unsafe.Pointer(%v)", paramName)
+	goType := "unsafe.Pointer"
+	cExpr := fmt.Sprintf("C.gpointer(%v)", varGo)
+	goExpr := fmt.Sprintf("unsafe.Pointer(%v)", varC)
+
+	switch tag {
+	case gi.TYPE_TAG_UTF8, gi.TYPE_TAG_FILENAME:
+		if isPtr && transfer != gi.TRANSFER_NOTHING {
+			cgoType = "*C.gchar"
+			cType = "gchar*"
+			goType = "string"
+			cExpr = fmt.Sprintf("(*C.gchar)(gi.CString(%v))", varGo)
+			goExpr = fmt.Sprintf("gi.GoString(unsafe.Pointer(%v))", varC)
+		}
+
+	case gi.TYPE_TAG_BOOLEAN,
+		gi.TYPE_TAG_INT8, gi.TYPE_TAG_UINT8,
+		gi.TYPE_TAG_INT16, gi.TYPE_TAG_UINT16,
+		gi.TYPE_TAG_INT32, gi.TYPE_TAG_UINT32,
+		gi.TYPE_TAG_INT64, gi.TYPE_TAG_UINT64,
+		gi.TYPE_TAG_FLOAT, gi.TYPE_TAG_DOUBLE,
+		gi.TYPE_TAG_UNICHAR:
+		// 简单类型
+		if !isPtr {
+			cType = getCTypeWithTag(tag)
+			cgoType = "C." + cType
+			goType = getTypeWithTag(tag)
+			cExpr = fmt.Sprintf("%v(%v)", cgoType, varGo)
+			goExpr = fmt.Sprintf("%v(%v)", goType, varC)
+			if tag == gi.TYPE_TAG_BOOLEAN {
+				cExpr = fmt.Sprintf("C.gboolean(gi.Bool2Int(%v))", varGo)
+				goExpr = fmt.Sprintf("gi.Int2Bool(int(%v))", varC)
+			}
+		}
+
+	case gi.TYPE_TAG_INTERFACE:
+		ii := argTypeInfo.Interface()
+		ifcType := ii.Type()
+		if ifcType == gi.INFO_TYPE_ENUM || ifcType == gi.INFO_TYPE_FLAGS {
+			if !isPtr {
+				cType = getCIdentifierPrefix(ii) + ii.Name()
+				cgoType = "C." + cType
+				name := getTypeName(ii) // 加上可能的包前缀
+				if ifcType == gi.INFO_TYPE_ENUM {
+					goType = getEnumTypeName(name)
+				} else {
+					goType = getFlagsTypeName(name)
+				}
+				cExpr = fmt.Sprintf("%v(%v)", cgoType, varGo)
+				goExpr = fmt.Sprintf("%v(%v)", goType, varC)
+			}
+		} else if ifcType == gi.INFO_TYPE_STRUCT || ifcType == gi.INFO_TYPE_UNION ||
+			ifcType == gi.INFO_TYPE_OBJECT || ifcType == gi.INFO_TYPE_INTERFACE {
+			if isPtr {
+				cType, cgoType = getCbInterfaceCType(ii)
+				goType = getTypeName(ii)
+				ptrExpr := varGo + ".P"
+				if transfer == gi.TRANSFER_EVERYTHING &&
+					(ifcType == gi.INFO_TYPE_OBJECT || ifcType == gi.INFO_TYPE_INTERFACE) {
+					// C 获得对象的一个引用
+					ptrExpr = fmt.Sprintf("gi.ObjectRef(%v)", ptrExpr)
+				}
+				cExpr = fmt.Sprintf("(%v)(%v)", cgoType, ptrExpr)
+				goExpr = fmt.Sprintf("%v{P: unsafe.Pointer(%v)}", goType, varC)
+				if ifcType == gi.INFO_TYPE_OBJECT {
+					goExpr = fmt.Sprintf("%vWrap%v(unsafe.Pointer(%v))",
+						getPkgPrefix(ii.Namespace()), ii.Name(), varC)
+				}
+			}
+		}
+		ii.Unref()
+
+	case gi.TYPE_TAG_ARRAY:
+		arrType := argTypeInfo.ArrayType()
+		if arrType == gi.ARRAY_TYPE_C {
+			elemTypeInfo := argTypeInfo.ParamType(0)
+			elemTypeTag := elemTypeInfo.Tag()
 
+			elemType := getArgumentType(elemTypeTag)
+			if elemType != "" && !elemTypeInfo.IsPointer() {
+				goType = "gi." + elemType + "Array"
+			} else if elemTypeTag == gi.TYPE_TAG_UTF8 || elemTypeTag == gi.TYPE_TAG_FILENAME {
+				goType = "gi.CStrArray"
+			}
+			if goType != "unsafe.Pointer" {
+				// 数组由 gi.NewXXXArray 分配内存，长度通过长度参数写回
+				cExpr = fmt.Sprintf("C.gpointer(%v.P)", varGo)
+				goExpr = fmt.Sprintf("%v{P: unsafe.Pointer(%v)}", goType, varC)
+			}
+			elemTypeInfo.Unref()
+		}
+	}
 	return &parseCbArgTypeDirOutResult{
 		cgoType: cgoType,
 		cType:   cType,
 		goType:  goType,
-		expr:    expr,
+		cExpr:   cExpr,
+		goExpr:  goExpr,
+	}
+}
+
+// getCbInterfaceCType 返回结构体、联合体、对象或接口 ii 的指针的 C 类型和 cgo 类型
+func getCbInterfaceCType(ii *gi.BaseInfo) (cType, cgoType string) {
+	identPrefix := getCIdentifierPrefix(ii)
+	name := ii.Name()
+	if identPrefix == "cairo" {
+		if name == "Context" {
+			name = "_t"
+		} else {
+			name = "_" + strings.ToLower(name) + "_t"
+		}
 	}
+	cType = identPrefix + name + "*"
+	cgoType = "*C." + identPrefix + name
+	return
 }
 
 type parseCbArgTypeDirInResult struct {
@@ -263,23 +562,12 @@ func parseCbArgTypeDirIn(paramName string, argTypeInfo *gi.TypeInfo) *parseCbArg
 		} else if ifcType == gi.INFO_TYPE_STRUCT || ifcType == gi.INFO_TYPE_UNION ||
 			ifcType == gi.INFO_TYPE_OBJECT || ifcType == gi.INFO_TYPE_INTERFACE {
 			if isPtr {
-				identPrefix := getCIdentifierPrefix(ii)
-				name := ii.Name()
-				if identPrefix == "cairo" {
-					if name == "Context" {
-						name = "_t"
-					} else {
-						name = "_" + strings.ToLower(name) + "_t"
-					}
-				}
-				cType = identPrefix + name + "*"
-				cgoType = "*C." + identPrefix + name
-
+				cType, cgoType = getCbInterfaceCType(ii)
 				goType = getTypeName(ii)
 				expr = fmt.Sprintf("%v{P: unsafe.Pointer(%v) }", goType, paramName)
 				if ifcType == gi.INFO_TYPE_OBJECT {
 					expr = fmt.Sprintf("%vWrap%v(unsafe.Pointer(%v))",
-						getPkgPrefix(ii.Namespace()), name, paramName)
+						getPkgPrefix(ii.Namespace()), ii.Name(), paramName)
 				}
 			}
 		}
@@ -297,7 +585,6 @@ func parseCbArgTypeDirIn(paramName string, argTypeInfo *gi.TypeInfo) *parseCbArg
 				cType = "gpointer"
 				goType = "gi." + elemType + "Array"
 				expr = fmt.Sprintf("%s{P: unsafe.Pointer(%v)}", goType, paramName)
-				// TODO length
 
 			} else if elemTypeTag == gi.TYPE_TAG_INTERFACE && !elemTypeInfo.IsPointer() {
 				goType = "unsafe.Pointer"
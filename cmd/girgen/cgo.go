@@ -0,0 +1,211 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
+	"github.com/electricface/go-gir3/cmd/girgen/xmlp"
+)
+
+// 生成函数调用代码的模式
+const (
+	// 通过 InvokerCache 获取 Invoker，由 libffi 调用 C 函数
+	modeFfi = "ffi"
+	// 通过 cgo 直接调用 C 函数
+	modeCgo = "cgo"
+)
+
+func isCgoMode() bool {
+	return _optMode == modeCgo
+}
+
+// getArgumentCField 返回传递类型为 ti 的值时使用的 GIArgument 的字段，不能用 GIArgument 传递时返回空字符串，
+// 比如按值传递的结构体。
+func getArgumentCField(ti *gi.TypeInfo) string {
+	if ti.IsPointer() {
+		return "v_pointer"
+	}
+	tag := ti.Tag()
+	switch tag {
+	case gi.TYPE_TAG_BOOLEAN:
+		return "v_boolean"
+	case gi.TYPE_TAG_INT8:
+		return "v_int8"
+	case gi.TYPE_TAG_UINT8:
+		return "v_uint8"
+	case gi.TYPE_TAG_INT16:
+		return "v_int16"
+	case gi.TYPE_TAG_UINT16:
+		return "v_uint16"
+	case gi.TYPE_TAG_INT32:
+		return "v_int32"
+	case gi.TYPE_TAG_UINT32, gi.TYPE_TAG_UNICHAR:
+		return "v_uint32"
+	case gi.TYPE_TAG_INT64:
+		return "v_int64"
+	case gi.TYPE_TAG_UINT64:
+		return "v_uint64"
+	case gi.TYPE_TAG_FLOAT:
+		return "v_float"
+	case gi.TYPE_TAG_DOUBLE:
+		return "v_double"
+	case gi.TYPE_TAG_GTYPE:
+		return "v_size"
+	case gi.TYPE_TAG_UTF8, gi.TYPE_TAG_FILENAME, gi.TYPE_TAG_ARRAY, gi.TYPE_TAG_ERROR,
+		gi.TYPE_TAG_GLIST, gi.TYPE_TAG_GSLIST, gi.TYPE_TAG_GHASH:
+		return "v_pointer"
+	case gi.TYPE_TAG_INTERFACE:
+		bi := ti.Interface()
+		defer bi.Unref()
+		switch bi.Type() {
+		case gi.INFO_TYPE_ENUM, gi.INFO_TYPE_FLAGS:
+			return "v_int"
+		case gi.INFO_TYPE_CALLBACK:
+			return "v_pointer"
+		}
+	}
+	return ""
+}
+
+// 直接调用 C 函数时从 gi.Argument 中取出参数值的方法，键是 GIArgument 的字段
+var _argumentGetters = map[string]string{
+	"v_boolean": "Int32",
+	"v_int8":    "Int8",
+	"v_uint8":   "Uint8",
+	"v_int16":   "Int16",
+	"v_uint16":  "Uint16",
+	"v_int32":   "Int32",
+	"v_uint32":  "Uint32",
+	"v_int64":   "Int64",
+	"v_uint64":  "Uint64",
+	"v_float":   "Float",
+	"v_double":  "Double",
+	"v_int":     "Int",
+	"v_size":    "Size",
+	"v_pointer": "Pointer",
+}
+
+// 把 C 函数的返回值放到 gi.Argument 中的表达式的格式，键是 GIArgument 的字段
+var _argumentNewFormats = map[string]string{
+	"v_boolean": "gi.NewBoolArgument(%v != 0)",
+	"v_int8":    "gi.NewInt8Argument(int8(%v))",
+	"v_uint8":   "gi.NewUint8Argument(uint8(%v))",
+	"v_int16":   "gi.NewInt16Argument(int16(%v))",
+	"v_uint16":  "gi.NewUint16Argument(uint16(%v))",
+	"v_int32":   "gi.NewInt32Argument(int32(%v))",
+	"v_uint32":  "gi.NewUint32Argument(uint32(%v))",
+	"v_int64":   "gi.NewInt64Argument(int64(%v))",
+	"v_uint64":  "gi.NewUint64Argument(uint64(%v))",
+	"v_float":   "gi.NewFloatArgument(float32(%v))",
+	"v_double":  "gi.NewDoubleArgument(float64(%v))",
+	"v_int":     "gi.NewIntArgument(int(%v))",
+	"v_size":    "gi.NewSizeArgument(uint64(%v))",
+	"v_pointer": "gi.NewPointerArgument(unsafe.Pointer(%v))",
+}
+
+// getCgoType 返回 C 类型 ctype 在 cgo 中的写法，比如 const gchar* 返回 *C.gchar，
+// 不能在 cgo 中直接使用的类型返回 false，比如 va_list 和 struct _Foo*。
+func getCgoType(ctype string) (string, bool) {
+	ct, err := xmlp.ParseCType(ctype)
+	if err != nil || ct.Name == "va_list" {
+		return "", false
+	}
+	var fields []string
+	for _, f := range strings.Fields(strings.Replace(ctype, "*", " ", -1)) {
+		if f != "const" {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) != 1 && !(ct.IsUnsigned && ct.Name == "int" && len(fields) == 2) {
+		return "", false
+	}
+	return ct.CgoNotation(), true
+}
+
+// getCgoParamCTypes 返回 C 函数 xFunc 的参数的 C 类型，顺序和 C 函数的参数一致，缺少 c:type 的参数为空字符串。
+func getCgoParamCTypes(xFunc *xmlp.FunctionInfo) (result []string) {
+	getCType := func(p *xmlp.Parameter) string {
+		if p.Array != nil {
+			return p.Array.CType
+		}
+		if p.Type != nil {
+			return p.Type.CType
+		}
+		return ""
+	}
+	if xFunc.Parameters != nil {
+		if p := xFunc.Parameters.InstanceParameter; p != nil {
+			result = append(result, getCType(p))
+		}
+		for _, p := range xFunc.Parameters.Parameters {
+			result = append(result, getCType(p))
+		}
+	}
+	if xFunc.Throws {
+		result = append(result, "GError**")
+	}
+	return
+}
+
+// getCgoArgExprs 返回直接调用 C 函数 xFunc 时传入的参数列表，argNames 是保存参数值的 gi.Argument 变量，
+// argFields 是它们使用的 GIArgument 的字段。有参数的 C 类型未知或不能在 cgo 中使用时返回 false。
+func getCgoArgExprs(xFunc *xmlp.FunctionInfo, argNames, argFields []string) ([]string, bool) {
+	if xFunc == nil {
+		return nil, false
+	}
+	ctypes := getCgoParamCTypes(xFunc)
+	if len(ctypes) != len(argNames) {
+		return nil, false
+	}
+	exprs := make([]string, len(argNames))
+	for i, ctype := range ctypes {
+		cgoType, ok := getCgoType(ctype)
+		if !ok {
+			return nil, false
+		}
+		if strings.HasPrefix(cgoType, "*") {
+			cgoType = "(" + cgoType + ")"
+		}
+		exprs[i] = fmt.Sprintf("%v(%v.%v())", cgoType, argNames[i], _argumentGetters[argFields[i]])
+	}
+	return exprs, true
+}
+
+// getCgoCallExpr 返回直接调用 C 函数 symbol 的表达式，返回值放在 GIArgument 的 retField 字段中，
+// retField 为空表示没有返回值。
+// 参数和返回值都和 libffi 调用时一样放在 gi.Argument 中，所以 Go 中转换参数和返回值的代码不用改变。
+func getCgoCallExpr(symbol string, argExprs []string, retField string) string {
+	call := fmt.Sprintf("C.%v(%v)", symbol, strings.Join(argExprs, ", "))
+	if retField == "" {
+		return call
+	}
+	return fmt.Sprintf(_argumentNewFormats[retField], call)
+}
+
+// pCgoInit 在 cgo 模式下为源文件加上编译选项
+func pCgoInit(s *SourceFile) {
+	// 直接调用已废弃的函数时不要输出警告
+	s.CHeader.Pn("#cgo CFLAGS: -Wno-deprecated-declarations")
+}
@@ -31,8 +31,20 @@ import (
 type config struct {
 	Black     []string `json:"black"`
 	CIncludes []string `json:"cIncludes"`
+	// 每个没有 user_data 参数的回调类型生成的 C 跳板函数的个数
+	NumTrampolines int `json:"numTrampolines"`
+	// 所有权模式，返回的对象持有一个引用，在被垃圾回收时由 finalizer 释放
+	Ownership bool `json:"ownership"`
+	// 目标的最低版本，比如 3.18，不生成比它新的函数，可以被 -min-version 参数覆盖
+	MinVersion string `json:"minVersion"`
+	// 不生成已废弃的函数、属性和信号，类型仍然生成，因为其他 API 可能引用它们
+	SkipDeprecated bool `json:"skipDeprecated"`
+	// -mode=cgo 时仍然通过 libffi 调用的函数，比如 C 头文件中没有声明的函数，格式和 black 相同
+	FfiFuncs []string `json:"ffiFuncs"`
 }
 
+const defaultNumTrampolines = 8
+
 func loadConfig(filename string, cfg *config) error {
 	data, err := ioutil.ReadFile(filename)
 	if err != nil {
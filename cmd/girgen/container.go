@@ -0,0 +1,252 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
+)
+
+type containerElemResult struct {
+	type0       string // 元素在 Go 中的类型
+	fromPtrExpr string // 把 unsafe.Pointer 转换为元素的表达式，%v 是指针
+	toPtrExpr   string // 把元素转换为 unsafe.Pointer 的表达式，%v 是元素
+	isStr       bool   // 元素是否为字符串，转换出来的指针需要释放
+}
+
+// parseContainerElemType 处理 GList, GSList 和 GHashTable 的元素类型，不支持的类型返回 nil。
+// transfer 为 TRANSFER_EVERYTHING 时，调用者拥有字符串元素。
+func parseContainerElemType(ti *gi.TypeInfo, transfer gi.Transfer) *containerElemResult {
+	switch ti.Tag() {
+	case gi.TYPE_TAG_UTF8, gi.TYPE_TAG_FILENAME:
+		fromPtrExpr := "gi.StrPtr{P: %v}.Copy()"
+		if transfer == gi.TRANSFER_EVERYTHING {
+			fromPtrExpr = "gi.StrPtr{P: %v}.Take()"
+		}
+		return &containerElemResult{
+			type0:       "string",
+			fromPtrExpr: fromPtrExpr,
+			toPtrExpr:   "gi.CString(%v)",
+			isStr:       true,
+		}
+
+	case gi.TYPE_TAG_INTERFACE:
+		if !ti.IsPointer() {
+			return nil
+		}
+		bi := ti.Interface()
+		defer bi.Unref()
+		switch bi.Type() {
+		case gi.INFO_TYPE_OBJECT, gi.INFO_TYPE_INTERFACE, gi.INFO_TYPE_STRUCT, gi.INFO_TYPE_UNION:
+			return &containerElemResult{
+				type0:       getTypeName(bi),
+				fromPtrExpr: getWrapPtrExpr(bi, "%v"),
+				toPtrExpr:   "%v.P",
+			}
+		}
+	}
+	return nil
+}
+
+// getListTypeName 返回 GList 或 GSList 对应的 g 包中的类型
+func getListTypeName(tag gi.TypeTag) string {
+	if tag == gi.TYPE_TAG_GSLIST {
+		return getGLibType("SList")
+	}
+	return getGLibType("List")
+}
+
+// parseContainerRetType 把 GList, GSList 转换为切片，把 GHashTable 转换为 map，
+// 并根据 transfer 释放容器和元素。对于 GHashTable，TRANSFER_CONTAINER 时先移除键值对再释放，
+// 避免销毁函数释放调用者不拥有的键和值。不支持的元素类型返回 nil。
+func parseContainerRetType(varRet string, ti *gi.TypeInfo, varReg *VarReg,
+	transfer gi.Transfer) *parseRetTypeResult {
+
+	tag := ti.Tag()
+	if tag == gi.TYPE_TAG_GHASH {
+		keyTypeInfo := ti.ParamType(0)
+		valueTypeInfo := ti.ParamType(1)
+		// 哈希表的键和值由哈希表的销毁函数释放，所以总是复制
+		key := parseContainerElemType(keyTypeInfo, gi.TRANSFER_NOTHING)
+		value := parseContainerElemType(valueTypeInfo, gi.TRANSFER_NOTHING)
+		keyTypeInfo.Unref()
+		valueTypeInfo.Unref()
+		if key == nil || value == nil {
+			return nil
+		}
+
+		type0 := fmt.Sprintf("map[%v]%v", key.type0, value.type0)
+		varHashTable := varReg.alloc("hashTable")
+		varKey := varReg.alloc("key")
+		varValue := varReg.alloc("value")
+		return &parseRetTypeResult{
+			type0: type0,
+			assign: func(varResult string) []string {
+				lines := []string{
+					fmt.Sprintf("%v := %v{P: %v.Pointer()}", varHashTable, getGLibType("HashTable"), varRet),
+					fmt.Sprintf("%v = make(%v)", varResult, type0),
+					fmt.Sprintf("%v.ForEach(func(%v, %v unsafe.Pointer) {", varHashTable, varKey, varValue),
+					fmt.Sprintf("%v[%v] = %v", varResult, fmt.Sprintf(key.fromPtrExpr, varKey),
+						fmt.Sprintf(value.fromPtrExpr, varValue)),
+					"})",
+				}
+				switch transfer {
+				case gi.TRANSFER_CONTAINER:
+					// 调用者只拥有哈希表，键和值不能被销毁函数释放
+					lines = append(lines, varHashTable+".StealAll()", varHashTable+".Unref()")
+				case gi.TRANSFER_EVERYTHING:
+					lines = append(lines, varHashTable+".Unref()")
+				}
+				return lines
+			},
+		}
+	}
+
+	elemTypeInfo := ti.ParamType(0)
+	elem := parseContainerElemType(elemTypeInfo, transfer)
+	elemTypeInfo.Unref()
+	if elem == nil {
+		return nil
+	}
+
+	type0 := "[]" + elem.type0
+	varList := varReg.alloc("list")
+	varItem := varReg.alloc("item")
+	return &parseRetTypeResult{
+		type0: type0,
+		assign: func(varResult string) []string {
+			lines := []string{
+				fmt.Sprintf("%v := %v{P: %v.Pointer()}", varList, getListTypeName(tag), varRet),
+				fmt.Sprintf("%v.ForEach(func(%v unsafe.Pointer) {", varList, varItem),
+				fmt.Sprintf("%v = append(%v, %v)", varResult, varResult,
+					fmt.Sprintf(elem.fromPtrExpr, varItem)),
+				"})",
+			}
+			if transfer != gi.TRANSFER_NOTHING {
+				lines = append(lines, varList+".Free()")
+			}
+			return lines
+		},
+	}
+}
+
+// parseContainerArgTypeDirIn 把切片转换为 GList 或 GSList，把 map 转换为 GHashTable，
+// 调用之后根据 transfer 释放调用者仍然拥有的部分：TRANSFER_NOTHING 时释放容器和元素，
+// TRANSFER_CONTAINER 时只释放元素，TRANSFER_EVERYTHING 时都不释放。不支持的元素类型返回 nil。
+func parseContainerArgTypeDirIn(varArg string, ti *gi.TypeInfo, varReg *VarReg,
+	transfer gi.Transfer) *parseArgTypeDirInResult {
+
+	tag := ti.Tag()
+	if tag == gi.TYPE_TAG_GHASH {
+		keyTypeInfo := ti.ParamType(0)
+		valueTypeInfo := ti.ParamType(1)
+		key := parseContainerElemType(keyTypeInfo, gi.TRANSFER_NOTHING)
+		value := parseContainerElemType(valueTypeInfo, gi.TRANSFER_NOTHING)
+		keyTypeInfo.Unref()
+		valueTypeInfo.Unref()
+		if key == nil || value == nil {
+			return nil
+		}
+		if transfer == gi.TRANSFER_EVERYTHING && !(key.isStr && value.isStr) {
+			// 被调用的函数会释放对象等元素的引用，但是调用者并不拥有它们。
+			return nil
+		}
+
+		varHashTable := varReg.alloc("hashTable")
+		varKey := varReg.alloc("key")
+		varValue := varReg.alloc("value")
+		// 字符串的键和值由哈希表的销毁函数释放，哈希表的所有权转移给被调用的函数时，
+		// 由它释放哈希表。
+		var afterCallLines []string
+		if transfer == gi.TRANSFER_NOTHING {
+			afterCallLines = []string{varHashTable + ".Unref()"}
+		}
+		return &parseArgTypeDirInResult{
+			type0:      fmt.Sprintf("map[%v]%v", key.type0, value.type0),
+			newArgExpr: fmt.Sprintf("gi.NewPointerArgument(%v.P)", varHashTable),
+			beforeArgLines: []string{
+				fmt.Sprintf("%v := %vNewHashTable(%v, %v, %v)", varHashTable, getPkgPrefix("GLib"),
+					key.isStr, key.isStr, value.isStr),
+				fmt.Sprintf("for %v, %v := range %v {", varKey, varValue, varArg),
+				fmt.Sprintf("%v.Insert(%v, %v)", varHashTable, fmt.Sprintf(key.toPtrExpr, varKey),
+					fmt.Sprintf(value.toPtrExpr, varValue)),
+				"}",
+			},
+			afterCallLines: afterCallLines,
+		}
+	}
+
+	elemTypeInfo := ti.ParamType(0)
+	elem := parseContainerElemType(elemTypeInfo, gi.TRANSFER_NOTHING)
+	elemTypeInfo.Unref()
+	if elem == nil {
+		return nil
+	}
+	if transfer == gi.TRANSFER_EVERYTHING && !elem.isStr {
+		// 被调用的函数会释放对象等元素的引用，但是调用者并不拥有它们。
+		return nil
+	}
+
+	varList := varReg.alloc("list")
+	varI := varReg.alloc("i")
+	elemExpr := fmt.Sprintf(elem.toPtrExpr, fmt.Sprintf("%v[%v]", varArg, varI))
+	beforeArgLines := []string{fmt.Sprintf("var %v %v", varList, getListTypeName(tag))}
+	var loopLines []string
+	var afterCallLines []string
+	switch transfer {
+	case gi.TRANSFER_NOTHING:
+		if elem.isStr {
+			afterCallLines = []string{varList + ".FreeFull(gi.Free)"}
+		} else {
+			afterCallLines = []string{varList + ".Free()"}
+		}
+	case gi.TRANSFER_CONTAINER:
+		if elem.isStr {
+			// 列表由被调用的函数释放，调用之后不能再遍历它，所以另外记录要释放的字符串。
+			varItems := varReg.alloc("items")
+			varItem := varReg.alloc("item")
+			beforeArgLines = append(beforeArgLines,
+				fmt.Sprintf("%v := make([]unsafe.Pointer, len(%v))", varItems, varArg))
+			loopLines = []string{fmt.Sprintf("%v[%v] = %v", varItems, varI, elemExpr)}
+			elemExpr = fmt.Sprintf("%v[%v]", varItems, varI)
+			afterCallLines = []string{
+				fmt.Sprintf("for _, %v := range %v {", varItem, varItems),
+				fmt.Sprintf("gi.Free(%v)", varItem),
+				"}",
+			}
+		}
+	}
+	// 从后往前 Prepend，避免 Append 每次都要遍历列表。
+	beforeArgLines = append(beforeArgLines,
+		fmt.Sprintf("for %v := len(%v) - 1; %v >= 0; %v-- {", varI, varArg, varI, varI))
+	beforeArgLines = append(beforeArgLines, loopLines...)
+	beforeArgLines = append(beforeArgLines,
+		fmt.Sprintf("%v = %v.Prepend(%v)", varList, varList, elemExpr),
+		"}")
+	return &parseArgTypeDirInResult{
+		type0:          "[]" + elem.type0,
+		newArgExpr:     fmt.Sprintf("gi.NewPointerArgument(%v.P)", varList),
+		beforeArgLines: beforeArgLines,
+		afterCallLines: afterCallLines,
+	}
+}
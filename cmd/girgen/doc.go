@@ -0,0 +1,293 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
+	"github.com/electricface/go-gir3/cmd/girgen/xmlp"
+)
+
+var _xRepo *xmlp.Repository
+
+// 键是 C 的符号，比如 GtkWidget、GTK_STATE_FLAG_NORMAL、gtk_widget_show，值是它对应的 Go 名字。
+var _docSymbols = make(map[string]string)
+
+// 匹配 gtk-doc 中的 %CONSTANT、#Type、@param 和 function()，
+// 前面是字母、数字、/ 或 : 的不算，避免匹配 URL 之类的。
+var _docRefRegexp = regexp.MustCompile(`(^|[^\w/:])([%#@])([A-Za-z_]\w*)|\b([A-Za-z_]\w*)\(\)`)
+
+// convertDocRefs 把一行 gtk-doc 文档中对 C 符号的引用转换为 Go 的名字，
+// lookup 返回 C 符号对应的 Go 名字，找不到时返回空字符串。
+func convertDocRefs(line string, lookup func(cName string) string) string {
+	var sb strings.Builder
+	last := 0
+	for _, m := range _docRefRegexp.FindAllStringSubmatchIndex(line, -1) {
+		sb.WriteString(line[last:m[0]])
+		last = m[1]
+
+		if m[8] >= 0 {
+			// function()
+			name := line[m[8]:m[9]]
+			if goName := lookup(name); goName != "" {
+				sb.WriteString(goName + "()")
+			} else {
+				sb.WriteString(line[m[0]:m[1]])
+			}
+			continue
+		}
+
+		sb.WriteString(line[m[2]:m[3]])
+		sigil := line[m[4]:m[5]]
+		name := line[m[6]:m[7]]
+		if sigil == "%" {
+			switch name {
+			case "TRUE":
+				name = "true"
+			case "FALSE":
+				name = "false"
+			case "NULL":
+				name = "nil"
+			}
+		}
+		if sigil != "@" {
+			if goName := lookup(name); goName != "" {
+				name = goName
+			}
+		}
+		sb.WriteString(name)
+	}
+	sb.WriteString(line[last:])
+	return sb.String()
+}
+
+// convertDoc 把 GIR 中 gtk-doc 格式的文档转换为 Go 的注释行，不包括开头的 //。
+// |[ ... ]| 中的代码转换为缩进的行，保持原样。
+func convertDoc(doc string, lookup func(cName string) string) []string {
+	var lines []string
+	inCode := false
+	for _, line := range strings.Split(strings.TrimSpace(doc), "\n") {
+		line = strings.TrimRight(line, " \t")
+		trimmed := strings.TrimSpace(line)
+		if !inCode && strings.HasPrefix(trimmed, "|[") {
+			inCode = true
+			continue
+		}
+		if inCode {
+			if strings.HasSuffix(trimmed, "]|") {
+				inCode = false
+				line = strings.TrimSuffix(line, "]|")
+				if strings.TrimSpace(line) == "" {
+					continue
+				}
+			}
+			lines = append(lines, "\t"+line)
+			continue
+		}
+		// 普通的文字不能有缩进，否则在 Go 文档中会被当作代码
+		lines = append(lines, convertDocRefs(trimmed, lookup))
+	}
+	return lines
+}
+
+func lookupDocSymbol(cName string) string {
+	return _docSymbols[cName]
+}
+
+// getDocLines 返回 doc 转换后的注释行，doc 为空时返回 nil。
+func getDocLines(doc string) []string {
+	if strings.TrimSpace(doc) == "" {
+		return nil
+	}
+	return convertDoc(doc, lookupDocSymbol)
+}
+
+// getDeprecatedDocLines 返回 Go 标准的 Deprecated: 段落，包括废弃的版本和 doc-deprecated 中的替代说明。
+func getDeprecatedDocLines(version, docDeprecated string) []string {
+	first := "Deprecated:"
+	if version != "" {
+		first += " Since " + version + "."
+	}
+	lines := getDocLines(docDeprecated)
+	if len(lines) == 0 {
+		if version == "" {
+			first += " Do not use."
+		}
+		return []string{first}
+	}
+	lines[0] = first + " " + lines[0]
+	return lines
+}
+
+// pDeprecated 输出 Deprecated: 段落，需要放在文档注释的最后。
+func pDeprecated(s *SourceFile, version, docDeprecated string) {
+	s.GoBody.Pn("//")
+	for _, line := range getDeprecatedDocLines(version, docDeprecated) {
+		s.GoBody.Pn("// %v", line)
+	}
+}
+
+type deprecation interface {
+	Deprecation() (version, docDeprecated string)
+}
+
+// pTypeDeprecated 输出当前命名空间中的类型 name 的 Deprecated: 段落
+func pTypeDeprecated(s *SourceFile, name string) {
+	var version, docDeprecated string
+	if _xRepo != nil {
+		typ, _ := _xRepo.GetType(name)
+		if d, ok := typ.(deprecation); ok {
+			version, docDeprecated = d.Deprecation()
+		}
+	}
+	pDeprecated(s, version, docDeprecated)
+}
+
+// pFuncDeprecated 输出 C 函数 symbol 的 Deprecated: 段落
+func pFuncDeprecated(s *SourceFile, symbol string) {
+	var version, docDeprecated string
+	if xFunc := getFuncDoc(symbol); xFunc != nil {
+		version, docDeprecated = xFunc.Deprecation()
+	}
+	pDeprecated(s, version, docDeprecated)
+}
+
+// initDocSymbols 收集文档中可能引用的 C 符号对应的 Go 名字，
+// 包括所有已经加载的 GIR 文件中的类型和枚举值，以及当前命名空间中的函数。
+func initDocSymbols(repo *gi.Repository) {
+	for _, xRepo := range xmlp.LoadedRepos() {
+		ns := xRepo.Namespace
+		prefix := ""
+		if !isSameNamespace(ns.Name) {
+			prefix = getPkgName(ns.Name) + "."
+		}
+
+		addType := func(cType, name string) {
+			if cType != "" {
+				_docSymbols[cType] = prefix + name
+			}
+		}
+		for _, class := range ns.Objects {
+			addType(class.CTypeAttr, class.NameAttr)
+		}
+		for _, ifc := range ns.Interfaces {
+			addType(ifc.CTypeAttr, ifc.NameAttr)
+		}
+		for _, struct0 := range ns.Structs {
+			addType(struct0.CTypeAttr, struct0.NameAttr)
+		}
+		for _, union := range ns.Unions {
+			addType(union.CTypeAttr, union.NameAttr)
+		}
+		for _, callback := range ns.Callbacks {
+			addType(callback.CTypeAttr, callback.NameAttr)
+		}
+		for _, enum := range ns.Enums {
+			type0 := getEnumTypeName(enum.NameAttr)
+			addType(enum.CTypeAttr, type0)
+			for _, member := range enum.Members {
+				addType(member.CIdentifier, getEnumMemberName(enum.NameAttr, type0, member.Name))
+			}
+		}
+		for _, enum := range ns.Bitfields {
+			type0 := getFlagsTypeName(enum.NameAttr)
+			addType(enum.CTypeAttr, type0)
+			for _, member := range enum.Members {
+				addType(member.CIdentifier, getEnumMemberName(enum.NameAttr, type0, member.Name))
+			}
+		}
+	}
+
+	forEachFunctionInfo(repo, _optNamespace, func(fi *gi.FunctionInfo) {
+		name, isMethod := getFunctionGoName(fi)
+		if isMethod {
+			container := fi.Container()
+			name = container.Name() + "." + name
+			container.Unref()
+		}
+		_docSymbols[fi.Symbol()] = name
+	})
+}
+
+// pPropertyDeprecated 输出类型 typeName 的属性 propName 的 Deprecated: 段落
+func pPropertyDeprecated(s *SourceFile, typeName, propName string) {
+	var version, docDeprecated string
+	if _xRepo != nil {
+		if prop := _xRepo.GetProperty(typeName, propName); prop != nil {
+			version, docDeprecated = prop.Deprecation()
+		}
+	}
+	pDeprecated(s, version, docDeprecated)
+}
+
+// pSignalDeprecated 输出类型 typeName 的信号 sigName 的 Deprecated: 段落
+func pSignalDeprecated(s *SourceFile, typeName, sigName string) {
+	var version, docDeprecated string
+	if _xRepo != nil {
+		if signal := _xRepo.GetSignal(typeName, sigName); signal != nil {
+			version, docDeprecated = signal.Deprecation()
+		}
+	}
+	pDeprecated(s, version, docDeprecated)
+}
+
+// pTypeDoc 输出当前命名空间中的类型 name 在 GIR 中的文档
+func pTypeDoc(s *SourceFile, name string) {
+	if _xRepo == nil {
+		return
+	}
+	typ, _ := _xRepo.GetType(name)
+	documented, ok := typ.(interface {
+		Documentation() string
+	})
+	if !ok {
+		return
+	}
+	lines := getDocLines(documented.Documentation())
+	if len(lines) == 0 {
+		return
+	}
+	s.GoBody.Pn("//")
+	for _, line := range lines {
+		s.GoBody.Pn("// %v", line)
+	}
+}
+
+// getFuncDoc 返回 C 函数 symbol 在 GIR 中的信息，找不到时返回 nil。
+func getFuncDoc(symbol string) *xmlp.FunctionInfo {
+	if _xRepo == nil {
+		return nil
+	}
+	return _xRepo.GetFunction(symbol)
+}
+
+// getEnumDoc 返回当前命名空间中的枚举或标志类型 name 在 GIR 中的信息，找不到时返回 nil。
+func getEnumDoc(name string) *xmlp.EnumInfo {
+	if _xRepo == nil {
+		return nil
+	}
+	typ, _ := _xRepo.GetType(name)
+	xEnum, _ := typ.(*xmlp.EnumInfo)
+	return xEnum
+}
@@ -0,0 +1,208 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
+)
+
+type parseFieldTypeResult struct {
+	type0   string // 字段在 Go 中的类型
+	getExpr string // 读取字段值的表达式，%[1]v 是字段的地址
+	setExpr string // 写入字段值的语句，%[1]v 是字段的地址，%[2]v 是值，为空则不能写入。
+}
+
+// getFieldPtrExpr 返回结构体中偏移量为 offset 的字段的地址表达式，varV 是结构体的 Go 变量。
+func getFieldPtrExpr(varV string, offset int) string {
+	if offset == 0 {
+		return varV + ".P"
+	}
+	return fmt.Sprintf("unsafe.Pointer(uintptr(%v.P) + %v)", varV, offset)
+}
+
+// getWrapPtrExpr 返回把指针 ptrExpr 包装为 bi 对应的 Go 类型的表达式。
+// 对象类型的 P 字段可能来自嵌入的父类型，不能用在复合字面量中，所以用 WrapXXX 函数。
+func getWrapPtrExpr(bi *gi.BaseInfo, ptrExpr string) string {
+	if bi.Type() == gi.INFO_TYPE_OBJECT {
+		return fmt.Sprintf("%vWrap%v(%v)", getPkgPrefix(bi.Namespace()), bi.Name(), ptrExpr)
+	}
+	return fmt.Sprintf("%v{P: %v}", getTypeName(bi), ptrExpr)
+}
+
+// parseFieldType 根据字段的类型生成读写字段的表达式，不支持的类型返回 nil。
+// 字符串和指针类型的字段不知道所有权，所以只能读取。
+func parseFieldType(ti *gi.TypeInfo) *parseFieldTypeResult {
+	isPtr := ti.IsPointer()
+	tag := ti.Tag()
+
+	switch tag {
+	case gi.TYPE_TAG_BOOLEAN:
+		if isPtr {
+			return nil
+		}
+		// gboolean 是 int32
+		return &parseFieldTypeResult{
+			type0:   "bool",
+			getExpr: "*(*int32)(%[1]v) != 0",
+			setExpr: "*(*int32)(%[1]v) = int32(gi.Bool2Int(%[2]v))",
+		}
+
+	case gi.TYPE_TAG_INT8, gi.TYPE_TAG_UINT8,
+		gi.TYPE_TAG_INT16, gi.TYPE_TAG_UINT16,
+		gi.TYPE_TAG_INT32, gi.TYPE_TAG_UINT32,
+		gi.TYPE_TAG_INT64, gi.TYPE_TAG_UINT64,
+		gi.TYPE_TAG_FLOAT, gi.TYPE_TAG_DOUBLE,
+		gi.TYPE_TAG_UNICHAR:
+		if isPtr {
+			return nil
+		}
+		type0 := getTypeWithTag(tag)
+		return &parseFieldTypeResult{
+			type0:   type0,
+			getExpr: "*(*" + type0 + ")(%[1]v)",
+			setExpr: "*(*" + type0 + ")(%[1]v) = %[2]v",
+		}
+
+	case gi.TYPE_TAG_GTYPE:
+		return &parseFieldTypeResult{
+			type0:   "gi.GType",
+			getExpr: "*(*gi.GType)(%[1]v)",
+			setExpr: "*(*gi.GType)(%[1]v) = %[2]v",
+		}
+
+	case gi.TYPE_TAG_UTF8, gi.TYPE_TAG_FILENAME:
+		return &parseFieldTypeResult{
+			type0:   "string",
+			getExpr: "gi.StrPtr{P: *(*unsafe.Pointer)(%[1]v)}.Copy()",
+		}
+
+	case gi.TYPE_TAG_VOID:
+		if !isPtr {
+			return nil
+		}
+		return &parseFieldTypeResult{
+			type0:   "unsafe.Pointer",
+			getExpr: "*(*unsafe.Pointer)(%[1]v)",
+			setExpr: "*(*unsafe.Pointer)(%[1]v) = %[2]v",
+		}
+
+	case gi.TYPE_TAG_INTERFACE:
+		bi := ti.Interface()
+		defer bi.Unref()
+		switch bi.Type() {
+		case gi.INFO_TYPE_ENUM, gi.INFO_TYPE_FLAGS:
+			if isPtr {
+				return nil
+			}
+			var type0 string
+			if bi.Type() == gi.INFO_TYPE_FLAGS {
+				type0 = getFlagsTypeName(getTypeName(bi))
+			} else {
+				type0 = getEnumTypeName(getTypeName(bi))
+			}
+			// 按照枚举的存储类型读写
+			storageType := getTypeWithTag(gi.ToEnumInfo(bi).StorageType())
+			return &parseFieldTypeResult{
+				type0:   type0,
+				getExpr: type0 + "(*(*" + storageType + ")(%[1]v))",
+				setExpr: "*(*" + storageType + ")(%[1]v) = " + storageType + "(%[2]v)",
+			}
+
+		case gi.INFO_TYPE_STRUCT, gi.INFO_TYPE_UNION:
+			type0 := getTypeName(bi)
+			if isPtr {
+				return &parseFieldTypeResult{
+					type0:   type0,
+					getExpr: getWrapPtrExpr(bi, "*(*unsafe.Pointer)(%[1]v)"),
+				}
+			}
+			// 内嵌的结构体，返回的值直接指向字段所在的内存。
+			return &parseFieldTypeResult{
+				type0:   type0,
+				getExpr: type0 + "{P: %[1]v}",
+			}
+
+		case gi.INFO_TYPE_OBJECT, gi.INFO_TYPE_INTERFACE:
+			if !isPtr {
+				return nil
+			}
+			return &parseFieldTypeResult{
+				type0:   getTypeName(bi),
+				getExpr: getWrapPtrExpr(bi, "*(*unsafe.Pointer)(%[1]v)"),
+			}
+		}
+	}
+	return nil
+}
+
+// pField 为结构体或联合体的字段 fi 生成 FieldXXX 和 SetFieldXXX 方法，
+// 字段的地址由静态的偏移量计算，不需要在运行时查找字段。
+func pField(s *SourceFile, fi *gi.FieldInfo, containerName string) {
+	fieldName := fi.Name()
+	flags := fi.Flags()
+	if flags&(gi.FIELD_IS_READABLE|gi.FIELD_IS_WRITABLE) == 0 {
+		return
+	}
+
+	ti := fi.Type()
+	defer ti.Unref()
+
+	// 位域的 Size 不为 0
+	var parseResult *parseFieldTypeResult
+	if fi.Size() == 0 {
+		parseResult = parseFieldType(ti)
+	}
+	if parseResult == nil {
+		s.GoBody.Pn("// TODO: field %s.%s, tag: %v, isPtr: %v\n",
+			containerName, fieldName, ti.Tag(), ti.IsPointer())
+		reason := fmt.Sprintf("unsupported field type, isPtr: %v", ti.IsPointer())
+		if fi.Size() != 0 {
+			reason = "bit field"
+		}
+		cSymbol := gi.DefaultRepository().CPrefix(_optNamespace) + containerName + "." + fieldName
+		_report.add(newReportEntry("field", cSymbol, -1, "", "", ti), reason)
+		return
+	}
+
+	name := snake2Camel(fieldName)
+	var varReg VarReg
+	varV := varReg.alloc("v")
+	ptrExpr := getFieldPtrExpr(varV, fi.Offset())
+
+	if flags&gi.FIELD_IS_READABLE != 0 {
+		s.GoBody.Pn("// Field%v 获取字段 %v 的值", name, fieldName)
+		s.GoBody.Pn("func (%v %v) Field%v() %v {", varV, containerName, name, parseResult.type0)
+		s.GoBody.Pn("return "+parseResult.getExpr, ptrExpr)
+		s.GoBody.Pn("}") // end func
+	}
+
+	if flags&gi.FIELD_IS_WRITABLE != 0 && parseResult.setExpr != "" {
+		varValue := varReg.alloc("value")
+		s.GoBody.Pn("// SetField%v 设置字段 %v 的值", name, fieldName)
+		s.GoBody.Pn("func (%v %v) SetField%v(%v %v) {", varV, containerName, name,
+			varValue, parseResult.type0)
+		s.GoBody.Pn(parseResult.setExpr, ptrExpr, varValue)
+		s.GoBody.Pn("}") // end func
+	}
+}
@@ -27,7 +27,7 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/electricface/go-gir3/gi"
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
 )
 
 // 给 InvokeCache.Get() 用的 index 的
@@ -58,6 +58,47 @@ func getFunctionNameFinal(fi *gi.FunctionInfo) string {
 	return getFunctionName(fi)
 }
 
+// getFunctionGoName 返回 fi 在生成的代码中的名称，以及是否把它生成为容器类型的方法。
+// 不能作为方法的容器内函数被生成为名为 容器名+函数名+"1" 的函数。
+func getFunctionGoName(fi *gi.FunctionInfo) (name string, isMethod bool) {
+	name = getFunctionNameFinal(fi)
+	container := fi.Container()
+	if container == nil {
+		return
+	}
+	defer container.Unref()
+
+	fnFlags := fi.Flags()
+	if fnFlags&gi.FUNCTION_IS_CONSTRUCTOR != 0 {
+		// 表示 C 函数是构造器
+		return
+	}
+	if fnFlags&gi.FUNCTION_IS_METHOD != 0 {
+		// 表示 C 函数是方法
+		return name, true
+	}
+
+	// 可能 C 函数还是可以作为方法的，只不过没有处理好参数，如果第一个参数是指针类型，就大概率是方法。
+	if fi.NumArg() > 0 {
+		arg0 := fi.Arg(0)
+		arg0Type := arg0.Type()
+		if arg0Type.IsPointer() && arg0Type.Tag() == gi.TYPE_TAG_INTERFACE {
+			ii := arg0Type.Interface()
+			isMethod = ii.Name() == container.Name()
+			ii.Unref()
+		}
+		arg0Type.Unref()
+		arg0.Unref()
+	}
+	// 比如 io_channel_error_quark 方法，被重命名为IOChannel.error_quark，这算是 IOChannel 的 static 方法，
+	// 不能作为方法, 作为函数
+	// TODO: 适当消除 1 后缀
+	if !isMethod {
+		name = container.Name() + name + "1"
+	}
+	return
+}
+
 /*
 
 { // begin func
@@ -81,9 +122,6 @@ return
 */
 
 func pFunction(s *SourceFile, fi *gi.FunctionInfo, idxLv1, idxLv2 int) {
-	if fi.IsDeprecated() {
-		markDeprecated(s)
-	}
 	b := &SourceBlock{}
 	symbol := fi.Symbol()
 	fiName := fi.Name()
@@ -97,15 +135,27 @@ func pFunction(s *SourceFile, fi *gi.FunctionInfo, idxLv1, idxLv2 int) {
 		b.Pn("\n// black function %s\n", identifyName)
 		return
 	}
+	if fi.IsDeprecated() && _cfg.SkipDeprecated {
+		s.GoBody.Pn("\n// deprecated function %s\n", identifyName)
+		return
+	}
+	xFunc := getFuncDoc(symbol)
+	if xFunc != nil && pSkipNewer(s, "function", identifyName, xFunc.Since) {
+		return
+	}
 
 	funcIdx := _funcNextIdx
 	_funcNextIdx++
 	_numFunc++
 
-	fnName := getFunctionNameFinal(fi)
-
 	var commentLines []string
 	commentLines = append(commentLines, symbol, "")
+	if xFunc != nil {
+		if docLines := getDocLines(xFunc.Doc.String()); len(docLines) > 0 {
+			commentLines = append(commentLines, docLines...)
+			commentLines = append(commentLines, "")
+		}
+	}
 
 	// 函数内变量名称分配器
 	var varReg VarReg
@@ -120,9 +170,16 @@ func pFunction(s *SourceFile, fi *gi.FunctionInfo, idxLv1, idxLv2 int) {
 	var newArgLines []string
 	// 传递给 invoker.Call 中的参数列表
 	var argNames []string
+	// cgo 模式下每个参数使用的 GIArgument 的字段，和 argNames 一一对应
+	var argCFields []string
+	// 是否通过 cgo 直接调用 C 函数，有参数或返回值不能用 GIArgument 传递，
+	// 或者参数的 C 类型未知时仍然使用 invoker
+	cgoCall := isCgoMode() && !strSliceContains(_cfg.FfiFuncs, identifyName)
 
 	// 在 invoker.Call 执行后需要执行的语句
 	var afterCallLines []string
+	// 所有权模式下在调用之后保持接收者和参数存活的语句
+	var keepAliveLines []string
 
 	var setParamLines []string
 
@@ -134,6 +191,9 @@ func pFunction(s *SourceFile, fi *gi.FunctionInfo, idxLv1, idxLv2 int) {
 
 	var varOutArgs string
 	var receiver string
+	var receiverVar string
+	// 形参对应的 C 函数参数的 index
+	var paramArgIdx []int
 
 	// 如果为 true，则 C 函数函数中最后一个是 **GError err
 	var isThrows bool
@@ -144,44 +204,12 @@ func pFunction(s *SourceFile, fi *gi.FunctionInfo, idxLv1, idxLv2 int) {
 		isThrows = true
 	}
 
+	fnName, addReceiver := getFunctionGoName(fi)
 	argIdxStart := 0
 	if container != nil {
-		addReceiver := false
-		//b.Pn("// container is not nil, container is %s", container.Name())
-		if fnFlags&gi.FUNCTION_IS_CONSTRUCTOR != 0 {
-			// 表示 C 函数是构造器
-			//b.Pn("// is constructor")
-		} else if fnFlags&gi.FUNCTION_IS_METHOD != 0 {
-			// 表示 C 函数是方法
-			//b.Pn("// is method")
-			addReceiver = true
-		} else {
-			// 可能 C 函数还是可以作为方法的，只不过没有处理好参数，如果第一个参数是指针类型，就大概率是方法。
-			if fi.NumArg() > 0 {
-				//b.Pn("// is method")
-				arg0 := fi.Arg(0)
-				arg0Type := arg0.Type()
-				//b.Pn("// arg0Type tag: %v, isPtr: %v", arg0Type.Tag(), arg0Type.IsPointer())
-				if arg0Type.IsPointer() && arg0Type.Tag() == gi.TYPE_TAG_INTERFACE {
-					ii := arg0Type.Interface()
-					if ii.Name() == container.Name() {
-						addReceiver = true
-						// 从 1 开始
-						argIdxStart = 1
-					}
-					ii.Unref()
-				}
-
-				if !addReceiver {
-					// 不能作为方法, 作为函数
-					fnName = container.Name() + fnName + "1"
-					// TODO: 适当消除 1 后缀
-				}
-			} else {
-				//b.Pn("// num arg is 0")
-				// 比如 io_channel_error_quark 方法，被重命名为IOChannel.error_quark，这算是 IOChannel 的 static 方法，
-				fnName = container.Name() + fnName + "1"
-			}
+		if addReceiver && fnFlags&gi.FUNCTION_IS_METHOD == 0 {
+			// 第一个参数作为接收者，从 1 开始
+			argIdxStart = 1
 		}
 
 		if addReceiver {
@@ -198,6 +226,7 @@ func pFunction(s *SourceFile, fi *gi.FunctionInfo, idxLv1, idxLv2 int) {
 
 			varV := varReg.alloc("v")
 			receiver = fmt.Sprintf("(%s %s)", varV, receiverType)
+			receiverVar = varV
 			varArgV := varReg.alloc("arg_v")
 			getPtrExpr := fmt.Sprintf("%s.P", varV)
 			if isContainerIfc {
@@ -206,6 +235,10 @@ func pFunction(s *SourceFile, fi *gi.FunctionInfo, idxLv1, idxLv2 int) {
 			newArgLines = append(newArgLines, fmt.Sprintf("%v := gi.NewPointerArgument(%s)",
 				varArgV, getPtrExpr))
 			argNames = append(argNames, varArgV)
+			argCFields = append(argCFields, "v_pointer")
+			if line := getKeepAliveLine(s, container, varV, isContainerIfc); line != "" {
+				keepAliveLines = append(keepAliveLines, line)
+			}
 		}
 	}
 
@@ -221,6 +254,11 @@ func pFunction(s *SourceFile, fi *gi.FunctionInfo, idxLv1, idxLv2 int) {
 		if dir == gi.DIRECTION_OUT || dir == gi.DIRECTION_INOUT {
 			paramComment += fmt.Sprintf(", dir: %v", dir)
 		}
+		if xFunc != nil {
+			if docLines := getDocLines(xFunc.GetParameterDoc(argInfo.Name())); len(docLines) > 0 {
+				paramComment += " " + strings.Join(docLines, " ")
+			}
+		}
 		commentLines = append(commentLines, paramComment, "")
 
 		argType := argInfo.Type()
@@ -248,11 +286,29 @@ func pFunction(s *SourceFile, fi *gi.FunctionInfo, idxLv1, idxLv2 int) {
 		}
 	}
 
+	// inout 参数的输入值作为目标函数的形参，更新后的值作为返回值，
+	// inoutInNames 的键是参数的 index，值是形参的名字，varReg 中的参数名是返回值的名字。
+	inoutInNames := make(map[int]string)
+	for i := argIdxStart; i < numArgs; i++ {
+		argInfo := fi.Arg(i)
+		if argInfo.Direction() == gi.DIRECTION_INOUT && !argInfo.IsCallerAllocates() {
+			inoutInNames[i] = varReg.getParam(i)
+			varReg.regParam(i, argInfo.Name())
+		}
+		argInfo.Unref()
+	}
+	// 在读取 inout 参数更新后的值之后才能执行的语句，比如释放输入的字符串
+	var inoutAfterLines []string
+
 	for i := argIdxStart; i < numArgs; i++ {
 		argInfo := fi.Arg(i)
 		argTypeInfo := argInfo.Type()
 		dir := argInfo.Direction()
 		isCallerAlloc := argInfo.IsCallerAllocates()
+		if dir == gi.DIRECTION_INOUT && isCallerAlloc {
+			// 由调用者分配内存的 inout 参数，被调用的函数直接修改它指向的内容，当作 in 参数处理。
+			dir = gi.DIRECTION_IN
+		}
 
 		switch dir {
 		case gi.DIRECTION_INOUT, gi.DIRECTION_OUT:
@@ -278,38 +334,90 @@ func pFunction(s *SourceFile, fi *gi.FunctionInfo, idxLv1, idxLv2 int) {
 			// 作为目标函数的输入参数之一
 
 			type0 := "int/*TODO:TYPE*/"
+			inParamName := paramName
 			if dir == gi.DIRECTION_IN {
-				parseResult := parseArgTypeDirIn(paramName, argTypeInfo, &varReg)
+				parseResult := parseArgTypeDirIn(paramName, argTypeInfo, &varReg, argInfo.OwnershipTransfer())
 
 				type0 = parseResult.type0
+				_report.addTodoType(newReportEntry("function", symbol, i, argInfo.Name(),
+					argInfo.Direction().String(), argTypeInfo), type0)
 				beforeArgLines = append(beforeArgLines, parseResult.beforeArgLines...)
 
 				varArg := varReg.alloc("arg_" + paramName)
 				argNames = append(argNames, varArg)
+				argCField := getArgumentCField(argTypeInfo)
+				if argCField == "" {
+					cgoCall = false
+				}
+				argCFields = append(argCFields, argCField)
 				newArgLines = append(newArgLines, fmt.Sprintf("%v := %v", varArg, parseResult.newArgExpr))
 
 				afterCallLines = append(afterCallLines, parseResult.afterCallLines...)
+				if line := getArgKeepAliveLine(s, argTypeInfo, paramName); line != "" {
+					keepAliveLines = append(keepAliveLines, line)
+				}
 			} else {
-				// TODO：处理 dir 为 inout 的
+				// dir 为 inout
+				inParamName = inoutInNames[i]
+				parseResult := parseArgTypeDirInOut(inParamName, paramName, argTypeInfo, &varReg,
+					argInfo.OwnershipTransfer())
+				inResult := parseResult.in
+				type0 = inResult.type0
+				beforeArgLines = append(beforeArgLines, inResult.beforeArgLines...)
+
+				// 把输入值放在 outArgs 中，传入它的地址，调用后从同一位置读取更新后的值
+				varArg := varReg.alloc("arg_" + inParamName)
+				argNames = append(argNames, varArg)
+				argCFields = append(argCFields, "v_pointer")
+				newArgLines = append(newArgLines,
+					fmt.Sprintf("%v[%v] = %v", varOutArgs, outArgIdx, inResult.newArgExpr),
+					fmt.Sprintf("%v := gi.NewPointerArgument(unsafe.Pointer(&%v[%v]))",
+						varArg, varOutArgs, outArgIdx))
+				inoutAfterLines = append(inoutAfterLines, inResult.afterCallLines...)
+
+				outResult := parseResult.out
+				outType0 := outResult.type0
+				_report.addTodoType(newReportEntry("function", symbol, i, argInfo.Name(),
+					dir.String(), argTypeInfo), type0+outType0+outResult.expr)
+				if _, ok := lenArgMap[i]; ok {
+					// 参数是数组的长度
+					afterCallLines = append(afterCallLines,
+						fmt.Sprintf("var %v %v; _ = %v", paramName, outType0, paramName))
+				} else {
+					retParams = append(retParams, paramName+" "+outType0)
+				}
+
+				getValExpr := fmt.Sprintf("%v[%v].%v", varOutArgs, outArgIdx, outResult.expr)
+				setParamLine := fmt.Sprintf("%v%v = %v", paramName, outResult.field, getValExpr)
+				if outResult.needTypeCast {
+					setParamLine = fmt.Sprintf("%v%v = %v(%s)", paramName, outResult.field, outType0, getValExpr)
+				}
+				setParamLines = append(setParamLines, setParamLine)
+				beforeRetLines = append(beforeRetLines, outResult.beforeRetLines...)
+				outArgIdx++
 			}
 
-			params = append(params, paramName+" "+type0)
+			params = append(params, inParamName+" "+type0)
+			paramArgIdx = append(paramArgIdx, i)
 
 		} else if dir == gi.DIRECTION_OUT {
 			// 作为目标函数的返回值之一
 			parseResult := parseArgTypeDirOut(paramName, argTypeInfo, &varReg, isCallerAlloc,
 				argInfo.OwnershipTransfer())
 			type0 := parseResult.type0
+			_report.addTodoType(newReportEntry("function", symbol, i, argInfo.Name(),
+				dir.String(), argTypeInfo), type0+parseResult.expr)
 			if _, ok := lenArgMap[i]; ok {
 				// 参数是数组的长度
 				afterCallLines = append(afterCallLines,
 					fmt.Sprintf("var %v %v; _ = %v", paramName, type0, paramName))
-			} else if parseResult.isRet {
+			} else if parseResult.isRet || parseResult.goAlloc {
 				retParams = append(retParams, paramName+" "+type0)
 			}
 
 			varArg := varReg.alloc("arg_" + paramName)
 			argNames = append(argNames, varArg)
+			argCFields = append(argCFields, "v_pointer")
 
 			if parseResult.isRet {
 				newArgLines = append(newArgLines, fmt.Sprintf("%v := gi.NewPointerArgument(unsafe.Pointer(&%v[%v]))", varArg, varOutArgs, outArgIdx))
@@ -327,11 +435,24 @@ func pFunction(s *SourceFile, fi *gi.FunctionInfo, idxLv1, idxLv2 int) {
 				// 或 param1.P = outArgs[1].Pointer()
 				setParamLines = append(setParamLines, setParamLine)
 				outArgIdx++
+			} else if parseResult.goAlloc {
+				// 传入返回值变量的地址，被调用的函数直接填写它
+				newArgLines = append(newArgLines,
+					fmt.Sprintf("%v := gi.NewPointerArgument(unsafe.Pointer(&%v))", varArg, paramName))
 			} else {
 				// out 类型的参数，依旧作为目标函数的参数，一般是指针类型
 				params = append(params, paramName+" "+parseResult.type0)
+				paramArgIdx = append(paramArgIdx, i)
 				newArgLines = append(newArgLines,
 					fmt.Sprintf("%v := gi.NewPointerArgument(%v)", varArg, parseResult.expr))
+				if argTypeInfo.Tag() == gi.TYPE_TAG_INTERFACE {
+					// 由调用者分配的结构体，参数的类型是 Xxx 结构体
+					bi := argTypeInfo.Interface()
+					if line := getKeepAliveLine(s, bi, paramName, false); line != "" {
+						keepAliveLines = append(keepAliveLines, line)
+					}
+					bi.Unref()
+				}
 			}
 
 			beforeRetLines = append(beforeRetLines, parseResult.beforeRetLines...)
@@ -348,6 +469,7 @@ func pFunction(s *SourceFile, fi *gi.FunctionInfo, idxLv1, idxLv2 int) {
 		}
 		varArg := varReg.alloc("arg_" + varErr)
 		argNames = append(argNames, varArg)
+		argCFields = append(argCFields, "v_pointer")
 		newArgLines = append(newArgLines, fmt.Sprintf("%v := gi.NewPointerArgument(unsafe.Pointer(&%v[%v]))", varArg, varOutArgs, outArgIdx))
 		afterCallLines = append(afterCallLines, fmt.Sprintf("%v = gi.ToError(%v[%v].%v)", varErr, varOutArgs, outArgIdx, "Pointer()"))
 		retParams = append(retParams, varErr+" error")
@@ -356,6 +478,7 @@ func pFunction(s *SourceFile, fi *gi.FunctionInfo, idxLv1, idxLv2 int) {
 	var varRet string
 	var varResult string
 	var parseRetTypeResult *parseRetTypeResult
+	var retCField string
 
 	// 是否**无**返回值
 	var isRetVoid bool
@@ -366,12 +489,43 @@ func pFunction(s *SourceFile, fi *gi.FunctionInfo, idxLv1, idxLv2 int) {
 		// 有返回值
 		varRet = varReg.alloc("ret")
 		varResult = varReg.alloc("result")
-		parseRetTypeResult = parseRetType(varRet, retTypeInfo, &varReg, fi, fi.CallerOwns())
+		retCField = getArgumentCField(retTypeInfo)
+		if retCField == "" {
+			cgoCall = false
+		}
+		retTransfer := fi.CallerOwns()
+		if isBoxedOwnMethod(fi, container, "copy") {
+			// 复制出来的实例由调用者拥有，不管 GIR 中是否标注了
+			retTransfer = gi.TRANSFER_EVERYTHING
+		}
+		parseRetTypeResult = parseRetType(varRet, retTypeInfo, &varReg, fi, retTransfer)
+		_report.addTodoType(newReportEntry("function", symbol, -1, "", dirReturn, retTypeInfo),
+			parseRetTypeResult.type0+parseRetTypeResult.expr)
 		// 把返回值加在 retParams 列表最前面
 		retParams = append([]string{varResult + " " + parseRetTypeResult.type0}, retParams...)
 
-		commentLines = append(commentLines, fmt.Sprintf(
-			"[ %v ] trans: %v", varResult, fi.CallerOwns()), "")
+		retComment := fmt.Sprintf("[ %v ] trans: %v", varResult, fi.CallerOwns())
+		if xFunc != nil {
+			if docLines := getDocLines(xFunc.GetReturnDoc()); len(docLines) > 0 {
+				retComment += " " + strings.Join(docLines, " ")
+			}
+		}
+		commentLines = append(commentLines, retComment, "")
+	}
+
+	// cgo 模式下直接调用 C 函数时传入的参数列表
+	var cgoArgExprs []string
+	if cgoCall {
+		cgoArgExprs, cgoCall = getCgoArgExprs(xFunc, argNames, argCFields)
+	}
+
+	if fi.IsDeprecated() {
+		var version, docDeprecated string
+		if xFunc != nil {
+			version, docDeprecated = xFunc.Deprecation()
+		}
+		commentLines = append(commentLines, getDeprecatedDocLines(version, docDeprecated)...)
+		commentLines = append(commentLines, "")
 	}
 
 	for _, line := range commentLines {
@@ -386,85 +540,97 @@ func pFunction(s *SourceFile, fi *gi.FunctionInfo, idxLv1, idxLv2 int) {
 	}
 	// 输出目标函数头部
 	b.Pn("func %s %s(%s) %s {", receiver, fnName, paramsJoined, retParamsJoined)
+	if receiverVar != "" {
+		for _, line := range getBoxedFreeLines(fi, container, receiverVar) {
+			b.Pn(line)
+		}
+	}
 
-	varInvoker := varReg.alloc("iv")
+	var varInvoker string
+	if !cgoCall {
+		varInvoker = varReg.alloc("iv")
 
-	useGet1 := false
-	if _optNamespace == "GObject" || _optNamespace == "Gio" {
-		useGet1 = true
-	}
+		useGet1 := false
+		if _optNamespace == "GObject" || _optNamespace == "Gio" {
+			useGet1 = true
+		}
 
-	// Get1(id uint, ns, nameLv1, nameLv2 string, idxLv1, idxLv2 int, infoType InfoType, flags FindMethodFlags)
-	//id: funcIdx
-	// ns: quote _optNamespace
-	// nameLv1: quote fiName | quote container.Name()
-	// nameLv2: "" | quote fiName
-	// idxLv1: idxLv1
-	// idxLv2: idxLv2
-	// infoType: gi.INFO_TYPE_FUNCTION | gi.INFO_TYPE_XX (XX is STRUCT,UNION,OBJECT,INTERFACE)
-	// flags: 0 or gi.FindMethodNoCallFind
-	getArgs := []interface{}{funcIdx} // id
-	if useGet1 {
-		getArgs = append(getArgs, strconv.Quote(_optNamespace)) // ns
-	}
-	// nameLv1, nameLv2
-	if container == nil {
-		getArgs = append(getArgs, strconv.Quote(fiName)) // nameLv1
-		getArgs = append(getArgs, `""`)                  // nameLv2
-	} else {
-		getArgs = append(getArgs, strconv.Quote(container.Name())) // nameLv1
-		getArgs = append(getArgs, strconv.Quote(fiName))           // nameLv2
-	}
+		// Get1(id uint, ns, nameLv1, nameLv2 string, idxLv1, idxLv2 int, infoType InfoType, flags FindMethodFlags)
+		//id: funcIdx
+		// ns: quote _optNamespace
+		// nameLv1: quote fiName | quote container.Name()
+		// nameLv2: "" | quote fiName
+		// idxLv1: idxLv1
+		// idxLv2: idxLv2
+		// infoType: gi.INFO_TYPE_FUNCTION | gi.INFO_TYPE_XX (XX is STRUCT,UNION,OBJECT,INTERFACE)
+		// flags: 0 or gi.FindMethodNoCallFind
+		getArgs := []interface{}{funcIdx} // id
+		if useGet1 {
+			getArgs = append(getArgs, strconv.Quote(_optNamespace)) // ns
+		}
+		// nameLv1, nameLv2
+		if container == nil {
+			getArgs = append(getArgs, strconv.Quote(fiName)) // nameLv1
+			getArgs = append(getArgs, `""`)                  // nameLv2
+		} else {
+			getArgs = append(getArgs, strconv.Quote(container.Name())) // nameLv1
+			getArgs = append(getArgs, strconv.Quote(fiName))           // nameLv2
+		}
 
-	getArgs = append(getArgs, idxLv1) // idxLv1
-	getArgs = append(getArgs, idxLv2) // idxLv2
+		getArgs = append(getArgs, idxLv1) // idxLv1
+		getArgs = append(getArgs, idxLv2) // idxLv2
+
+		// infoType
+		infoType := "FUNCTION"
+		if container != nil {
+			switch container.Type() {
+			case gi.INFO_TYPE_STRUCT:
+				infoType = "STRUCT"
+			case gi.INFO_TYPE_UNION:
+				infoType = "UNION"
+			case gi.INFO_TYPE_OBJECT:
+				infoType = "OBJECT"
+			case gi.INFO_TYPE_INTERFACE:
+				infoType = "INTERFACE"
+			case gi.INFO_TYPE_ENUM:
+				infoType = "ENUM"
+			case gi.INFO_TYPE_FLAGS:
+				infoType = "FLAGS"
+			}
+		}
+		getArgs = append(getArgs, "gi.INFO_TYPE_"+infoType) // infoType
 
-	// infoType
-	infoType := "FUNCTION"
-	if container != nil {
-		switch container.Type() {
-		case gi.INFO_TYPE_STRUCT:
-			infoType = "STRUCT"
-		case gi.INFO_TYPE_UNION:
-			infoType = "UNION"
-		case gi.INFO_TYPE_OBJECT:
-			infoType = "OBJECT"
-		case gi.INFO_TYPE_INTERFACE:
-			infoType = "INTERFACE"
+		findMethodFlags := "0"
+		if _optNamespace == "GObject" && container != nil && container.Name() == "ObjectClass" {
+			// 因为调用 StructInfo.FindMethod 方法去查找 GObject.ObjectClass 的方法会导致崩溃，所以加上这个 flag 来规避。
+			findMethodFlags = "gi.FindMethodNoCallFind"
 		}
-	}
-	getArgs = append(getArgs, "gi.INFO_TYPE_"+infoType) // infoType
+		getArgs = append(getArgs, findMethodFlags) // flags
 
-	findMethodFlags := "0"
-	if _optNamespace == "GObject" && container != nil && container.Name() == "ObjectClass" {
-		// 因为调用 StructInfo.FindMethod 方法去查找 GObject.ObjectClass 的方法会导致崩溃，所以加上这个 flag 来规避。
-		findMethodFlags = "gi.FindMethodNoCallFind"
-	}
-	getArgs = append(getArgs, findMethodFlags) // flags
+		b.P("%v, %v := _I.Get", varInvoker, varErr)
+		if useGet1 {
+			b.P("1")
+		}
+		getArgsStr := make([]string, len(getArgs))
+		for i, v := range getArgs {
+			getArgsStr[i] = fmt.Sprintf("%v", v)
+		}
+		b.Pn("(%v)", strings.Join(getArgsStr, ", "))
 
-	b.P("%v, %v := _I.Get", varInvoker, varErr)
-	if useGet1 {
-		b.P("1")
-	}
-	getArgsStr := make([]string, len(getArgs))
-	for i, v := range getArgs {
-		getArgsStr[i] = fmt.Sprintf("%v", v)
-	}
-	b.Pn("(%v)", strings.Join(getArgsStr, ", "))
+		{ // 处理 invoker 获取失败的情况
 
-	{ // 处理 invoker 获取失败的情况
+			b.Pn("if %s != nil {", varErr)
 
-		b.Pn("if %s != nil {", varErr)
+			if isThrows {
+				// 使用 err 变量返回错误
+			} else {
+				// 把 err 打印出来
+				b.Pn("log.Println(\"WARN:\", %s)", varErr)
+			}
+			b.Pn("return")
 
-		if isThrows {
-			// 使用 err 变量返回错误
-		} else {
-			// 把 err 打印出来
-			b.Pn("log.Println(\"WARN:\", %s)", varErr)
+			b.Pn("}") // end if err != nil
 		}
-		b.Pn("return")
-
-		b.Pn("}") // end if err != nil
 	}
 
 	if numOutArgs > 0 {
@@ -479,25 +645,39 @@ func pFunction(s *SourceFile, fi *gi.FunctionInfo, idxLv1, idxLv2 int) {
 		b.Pn(line)
 	}
 
-	callArgArgs := "nil"
-	if len(argNames) > 0 {
-		// 比如输出 args := []gi.Argument{arg0,arg1}
-		varArgs := varReg.alloc("args")
-		b.Pn("%s := []gi.Argument{%s}", varArgs, strings.Join(argNames, ", "))
-		callArgArgs = varArgs
-	}
+	if cgoCall {
+		// 直接调用 C 函数，参数转换为 C 函数声明的类型
+		call := getCgoCallExpr(symbol, cgoArgExprs, retCField)
+		if isRetVoid {
+			b.Pn("%s", call)
+		} else {
+			b.Pn("%s := %s", varRet, call)
+		}
+	} else {
+		callArgArgs := "nil"
+		if len(argNames) > 0 {
+			// 比如输出 args := []gi.Argument{arg0,arg1}
+			varArgs := varReg.alloc("args")
+			b.Pn("%s := []gi.Argument{%s}", varArgs, strings.Join(argNames, ", "))
+			callArgArgs = varArgs
+		}
 
-	callArgRet := "nil"
-	if !isRetVoid {
-		// 有返回值
-		callArgRet = "&" + varRet
-		b.Pn("var %s gi.Argument", varRet)
+		callArgRet := "nil"
+		if !isRetVoid {
+			// 有返回值
+			callArgRet = "&" + varRet
+			b.Pn("var %s gi.Argument", varRet)
+		}
+		callArgOutArgs := "nil"
+		if numOutArgs > 0 {
+			callArgOutArgs = fmt.Sprintf("&%s[0]", varOutArgs)
+		}
+		b.Pn("%s.Call(%s, %s, %s)", varInvoker, callArgArgs, callArgRet, callArgOutArgs)
 	}
-	callArgOutArgs := "nil"
-	if numOutArgs > 0 {
-		callArgOutArgs = fmt.Sprintf("&%s[0]", varOutArgs)
+
+	for _, line := range keepAliveLines {
+		b.Pn(line)
 	}
-	b.Pn("%s.Call(%s, %s, %s)", varInvoker, callArgArgs, callArgRet, callArgOutArgs)
 
 	for _, line := range afterCallLines {
 		b.Pn(line)
@@ -507,10 +687,13 @@ func pFunction(s *SourceFile, fi *gi.FunctionInfo, idxLv1, idxLv2 int) {
 		b.Pn(line)
 	}
 
+	for _, line := range inoutAfterLines {
+		b.Pn(line)
+	}
+
 	if !isRetVoid && parseRetTypeResult != nil {
-		b.Pn("%s%s = %s", varResult, parseRetTypeResult.field, parseRetTypeResult.expr)
-		if parseRetTypeResult.zeroTerm {
-			b.Pn("%v.SetLenZT()", varResult)
+		for _, line := range parseRetTypeResult.assignLines(varResult) {
+			b.Pn(line)
 		}
 	}
 
@@ -527,6 +710,28 @@ func pFunction(s *SourceFile, fi *gi.FunctionInfo, idxLv1, idxLv2 int) {
 		_numTodoFunc++
 	}
 	s.GoBody.addBlock(b)
+
+	sig := &funcSignature{
+		fnName:      fnName,
+		receiver:    receiver,
+		receiverVar: receiverVar,
+		params:      params,
+		paramArgIdx: paramArgIdx,
+		retParams:   retParams,
+		symbol:      symbol,
+		deprecated:  fi.IsDeprecated(),
+
+		cancellableIdx: getCancellableArgIdx(fi),
+	}
+	if strings.HasSuffix(identifyName, "_async") {
+		sig.cbIdx, sig.closureIdx, sig.resField = getAsyncCallbackArgIdx(fi)
+	} else {
+		pCancellableFunc(s, sig)
+	}
+	_funcSignatures[identifyName] = sig
+	if asyncSig, finishSig := getAsyncPair(identifyName); asyncSig != nil {
+		pAsyncFunc(s, asyncSig, finishSig)
+	}
 }
 
 type parseRetTypeResult struct {
@@ -534,6 +739,20 @@ type parseRetTypeResult struct {
 	field    string // expr 要给 result 的什么字段设置，比如 .P 字段
 	type0    string // 目标函数中返回值类型
 	zeroTerm bool
+	// 不能用一个表达式转换时，返回给 result 赋值的语句，比如把 GList 转换为切片。
+	assign func(varResult string) []string
+}
+
+// assignLines 返回把转换后的值赋给 varResult 的语句
+func (r *parseRetTypeResult) assignLines(varResult string) []string {
+	if r.assign != nil {
+		return r.assign(varResult)
+	}
+	lines := []string{fmt.Sprintf("%v%v = %v", varResult, r.field, r.expr)}
+	if r.zeroTerm {
+		lines = append(lines, varResult+".SetLenZT()")
+	}
+	return lines
 }
 
 func parseRetType(varRet string, ti *gi.TypeInfo, varReg *VarReg, fi *gi.FunctionInfo,
@@ -545,7 +764,12 @@ func parseRetType(varRet string, ti *gi.TypeInfo, varReg *VarReg, fi *gi.Functio
 	expr := varRet + ".Int()/*TODO*/"
 	field := ""
 	zeroTerm := false
-	fiFlags := fi.Flags()
+	var assign func(varResult string) []string
+	// fi 可能为 nil，比如处理属性的类型时
+	var fiFlags gi.FunctionInfoFlags
+	if fi != nil {
+		fiFlags = fi.Flags()
+	}
 
 	switch tag {
 	case gi.TYPE_TAG_UTF8, gi.TYPE_TAG_FILENAME:
@@ -595,6 +819,16 @@ func parseRetType(varRet string, ti *gi.TypeInfo, varReg *VarReg, fi *gi.Functio
 			expr = fmt.Sprintf("%v.Pointer()", varRet)
 			field = ".P"
 
+			if _cfg != nil && _cfg.Ownership {
+				// 所有权模式，由 OwnRef 字段持有实例的一个引用，在被垃圾回收时释放。
+				if assignOwned := getOwnershipAssign(bi, type0, transfer); assignOwned != nil {
+					ptrExpr := expr
+					assign = func(varResult string) []string {
+						return assignOwned(varResult, ptrExpr)
+					}
+				}
+			}
+
 		} else {
 			if biType == gi.INFO_TYPE_FLAGS {
 				type0 = getFlagsTypeName(getTypeName(bi))
@@ -615,18 +849,25 @@ func parseRetType(varRet string, ti *gi.TypeInfo, varReg *VarReg, fi *gi.Functio
 		type0 = "gi.GType"
 		expr = fmt.Sprintf("gi.GType(%v.Uint())", varRet)
 
-	case gi.TYPE_TAG_GHASH:
-		type0 = getGLibType("HashTable")
-		expr = fmt.Sprintf("%v.Pointer()", varRet)
-		field = ".P"
-
-	case gi.TYPE_TAG_GLIST:
-		type0 = getGLibType("List")
-		expr = fmt.Sprintf("%v.Pointer()", varRet)
-		field = ".P"
-
-	case gi.TYPE_TAG_GSLIST:
-		type0 = getGLibType("SList")
+	case gi.TYPE_TAG_GHASH, gi.TYPE_TAG_GLIST, gi.TYPE_TAG_GSLIST:
+		// 产生类似如下代码：
+		// list := g.List{P: ret.Pointer()}
+		// list.ForEach(func(item unsafe.Pointer) {
+		// result = append(result, Widget{P: item})
+		// })
+		// list.Free()
+		if result := parseContainerRetType(varRet, ti, varReg, transfer); result != nil {
+			return result
+		}
+		// 不支持的元素类型，使用 g 包中的容器类型。
+		switch tag {
+		case gi.TYPE_TAG_GHASH:
+			type0 = getGLibType("HashTable")
+		case gi.TYPE_TAG_GLIST:
+			type0 = getGLibType("List")
+		case gi.TYPE_TAG_GSLIST:
+			type0 = getGLibType("SList")
+		}
 		expr = fmt.Sprintf("%v.Pointer()", varRet)
 		field = ".P"
 
@@ -655,7 +896,7 @@ func parseRetType(varRet string, ti *gi.TypeInfo, varReg *VarReg, fi *gi.Functio
 				type0 = "gi." + elemType + "Array"
 
 				argName := "0"
-				if lenArgIdx >= 0 {
+				if lenArgIdx >= 0 && fi != nil {
 					argInfo := fi.Arg(lenArgIdx)
 					argName = argInfo.Name()
 					argInfo.Unref()
@@ -698,6 +939,7 @@ func parseRetType(varRet string, ti *gi.TypeInfo, varReg *VarReg, fi *gi.Functio
 		expr:     expr,
 		type0:    type0,
 		zeroTerm: zeroTerm,
+		assign:   assign,
 	}
 }
 
@@ -714,6 +956,8 @@ type parseArgTypeDirOutResult struct {
 	field          string // 表达式赋值的字段
 	beforeRetLines []string
 	isRet          bool // 是否作为返回值
+	// 由调用者分配内存的参数改为由 Go 分配，传入返回值变量的地址，调用后按值返回
+	goAlloc bool
 }
 
 func shouldArgAsReturn(ti *gi.TypeInfo, isCallerAlloc bool) bool {
@@ -748,6 +992,7 @@ func parseArgTypeDirOut(paramName string, ti *gi.TypeInfo, varReg *VarReg,
 	needTypeCast := false
 	field := ""
 	isRet := true
+	goAlloc := false
 	var beforeRetLines []string
 
 	switch tag {
@@ -813,8 +1058,14 @@ func parseArgTypeDirOut(paramName string, ti *gi.TypeInfo, varReg *VarReg,
 			} else if biType == gi.INFO_TYPE_STRUCT {
 				if isCallerAlloc {
 					isRet = false
-					type0 = getTypeName(bi)
-					expr = paramName + ".P"
+					if isScalarStruct(bi) {
+						// 只有简单类型字段的结构体，使用 Go 的内存
+						goAlloc = true
+						type0 = getTypeName(bi) + "Value"
+					} else {
+						type0 = getTypeName(bi)
+						expr = paramName + ".P"
+					}
 				}
 			}
 		}
@@ -908,6 +1159,12 @@ func parseArgTypeDirOut(paramName string, ti *gi.TypeInfo, varReg *VarReg,
 					type0 = "gi.CStrArray"
 					expr = "Pointer()"
 					field = ".P"
+
+					if lenArgIdx >= 0 {
+						lenArgName := varReg.getParam(lenArgIdx)
+						beforeRetLines = append(beforeRetLines,
+							fmt.Sprintf("%v.Len = int(%v)", paramName, lenArgName))
+					}
 				} else if elemTypeTag == gi.TYPE_TAG_INTERFACE && elemTypeInfo.IsPointer() {
 					type0 = "gi.PointerArray"
 					expr = "Pointer()"
@@ -948,11 +1205,30 @@ func parseArgTypeDirOut(paramName string, ti *gi.TypeInfo, varReg *VarReg,
 		field:          field,
 		beforeRetLines: beforeRetLines,
 		isRet:          isRet,
+		goAlloc:        goAlloc,
 	}
 }
 
-func parseArgTypeDirInOut() {
-	// TODO
+type parseArgTypeDirInOutResult struct {
+	in  *parseArgTypeDirInResult  // 处理输入值
+	out *parseArgTypeDirOutResult // 处理更新后的值
+}
+
+// parseArgTypeDirInOut 处理 direction 为 inout 的参数，inParamName 是输入值的形参名，
+// outParamName 是更新后的值的返回值名。
+func parseArgTypeDirInOut(inParamName, outParamName string, ti *gi.TypeInfo, varReg *VarReg,
+	transfer gi.Transfer) *parseArgTypeDirInOutResult {
+
+	inResult := parseArgTypeDirIn(inParamName, ti, varReg, transfer)
+	if transfer == gi.TRANSFER_EVERYTHING {
+		// 被调用的函数获得了输入值的所有权，不能再释放它。
+		inResult.afterCallLines = nil
+	}
+	outResult := parseArgTypeDirOut(outParamName, ti, varReg, false, transfer)
+	return &parseArgTypeDirInOutResult{
+		in:  inResult,
+		out: outResult,
+	}
 }
 
 func getTypeWithTag(tag gi.TypeTag) (type0 string) {
@@ -1047,7 +1323,8 @@ type parseArgTypeDirInResult struct {
 	afterCallLines []string // 在 invoker.Call() 之后执行的语句
 }
 
-func parseArgTypeDirIn(varArg string, ti *gi.TypeInfo, varReg *VarReg) *parseArgTypeDirInResult {
+// parseArgTypeDirIn 处理 direction 为 in 的参数，transfer 决定调用之后释放 GList 等容器的哪些部分。
+func parseArgTypeDirIn(varArg string, ti *gi.TypeInfo, varReg *VarReg, transfer gi.Transfer) *parseArgTypeDirInResult {
 	// 处理 direction 为 in 的情况
 	var beforeArgLines []string
 	var afterCallLines []string
@@ -1131,9 +1408,15 @@ func parseArgTypeDirIn(varArg string, ti *gi.TypeInfo, varReg *VarReg) *parseArg
 				type0 = getEnumTypeName(getTypeName(bi))
 				newArgExpr = fmt.Sprintf("gi.NewIntArgument(int(%v))", varArg)
 			} else if biType == gi.INFO_TYPE_CALLBACK {
-				type0 = getDebugType("CALLBACK")
-				newArgExpr = fmt.Sprintf("gi.NewPointerArgument(unsafe.Pointer(%vGetPointer_my%v()))",
-					getPkgPrefix(bi.Namespace()), bi.Name())
+				if getCallbackClosureIdx(gi.ToCallableInfo(bi)) >= 0 {
+					type0 = getDebugType("CALLBACK")
+					newArgExpr = fmt.Sprintf("gi.NewPointerArgument(unsafe.Pointer(%vGetPointer_my%v()))",
+						getPkgPrefix(bi.Namespace()), bi.Name())
+				} else {
+					// 回调没有 user_data 参数，传入 NewXXXTrampoline 分配的跳板函数
+					type0 = getPkgPrefix(bi.Namespace()) + bi.Name() + "Trampoline"
+					newArgExpr = fmt.Sprintf("gi.NewPointerArgument(%v.P)", varArg)
+				}
 			}
 		}
 		bi.Unref()
@@ -1146,16 +1429,19 @@ func parseArgTypeDirIn(varArg string, ti *gi.TypeInfo, varReg *VarReg) *parseArg
 		type0 = "gi.GType"
 		newArgExpr = fmt.Sprintf("gi.NewUintArgument(uint(%v))", varArg)
 
-	case gi.TYPE_TAG_GHASH:
-		type0 = getGLibType("HashTable")
-		newArgExpr = fmt.Sprintf("gi.NewPointerArgument(%v.P)", varArg)
-
-	case gi.TYPE_TAG_GLIST:
-		type0 = getGLibType("List")
-		newArgExpr = fmt.Sprintf("gi.NewPointerArgument(%v.P)", varArg)
-
-	case gi.TYPE_TAG_GSLIST:
-		type0 = getGLibType("SList")
+	case gi.TYPE_TAG_GHASH, gi.TYPE_TAG_GLIST, gi.TYPE_TAG_GSLIST:
+		if result := parseContainerArgTypeDirIn(varArg, ti, varReg, transfer); result != nil {
+			return result
+		}
+		// 不支持的元素类型，使用 g 包中的容器类型。
+		switch tag {
+		case gi.TYPE_TAG_GHASH:
+			type0 = getGLibType("HashTable")
+		case gi.TYPE_TAG_GLIST:
+			type0 = getGLibType("List")
+		case gi.TYPE_TAG_GSLIST:
+			type0 = getGLibType("SList")
+		}
 		newArgExpr = fmt.Sprintf("gi.NewPointerArgument(%v.P)", varArg)
 
 	case gi.TYPE_TAG_ARRAY:
@@ -1241,16 +1527,22 @@ func getPkgPrefix(ns string) string {
 		}
 	}
 
-	ret := strings.ToLower(ns) + "."
-	if ret == "glib." || ret == "gobject." || ret == "gio." {
-		ret = "g."
-	}
+	ret := getPkgName(ns) + "."
 	if pkgBase != "" {
 		_sourceFile.AddGirImport(pkgBase)
 	}
 	return ret
 }
 
+// getPkgName 返回命名空间 ns 对应的 Go 包名
+func getPkgName(ns string) string {
+	ret := strings.ToLower(ns)
+	if ret == "glib" || ret == "gobject" || ret == "gio" {
+		ret = "g"
+	}
+	return ret
+}
+
 func addGirImport(ns string) {
 	pkgBase := ""
 	for _, dep := range _deps {
@@ -1272,7 +1564,7 @@ func getAllDeps(repo *gi.Repository, namespace string) []string {
 		nameVer := strings.SplitN(namespace, "-", 2)
 		namespace = nameVer[0]
 		version := nameVer[1]
-		_, err := repo.Require(namespace, version, gi.REPOSITORY_LOAD_FLAG_LAZY)
+		err := repo.Require(namespace, version, gi.REPOSITORY_LOAD_FLAG_LAZY)
 		if err != nil {
 			log.Fatal(err)
 		}
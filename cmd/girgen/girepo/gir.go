@@ -0,0 +1,848 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package girepo
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/electricface/go-gir3/cmd/girgen/xmlp"
+)
+
+// xRepository 是只使用 GIR 文件的 Repository 的实现，名字空间由 xmlp.Load 加载，
+// 第一次使用时才转换为 xNamespace。
+type xRepository struct {
+	namespaces map[string]*xNamespace
+}
+
+type xNamespace struct {
+	infos  []*xInfo // 顶层的 info，按名字排序
+	byName map[string]*xInfo
+}
+
+func (r *xRepository) namespace(name string) *xNamespace {
+	if ns, ok := r.namespaces[name]; ok {
+		return ns
+	}
+	repo := xmlp.GetLoadedRepo(name)
+	if repo == nil {
+		panic("namespace " + name + " is not loaded")
+	}
+	b := &xBuilder{ns: name}
+	ns := b.build(repo.Namespace)
+	r.namespaces[name] = ns
+	return ns
+}
+
+func (r *xRepository) findByName(namespace, name string) *xInfo {
+	if xmlp.GetLoadedRepo(namespace) == nil {
+		return nil
+	}
+	return r.namespace(namespace).byName[name]
+}
+
+// resolve 根据完整的类型名（如 GObject.Object）找到 info，找不到时返回 INFO_TYPE_UNRESOLVED 类型的 info。
+func resolve(fullName string) *xInfo {
+	parts := strings.SplitN(fullName, ".", 2)
+	if len(parts) == 2 {
+		xi := _xDefaultRepo.findByName(parts[0], parts[1])
+		if xi != nil {
+			return xi
+		}
+		return &xInfo{infoType: INFO_TYPE_UNRESOLVED, ns: parts[0], name: parts[1]}
+	}
+	return &xInfo{infoType: INFO_TYPE_UNRESOLVED, name: fullName}
+}
+
+// xInfo 是从 GIR 文件中得到的一项信息，相当于 typelib 中的一个 GIBaseInfo，
+// 不同类型的 info 使用不同的字段。
+type xInfo struct {
+	infoType   InfoType
+	ns         string
+	name       string
+	deprecated bool
+	container  *xInfo
+
+	// 参数，返回值（在 callable 中），字段，属性和常量的类型，类型本身（INFO_TYPE_TYPE）
+	type0 *xType
+
+	// 参数
+	direction       Direction
+	callerAllocates bool
+	optional        bool
+	nullable        bool // 对于 callable 是返回值是否可为 nil
+	transfer        Transfer
+	scope           ScopeType
+	closure         int
+	destroy         int
+
+	// callable
+	args       []*xInfo
+	symbol     string
+	funcFlags  FunctionInfoFlags
+	vfuncFlags VFuncInfoFlags
+	invoker    string
+
+	// 字段
+	fieldFlags FieldInfoFlags
+	bits       int
+	offset     int
+
+	// 属性
+	paramFlags ParamFlags
+
+	// 常量
+	constValue string
+
+	// 枚举值
+	value int64
+
+	// 注册类型
+	typeName    string
+	typeInit    string
+	values      []*xInfo
+	errorDomain string
+	fields      []*xInfo
+	methods     []*xInfo
+	properties  []*xInfo
+	signals     []*xInfo
+	vfuncs      []*xInfo
+	interfaces  []string // 对象实现的接口或接口的前提，都是完整的类型名
+	parent      string
+	classStruct string
+	refFunc     string
+	unrefFunc   string
+	abstract    bool
+	fundamental bool
+	gtypeStruct bool
+	foreign     bool
+
+	// 内存布局，见 computeLayout
+	layout layoutState
+	size   int
+	align  int
+}
+
+func (x *xInfo) typeInfo(t *xType) *TypeInfo {
+	return &TypeInfo{BaseInfo{x: &xInfo{infoType: INFO_TYPE_TYPE, ns: x.ns, type0: t}}}
+}
+
+func (x *xInfo) findMethod(name string) *xInfo {
+	for _, m := range x.methods {
+		if m.name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// isBoxed 返回是否是 boxed 类型，有 get-type 函数的 struct 和 union 是 boxed 类型，类结构体除外。
+func (x *xInfo) isBoxed() bool {
+	switch x.infoType {
+	case INFO_TYPE_STRUCT, INFO_TYPE_UNION:
+		return x.typeInit != "" && x.typeInit != "intern" && !x.gtypeStruct
+	}
+	return false
+}
+
+// storageType 和 g-ir-compiler 一样，根据枚举值的范围决定存储类型。
+func (x *xInfo) storageType() TypeTag {
+	var min, max int64
+	for _, v := range x.values {
+		if v.value < min {
+			min = v.value
+		}
+		if v.value > max {
+			max = v.value
+		}
+	}
+	if min < 0 {
+		if min < math.MinInt32 || max > math.MaxInt32 {
+			return TYPE_TAG_INT64
+		}
+		return TYPE_TAG_INT32
+	}
+	if max > math.MaxUint32 {
+		return TYPE_TAG_UINT64
+	}
+	return TYPE_TAG_UINT32
+}
+
+func (x *xInfo) constantValue() interface{} {
+	str := x.constValue
+	switch x.type0.tag {
+	case TYPE_TAG_BOOLEAN:
+		return str == "true" || str == "1"
+	case TYPE_TAG_INT8, TYPE_TAG_INT16, TYPE_TAG_INT32, TYPE_TAG_INT64:
+		v, _ := strconv.ParseInt(str, 0, 64)
+		switch x.type0.tag {
+		case TYPE_TAG_INT8:
+			return int8(v)
+		case TYPE_TAG_INT16:
+			return int16(v)
+		case TYPE_TAG_INT32:
+			return int32(v)
+		}
+		return v
+	case TYPE_TAG_UINT8, TYPE_TAG_UINT16, TYPE_TAG_UINT32, TYPE_TAG_UINT64:
+		v, _ := strconv.ParseUint(str, 0, 64)
+		switch x.type0.tag {
+		case TYPE_TAG_UINT8:
+			return uint8(v)
+		case TYPE_TAG_UINT16:
+			return uint16(v)
+		case TYPE_TAG_UINT32:
+			return uint32(v)
+		}
+		return v
+	case TYPE_TAG_FLOAT:
+		v, _ := strconv.ParseFloat(str, 32)
+		return float32(v)
+	case TYPE_TAG_DOUBLE:
+		v, _ := strconv.ParseFloat(str, 64)
+		return v
+	case TYPE_TAG_UTF8, TYPE_TAG_FILENAME:
+		return str
+	}
+	// 和 gi 包的一样，枚举和标志类型的常量不求值
+	return nil
+}
+
+// vfuncOffset 返回虚方法在类结构体中的偏移，找不到时返回 0xFFFF。
+func (x *xInfo) vfuncOffset() int {
+	const invalidOffset = 0xFFFF
+	container := x.container
+	if container == nil || container.classStruct == "" {
+		return invalidOffset
+	}
+	classStruct := resolve(container.classStruct)
+	classStruct.computeLayout()
+	for _, field := range classStruct.fields {
+		if field.name == x.name {
+			return field.offset
+		}
+	}
+	return invalidOffset
+}
+
+// xType 是从 GIR 文件中的 type 或 array 元素得到的类型
+type xType struct {
+	tag     TypeTag
+	pointer pointerKind
+	ifcName string // tag 为 TYPE_TAG_INTERFACE 时的完整类型名，如 GObject.Object
+	ifc     *xInfo // 字段中直接定义的回调
+	params  []*xType
+
+	arrayType      ArrayType
+	arrayLength    int
+	arrayFixedSize int
+	zeroTerminated bool
+}
+
+type pointerKind int
+
+const (
+	pointerNo pointerKind = iota
+	pointerYes
+	// 没有 C 类型可参考，根据所指的类型决定
+	pointerByKind
+)
+
+func (t *xType) isPointer() bool {
+	switch t.pointer {
+	case pointerYes:
+		return true
+	case pointerNo:
+		return false
+	}
+	switch t.tag {
+	case TYPE_TAG_UTF8, TYPE_TAG_FILENAME, TYPE_TAG_ARRAY, TYPE_TAG_GLIST, TYPE_TAG_GSLIST,
+		TYPE_TAG_GHASH, TYPE_TAG_ERROR:
+		return true
+	case TYPE_TAG_INTERFACE:
+		switch t.interface0().infoType {
+		case INFO_TYPE_STRUCT, INFO_TYPE_UNION, INFO_TYPE_OBJECT, INFO_TYPE_INTERFACE:
+			return true
+		}
+	}
+	return false
+}
+
+func (t *xType) interface0() *xInfo {
+	if t.ifc != nil {
+		return t.ifc
+	}
+	if t.tag != TYPE_TAG_INTERFACE {
+		return nil
+	}
+	return resolve(t.ifcName)
+}
+
+// 键是 GIR 中基本类型的名字
+var _basicTypeTags = map[string]TypeTag{
+	"none":          TYPE_TAG_VOID,
+	"gpointer":      TYPE_TAG_VOID,
+	"gconstpointer": TYPE_TAG_VOID,
+	"gboolean":      TYPE_TAG_BOOLEAN,
+	"gint8":         TYPE_TAG_INT8,
+	"gchar":         TYPE_TAG_INT8,
+	"guint8":        TYPE_TAG_UINT8,
+	"guchar":        TYPE_TAG_UINT8,
+	"gint16":        TYPE_TAG_INT16,
+	"gshort":        TYPE_TAG_INT16,
+	"guint16":       TYPE_TAG_UINT16,
+	"gushort":       TYPE_TAG_UINT16,
+	"gint32":        TYPE_TAG_INT32,
+	"gint":          TYPE_TAG_INT32,
+	"pid_t":         TYPE_TAG_INT32,
+	"guint32":       TYPE_TAG_UINT32,
+	"guint":         TYPE_TAG_UINT32,
+	"uid_t":         TYPE_TAG_UINT32,
+	"gint64":        TYPE_TAG_INT64,
+	"glong":         TYPE_TAG_INT64,
+	"gssize":        TYPE_TAG_INT64,
+	"gintptr":       TYPE_TAG_INT64,
+	"goffset":       TYPE_TAG_INT64,
+	"off_t":         TYPE_TAG_INT64,
+	"time_t":        TYPE_TAG_INT64,
+	"guint64":       TYPE_TAG_UINT64,
+	"gulong":        TYPE_TAG_UINT64,
+	"gsize":         TYPE_TAG_UINT64,
+	"guintptr":      TYPE_TAG_UINT64,
+	"size_t":        TYPE_TAG_UINT64,
+	"gfloat":        TYPE_TAG_FLOAT,
+	"gdouble":       TYPE_TAG_DOUBLE,
+	"GType":         TYPE_TAG_GTYPE,
+	"gunichar":      TYPE_TAG_UNICHAR,
+	"utf8":          TYPE_TAG_UTF8,
+	"filename":      TYPE_TAG_FILENAME,
+}
+
+// 键是完整的类型名
+var _containerTypeTags = map[string]TypeTag{
+	"GLib.List":      TYPE_TAG_GLIST,
+	"GLib.SList":     TYPE_TAG_GSLIST,
+	"GLib.HashTable": TYPE_TAG_GHASH,
+	"GLib.Error":     TYPE_TAG_ERROR,
+}
+
+// getPointerDepth 返回 C 类型中指针的层数，gpointer 算一层。
+func getPointerDepth(cType string) int {
+	depth := strings.Count(cType, "*")
+	if strings.Contains(cType, "gpointer") || strings.Contains(cType, "gconstpointer") {
+		depth++
+	}
+	return depth
+}
+
+// xBuilder 把 xmlp 解析的一个名字空间转换为 xInfo
+type xBuilder struct {
+	ns string
+}
+
+func (b *xBuilder) build(ns *xmlp.Namespace) *xNamespace {
+	var infos []*xInfo
+	for _, fn := range ns.Functions {
+		if fn.Introspectable {
+			infos = append(infos, b.newFunction(fn, nil, 0))
+		}
+	}
+	for _, cb := range ns.Callbacks {
+		if cb.Introspectable {
+			infos = append(infos, b.newCallback(cb))
+		}
+	}
+	for _, si := range ns.Structs {
+		infos = append(infos, b.newStruct(si))
+	}
+	for _, ui := range ns.Unions {
+		infos = append(infos, b.newUnion(ui))
+	}
+	for _, oi := range ns.Objects {
+		infos = append(infos, b.newObject(oi))
+	}
+	for _, ii := range ns.Interfaces {
+		infos = append(infos, b.newInterface(ii))
+	}
+	for _, ei := range ns.Enums {
+		infos = append(infos, b.newEnum(ei, INFO_TYPE_ENUM))
+	}
+	for _, ei := range ns.Bitfields {
+		infos = append(infos, b.newEnum(ei, INFO_TYPE_FLAGS))
+	}
+	for _, ci := range ns.Constants {
+		infos = append(infos, &xInfo{
+			infoType:   INFO_TYPE_CONSTANT,
+			ns:         b.ns,
+			name:       ci.NameAttr,
+			deprecated: isDeprecated(&ci.BaseInfo),
+			type0:      b.newType(ci.Type, nil, false),
+			constValue: ci.Value,
+		})
+	}
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].name < infos[j].name
+	})
+	byName := make(map[string]*xInfo, len(infos))
+	for _, xi := range infos {
+		byName[xi.name] = xi
+	}
+	return &xNamespace{infos: infos, byName: byName}
+}
+
+// fullName 返回完整的类型名，name 中没有名字空间时加上当前的名字空间。
+func (b *xBuilder) fullName(name string) string {
+	if name == "" || strings.Contains(name, ".") {
+		return name
+	}
+	return b.ns + "." + name
+}
+
+func isDeprecated(bi *xmlp.BaseInfo) bool {
+	return bi.Deprecated || bi.DeprecatedVersion != ""
+}
+
+func parseTransfer(str string) Transfer {
+	switch str {
+	case "full":
+		return TRANSFER_EVERYTHING
+	case "container":
+		return TRANSFER_CONTAINER
+	}
+	return TRANSFER_NOTHING
+}
+
+// setCallable 设置 x 的参数和返回值，GIR 中参数的序号都不包括实例参数。
+func (b *xBuilder) setCallable(x *xInfo, ret *xmlp.Parameter, params *xmlp.Parameters) {
+	if params != nil {
+		for _, param := range params.Parameters {
+			x.args = append(x.args, b.newArg(param, x))
+		}
+	}
+	if ret != nil {
+		x.type0 = b.newType(ret.Type, ret.Array, false)
+		x.transfer = parseTransfer(ret.TransferOwnership)
+		x.nullable = ret.Nullable || ret.AllowNone
+	} else {
+		x.type0 = &xType{tag: TYPE_TAG_VOID}
+	}
+}
+
+func (b *xBuilder) newArg(param *xmlp.Parameter, container *xInfo) *xInfo {
+	x := &xInfo{
+		infoType:        INFO_TYPE_ARG,
+		ns:              b.ns,
+		name:            param.Name,
+		container:       container,
+		callerAllocates: param.CallerAllocates,
+		optional:        param.Optional,
+		nullable:        param.Nullable || param.AllowNone,
+		transfer:        parseTransfer(param.TransferOwnership),
+		closure:         param.ClosureIndex,
+		destroy:         param.DestroyIndex,
+	}
+	switch param.Direction {
+	case "out":
+		x.direction = DIRECTION_OUT
+	case "inout":
+		x.direction = DIRECTION_INOUT
+	default:
+		x.direction = DIRECTION_IN
+	}
+	switch param.Scope {
+	case "call":
+		x.scope = SCOPE_TYPE_CALL
+	case "async":
+		x.scope = SCOPE_TYPE_ASYNC
+	case "notified":
+		x.scope = SCOPE_TYPE_NOTIFIED
+	default:
+		x.scope = SCOPE_TYPE_INVALID
+	}
+	x.type0 = b.newType(param.Type, param.Array, x.direction != DIRECTION_IN)
+	return x
+}
+
+// newType 转换 type 或 array 元素，out 表示是 out 或 inout 参数，它的 C 类型多了一层指针。
+func (b *xBuilder) newType(t *xmlp.Type, arr *xmlp.ArrayType, out bool) *xType {
+	if arr != nil {
+		return b.newArrayType(arr, out)
+	}
+	if t == nil {
+		// 比如 varargs
+		return &xType{tag: TYPE_TAG_VOID}
+	}
+
+	result := &xType{pointer: pointerByKind}
+	if t.CType != "" {
+		depth := getPointerDepth(t.CType)
+		if out {
+			depth--
+		}
+		result.pointer = pointerNo
+		if depth > 0 {
+			result.pointer = pointerYes
+		}
+	} else if t.Name == "gpointer" || t.Name == "gconstpointer" {
+		result.pointer = pointerYes
+	}
+
+	if tag, ok := _basicTypeTags[t.Name]; ok {
+		result.tag = tag
+		return result
+	}
+
+	name := b.fullName(t.Name)
+	if tag, ok := _containerTypeTags[name]; ok {
+		result.tag = tag
+		for _, elemType := range t.ElemTypes {
+			result.params = append(result.params, b.newType(elemType, nil, false))
+		}
+		return result
+	}
+
+	if alias := getAlias(name); alias != nil && alias.SourceType != nil {
+		// 别名所在的名字空间
+		ab := &xBuilder{ns: strings.SplitN(name, ".", 2)[0]}
+		aliased := ab.newType(alias.SourceType, nil, false)
+		if result.pointer != pointerByKind {
+			aliased.pointer = result.pointer
+		}
+		return aliased
+	}
+
+	result.tag = TYPE_TAG_INTERFACE
+	result.ifcName = name
+	return result
+}
+
+func (b *xBuilder) newArrayType(arr *xmlp.ArrayType, out bool) *xType {
+	result := &xType{
+		tag:            TYPE_TAG_ARRAY,
+		pointer:        pointerYes,
+		arrayType:      ARRAY_TYPE_C,
+		arrayLength:    arr.LengthIndex,
+		arrayFixedSize: -1,
+		zeroTerminated: arr.ZeroTerminated,
+	}
+	if arr.FixedSize > 0 {
+		result.arrayFixedSize = arr.FixedSize
+	}
+	switch b.fullName(arr.Name) {
+	case "GLib.Array":
+		result.arrayType = ARRAY_TYPE_ARRAY
+	case "GLib.PtrArray":
+		result.arrayType = ARRAY_TYPE_PTR_ARRAY
+	case "GLib.ByteArray":
+		result.arrayType = ARRAY_TYPE_BYTE_ARRAY
+	}
+
+	// 元素没有 C 类型时，它是否是指针由数组的 C 类型决定
+	elemPointer := pointerByKind
+	if arr.CType != "" {
+		depth := getPointerDepth(arr.CType)
+		if out {
+			depth--
+		}
+		if depth <= 0 {
+			// 结构体中的固定长度数组
+			result.pointer = pointerNo
+		}
+		elemPointer = pointerNo
+		if depth > 1 {
+			elemPointer = pointerYes
+		}
+	} else if result.arrayFixedSize > 0 {
+		elemPointer = pointerNo
+	}
+
+	var elem *xType
+	if arr.ElemArray != nil {
+		elem = b.newArrayType(arr.ElemArray, false)
+	} else if arr.ElemType != nil {
+		elem = b.newType(arr.ElemType, nil, false)
+		if arr.ElemType.CType == "" && elem.tag == TYPE_TAG_INTERFACE {
+			elem.pointer = elemPointer
+		}
+	} else {
+		elem = &xType{tag: TYPE_TAG_UINT8}
+	}
+	result.params = []*xType{elem}
+	return result
+}
+
+// 键是完整的类型名
+var _aliases map[string]*xmlp.AliasInfo
+
+func getAlias(fullName string) *xmlp.AliasInfo {
+	parts := strings.SplitN(fullName, ".", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	if _aliases == nil {
+		_aliases = make(map[string]*xmlp.AliasInfo)
+	}
+	if alias, ok := _aliases[fullName]; ok {
+		return alias
+	}
+	var result *xmlp.AliasInfo
+	repo := xmlp.GetLoadedRepo(parts[0])
+	if repo != nil {
+		for _, alias := range repo.Namespace.Aliases {
+			if alias.NameAttr == parts[1] {
+				result = alias
+				break
+			}
+		}
+	}
+	_aliases[fullName] = result
+	return result
+}
+
+func (b *xBuilder) newFunction(fn *xmlp.FunctionInfo, container *xInfo, flags FunctionInfoFlags) *xInfo {
+	name := fn.NameAttr
+	if fn.Shadows != "" {
+		name = fn.Shadows
+	}
+	if fn.Parameters != nil && fn.Parameters.InstanceParameter != nil {
+		flags |= FUNCTION_IS_METHOD
+	}
+	if fn.Throws {
+		flags |= FUNCTION_THROWS
+	}
+	x := &xInfo{
+		infoType:   INFO_TYPE_FUNCTION,
+		ns:         b.ns,
+		name:       name,
+		deprecated: isDeprecated(&fn.BaseInfo),
+		container:  container,
+		symbol:     fn.CIdentifier,
+		funcFlags:  flags,
+	}
+	b.setCallable(x, fn.ReturnValue, fn.Parameters)
+	return x
+}
+
+func (b *xBuilder) newCallback(cb *xmlp.CallbackInfo) *xInfo {
+	x := &xInfo{
+		infoType:   INFO_TYPE_CALLBACK,
+		ns:         b.ns,
+		name:       cb.NameAttr,
+		deprecated: isDeprecated(&cb.BaseInfo),
+	}
+	b.setCallable(x, cb.ReturnValue, cb.Parameters)
+	return x
+}
+
+// addMethods 添加构造器，函数和方法，忽略不能内省的。
+func (b *xBuilder) addMethods(x *xInfo, constructors, functions, methods []*xmlp.FunctionInfo) {
+	for _, fn := range constructors {
+		if fn.Introspectable {
+			x.methods = append(x.methods, b.newFunction(fn, x, FUNCTION_IS_CONSTRUCTOR))
+		}
+	}
+	for _, list := range [][]*xmlp.FunctionInfo{functions, methods} {
+		for _, fn := range list {
+			if fn.Introspectable {
+				x.methods = append(x.methods, b.newFunction(fn, x, 0))
+			}
+		}
+	}
+}
+
+func (b *xBuilder) addFields(x *xInfo, fields []*xmlp.Field) {
+	for _, field := range fields {
+		f := &xInfo{
+			infoType:  INFO_TYPE_FIELD,
+			ns:        b.ns,
+			name:      field.Name,
+			container: x,
+			bits:      field.Bits,
+		}
+		if field.Readable {
+			f.fieldFlags |= FIELD_IS_READABLE
+		}
+		if field.Writable {
+			f.fieldFlags |= FIELD_IS_WRITABLE
+		}
+		if field.Callback != nil {
+			cb := b.newCallback(field.Callback)
+			cb.container = x
+			f.type0 = &xType{tag: TYPE_TAG_INTERFACE, ifc: cb}
+		} else {
+			f.type0 = b.newType(field.Type, field.Array, false)
+		}
+		x.fields = append(x.fields, f)
+	}
+}
+
+func (b *xBuilder) addProperties(x *xInfo, properties []*xmlp.Property) {
+	for _, prop := range properties {
+		if !prop.Introspectable {
+			continue
+		}
+		p := &xInfo{
+			infoType:   INFO_TYPE_PROPERTY,
+			ns:         b.ns,
+			name:       prop.Name,
+			deprecated: prop.Deprecated || prop.DeprecatedVersion != "",
+			container:  x,
+			transfer:   parseTransfer(prop.TransferOwnership),
+			type0:      b.newType(prop.Type, prop.Array, false),
+		}
+		if prop.Readable {
+			p.paramFlags |= PARAM_READABLE
+		}
+		if prop.Writable {
+			p.paramFlags |= PARAM_WRITABLE
+		}
+		if prop.Construct {
+			p.paramFlags |= PARAM_CONSTRUCT
+		}
+		if prop.ConstructOnly {
+			p.paramFlags |= PARAM_CONSTRUCT_ONLY
+		}
+		x.properties = append(x.properties, p)
+	}
+}
+
+func (b *xBuilder) addSignals(x *xInfo, signals []*xmlp.SignalInfo) {
+	for _, sig := range signals {
+		if !sig.Introspectable {
+			continue
+		}
+		s := &xInfo{
+			infoType:   INFO_TYPE_SIGNAL,
+			ns:         b.ns,
+			name:       sig.NameAttr,
+			deprecated: isDeprecated(&sig.BaseInfo),
+			container:  x,
+		}
+		b.setCallable(s, sig.ReturnValue, sig.Parameters)
+		x.signals = append(x.signals, s)
+	}
+}
+
+func (b *xBuilder) addVFuncs(x *xInfo, vfuncs []*xmlp.VFuncInfo) {
+	for _, vfunc := range vfuncs {
+		if !vfunc.Introspectable {
+			continue
+		}
+		v := &xInfo{
+			infoType:   INFO_TYPE_VFUNC,
+			ns:         b.ns,
+			name:       vfunc.NameAttr,
+			deprecated: isDeprecated(&vfunc.BaseInfo),
+			container:  x,
+			invoker:    vfunc.Invoker,
+		}
+		if vfunc.Throws {
+			v.vfuncFlags |= VFUNC_THROWS
+		}
+		b.setCallable(v, vfunc.ReturnValue, vfunc.Parameters)
+		x.vfuncs = append(x.vfuncs, v)
+	}
+}
+
+func (b *xBuilder) newRegisteredType(infoType InfoType, rt *xmlp.RegisteredTypeInfo) *xInfo {
+	return &xInfo{
+		infoType:   infoType,
+		ns:         b.ns,
+		name:       rt.NameAttr,
+		deprecated: isDeprecated(&rt.BaseInfo),
+		typeName:   rt.GlibTypeName,
+		typeInit:   rt.GlibGetType,
+	}
+}
+
+func (b *xBuilder) newStruct(si *xmlp.StructInfo) *xInfo {
+	x := b.newRegisteredType(INFO_TYPE_STRUCT, &si.RegisteredTypeInfo)
+	x.gtypeStruct = si.GlibIsGtypeStructFor != ""
+	x.foreign = si.Foreign
+	b.addFields(x, si.Fields)
+	b.addMethods(x, si.Constructors, si.Functions, si.Methods)
+	return x
+}
+
+func (b *xBuilder) newUnion(ui *xmlp.UnionInfo) *xInfo {
+	x := b.newRegisteredType(INFO_TYPE_UNION, &ui.RegisteredTypeInfo)
+	b.addFields(x, ui.Fields)
+	b.addMethods(x, ui.Constructors, ui.Functions, ui.Methods)
+	return x
+}
+
+func (b *xBuilder) newObject(oi *xmlp.ObjectInfo) *xInfo {
+	x := b.newRegisteredType(INFO_TYPE_OBJECT, &oi.RegisteredTypeInfo)
+	x.parent = b.fullName(oi.Parent)
+	x.classStruct = b.fullName(oi.GlibTypeStruct)
+	x.abstract = oi.Abstract
+	x.fundamental = oi.GlibFundamental
+	x.refFunc = oi.GlibRefFunc
+	x.unrefFunc = oi.GlibUnrefFunc
+	for _, ifc := range oi.Implements {
+		x.interfaces = append(x.interfaces, b.fullName(ifc.Name))
+	}
+	b.addFields(x, oi.Fields)
+	b.addProperties(x, oi.Properties)
+	b.addMethods(x, oi.Constructors, oi.Functions, oi.Methods)
+	b.addSignals(x, oi.Signals)
+	b.addVFuncs(x, oi.VirtualMethods)
+	return x
+}
+
+func (b *xBuilder) newInterface(ii *xmlp.InterfaceInfo) *xInfo {
+	x := b.newRegisteredType(INFO_TYPE_INTERFACE, &ii.RegisteredTypeInfo)
+	x.classStruct = b.fullName(ii.GlibTypeStruct)
+	for _, prereq := range ii.Prerequisites {
+		x.interfaces = append(x.interfaces, b.fullName(prereq.Name))
+	}
+	b.addProperties(x, ii.Properties)
+	b.addMethods(x, nil, ii.Functions, ii.Methods)
+	b.addSignals(x, ii.Signals)
+	b.addVFuncs(x, ii.VirtualMethods)
+	return x
+}
+
+func (b *xBuilder) newEnum(ei *xmlp.EnumInfo, infoType InfoType) *xInfo {
+	x := b.newRegisteredType(infoType, &ei.RegisteredTypeInfo)
+	x.errorDomain = ei.GlibErrorDomain
+	for _, member := range ei.Members {
+		v, err := strconv.ParseInt(member.Value, 0, 64)
+		if err != nil {
+			// 超出 int64 范围的标志
+			u, _ := strconv.ParseUint(member.Value, 0, 64)
+			v = int64(u)
+		}
+		x.values = append(x.values, &xInfo{
+			infoType:  INFO_TYPE_VALUE,
+			ns:        b.ns,
+			name:      member.Name,
+			container: x,
+			value:     v,
+		})
+	}
+	b.addMethods(x, nil, ei.Functions, nil)
+	return x
+}
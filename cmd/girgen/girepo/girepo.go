@@ -0,0 +1,265 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package girepo 提供 girgen 需要的类型信息，信息可以来自已安装的 typelib 文件（通过 typelib 包），
+// 也可以只来自 GIR 文件（通过 xmlp 包），后者不需要安装 typelib 文件。
+package girepo
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/electricface/go-gir3/cmd/girgen/girepo/typelib"
+	"github.com/electricface/go-gir3/cmd/girgen/xmlp"
+	"github.com/electricface/go-gir3/gi/gitypes"
+)
+
+type InfoType = gitypes.InfoType
+
+const (
+	INFO_TYPE_INVALID    = gitypes.INFO_TYPE_INVALID
+	INFO_TYPE_FUNCTION   = gitypes.INFO_TYPE_FUNCTION
+	INFO_TYPE_CALLBACK   = gitypes.INFO_TYPE_CALLBACK
+	INFO_TYPE_STRUCT     = gitypes.INFO_TYPE_STRUCT
+	INFO_TYPE_BOXED      = gitypes.INFO_TYPE_BOXED
+	INFO_TYPE_ENUM       = gitypes.INFO_TYPE_ENUM
+	INFO_TYPE_FLAGS      = gitypes.INFO_TYPE_FLAGS
+	INFO_TYPE_OBJECT     = gitypes.INFO_TYPE_OBJECT
+	INFO_TYPE_INTERFACE  = gitypes.INFO_TYPE_INTERFACE
+	INFO_TYPE_CONSTANT   = gitypes.INFO_TYPE_CONSTANT
+	INFO_TYPE_UNION      = gitypes.INFO_TYPE_UNION
+	INFO_TYPE_VALUE      = gitypes.INFO_TYPE_VALUE
+	INFO_TYPE_SIGNAL     = gitypes.INFO_TYPE_SIGNAL
+	INFO_TYPE_VFUNC      = gitypes.INFO_TYPE_VFUNC
+	INFO_TYPE_PROPERTY   = gitypes.INFO_TYPE_PROPERTY
+	INFO_TYPE_FIELD      = gitypes.INFO_TYPE_FIELD
+	INFO_TYPE_ARG        = gitypes.INFO_TYPE_ARG
+	INFO_TYPE_TYPE       = gitypes.INFO_TYPE_TYPE
+	INFO_TYPE_UNRESOLVED = gitypes.INFO_TYPE_UNRESOLVED
+)
+
+type RepositoryLoadFlags = gitypes.RepositoryLoadFlags
+
+const REPOSITORY_LOAD_FLAG_LAZY = gitypes.REPOSITORY_LOAD_FLAG_LAZY
+
+type Direction = gitypes.Direction
+
+const (
+	DIRECTION_IN    = gitypes.DIRECTION_IN
+	DIRECTION_OUT   = gitypes.DIRECTION_OUT
+	DIRECTION_INOUT = gitypes.DIRECTION_INOUT
+)
+
+type ScopeType = gitypes.ScopeType
+
+const (
+	SCOPE_TYPE_INVALID  = gitypes.SCOPE_TYPE_INVALID
+	SCOPE_TYPE_CALL     = gitypes.SCOPE_TYPE_CALL
+	SCOPE_TYPE_ASYNC    = gitypes.SCOPE_TYPE_ASYNC
+	SCOPE_TYPE_NOTIFIED = gitypes.SCOPE_TYPE_NOTIFIED
+)
+
+type Transfer = gitypes.Transfer
+
+const (
+	TRANSFER_NOTHING    = gitypes.TRANSFER_NOTHING
+	TRANSFER_CONTAINER  = gitypes.TRANSFER_CONTAINER
+	TRANSFER_EVERYTHING = gitypes.TRANSFER_EVERYTHING
+)
+
+type FieldInfoFlags = gitypes.FieldInfoFlags
+
+const (
+	FIELD_IS_READABLE = gitypes.FIELD_IS_READABLE
+	FIELD_IS_WRITABLE = gitypes.FIELD_IS_WRITABLE
+)
+
+type ParamFlags = gitypes.ParamFlags
+
+const (
+	PARAM_READABLE       = gitypes.PARAM_READABLE
+	PARAM_WRITABLE       = gitypes.PARAM_WRITABLE
+	PARAM_CONSTRUCT      = gitypes.PARAM_CONSTRUCT
+	PARAM_CONSTRUCT_ONLY = gitypes.PARAM_CONSTRUCT_ONLY
+	PARAM_DEPRECATED     = gitypes.PARAM_DEPRECATED
+)
+
+type ArrayType = gitypes.ArrayType
+
+const (
+	ARRAY_TYPE_C          = gitypes.ARRAY_TYPE_C
+	ARRAY_TYPE_ARRAY      = gitypes.ARRAY_TYPE_ARRAY
+	ARRAY_TYPE_PTR_ARRAY  = gitypes.ARRAY_TYPE_PTR_ARRAY
+	ARRAY_TYPE_BYTE_ARRAY = gitypes.ARRAY_TYPE_BYTE_ARRAY
+)
+
+type TypeTag = gitypes.TypeTag
+
+const (
+	TYPE_TAG_VOID      = gitypes.TYPE_TAG_VOID
+	TYPE_TAG_BOOLEAN   = gitypes.TYPE_TAG_BOOLEAN
+	TYPE_TAG_INT8      = gitypes.TYPE_TAG_INT8
+	TYPE_TAG_UINT8     = gitypes.TYPE_TAG_UINT8
+	TYPE_TAG_INT16     = gitypes.TYPE_TAG_INT16
+	TYPE_TAG_UINT16    = gitypes.TYPE_TAG_UINT16
+	TYPE_TAG_INT32     = gitypes.TYPE_TAG_INT32
+	TYPE_TAG_UINT32    = gitypes.TYPE_TAG_UINT32
+	TYPE_TAG_INT64     = gitypes.TYPE_TAG_INT64
+	TYPE_TAG_UINT64    = gitypes.TYPE_TAG_UINT64
+	TYPE_TAG_FLOAT     = gitypes.TYPE_TAG_FLOAT
+	TYPE_TAG_DOUBLE    = gitypes.TYPE_TAG_DOUBLE
+	TYPE_TAG_GTYPE     = gitypes.TYPE_TAG_GTYPE
+	TYPE_TAG_UTF8      = gitypes.TYPE_TAG_UTF8
+	TYPE_TAG_FILENAME  = gitypes.TYPE_TAG_FILENAME
+	TYPE_TAG_ARRAY     = gitypes.TYPE_TAG_ARRAY
+	TYPE_TAG_INTERFACE = gitypes.TYPE_TAG_INTERFACE
+	TYPE_TAG_GLIST     = gitypes.TYPE_TAG_GLIST
+	TYPE_TAG_GSLIST    = gitypes.TYPE_TAG_GSLIST
+	TYPE_TAG_GHASH     = gitypes.TYPE_TAG_GHASH
+	TYPE_TAG_ERROR     = gitypes.TYPE_TAG_ERROR
+	TYPE_TAG_UNICHAR   = gitypes.TYPE_TAG_UNICHAR
+)
+
+type FunctionInfoFlags = gitypes.FunctionInfoFlags
+
+const (
+	FUNCTION_IS_METHOD      = gitypes.FUNCTION_IS_METHOD
+	FUNCTION_IS_CONSTRUCTOR = gitypes.FUNCTION_IS_CONSTRUCTOR
+	FUNCTION_IS_GETTER      = gitypes.FUNCTION_IS_GETTER
+	FUNCTION_IS_SETTER      = gitypes.FUNCTION_IS_SETTER
+	FUNCTION_WRAPS_VFUNC    = gitypes.FUNCTION_WRAPS_VFUNC
+	FUNCTION_THROWS         = gitypes.FUNCTION_THROWS
+)
+
+type VFuncInfoFlags = gitypes.VFuncInfoFlags
+
+const (
+	VFUNC_MUST_CHAIN_UP     = gitypes.VFUNC_MUST_CHAIN_UP
+	VFUNC_MUST_OVERRIDE     = gitypes.VFUNC_MUST_OVERRIDE
+	VFUNC_MUST_NOT_OVERRIDE = gitypes.VFUNC_MUST_NOT_OVERRIDE
+	VFUNC_THROWS            = gitypes.VFUNC_THROWS
+)
+
+// GType 表示注册的类型，只能比较是否相同和判断是否是 boxed 类型。
+type GType struct {
+	name  string
+	boxed bool
+}
+
+func (t GType) IsBoxed() bool {
+	return t.boxed
+}
+
+//------------------------------------------------------------------------------
+// Repository
+//------------------------------------------------------------------------------
+
+// Repository 的 t 和 x 只有一个不为 nil，t 表示使用 typelib 文件，x 表示只使用 GIR 文件。
+type Repository struct {
+	t *typelib.Repository
+	x *xRepository
+}
+
+// 使用 gironly 构建标签时不能读取 typelib 文件，总是只使用 GIR 文件。
+var _girOnly = !typelib.Available
+
+// SetGirOnly 设置之后 DefaultRepository 返回只使用 GIR 文件的 Repository，
+// 需要在调用 DefaultRepository 之前设置。
+func SetGirOnly() {
+	_girOnly = true
+}
+
+var _xDefaultRepo = &xRepository{
+	namespaces: make(map[string]*xNamespace),
+}
+
+// GirOnly 返回 DefaultRepository 是否只使用 GIR 文件
+func GirOnly() bool {
+	return _girOnly
+}
+
+func DefaultRepository() *Repository {
+	if _girOnly {
+		return &Repository{x: _xDefaultRepo}
+	}
+	return &Repository{t: typelib.DefaultRepository()}
+}
+
+func (r *Repository) Require(namespace, version string, flags RepositoryLoadFlags) error {
+	if r.x != nil {
+		if version == "" {
+			return errors.New("version of namespace " + namespace + " is required")
+		}
+		_, err := xmlp.Load(namespace, version)
+		return err
+	}
+	_, err := r.t.Require(namespace, version, flags)
+	return err
+}
+
+func (r *Repository) FindByName(namespace, name string) *BaseInfo {
+	if r.x != nil {
+		// 和 typelib 的一样，找不到时返回的 BaseInfo 的 IsNil() 为 true
+		return &BaseInfo{x: r.x.findByName(namespace, name)}
+	}
+	return &BaseInfo{t: r.t.FindByName(namespace, name)}
+}
+
+// ImmediateDependencies 返回直接依赖的名字空间，每项如 GObject-2.0。
+func (r *Repository) ImmediateDependencies(namespace string) []string {
+	if r.x != nil {
+		repo := xmlp.GetLoadedRepo(namespace)
+		if repo == nil {
+			return nil
+		}
+		var deps []string
+		for _, inc := range repo.CoreIncludes() {
+			deps = append(deps, inc.Name+"-"+inc.Version)
+		}
+		return deps
+	}
+	return r.t.ImmediateDependencies(namespace)
+}
+
+func (r *Repository) NumInfo(namespace string) int {
+	if r.x != nil {
+		return len(r.x.namespace(namespace).infos)
+	}
+	return r.t.NumInfo(namespace)
+}
+
+func (r *Repository) Info(namespace string, index int) *BaseInfo {
+	if r.x != nil {
+		return &BaseInfo{x: r.x.namespace(namespace).infos[index]}
+	}
+	return &BaseInfo{t: r.t.Info(namespace, index)}
+}
+
+func (r *Repository) CPrefix(namespace string) string {
+	if r.x != nil {
+		repo := xmlp.GetLoadedRepo(namespace)
+		if repo == nil {
+			return ""
+		}
+		return strings.Split(repo.Namespace.CIdentifierPrefixes, ",")[0]
+	}
+	return r.t.CPrefix(namespace)
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package girepo
+
+import (
+	"testing"
+
+	"github.com/electricface/go-gir3/cmd/girgen/xmlp"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadFix(t *testing.T) *Repository {
+	xmlp.PrependSearchPath("testdata")
+	SetGirOnly()
+	repo := DefaultRepository()
+	err := repo.Require("Fix", "1.0", REPOSITORY_LOAD_FLAG_LAZY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestGirOnlyObject(t *testing.T) {
+	repo := loadFix(t)
+	assert.True(t, repo.FindByName("Fix", "NotExist").IsNil())
+	assert.Equal(t, "Fix", repo.CPrefix("Fix"))
+
+	oi := ToObjectInfo(repo.FindByName("Fix", "Thing"))
+	assert.Equal(t, "Base", oi.Parent().Name())
+	assert.Equal(t, "Runner", oi.Interface(0).Name())
+	assert.Equal(t, "ThingClass", oi.ClassStruct().Name())
+	// 不能内省的 varargs 方法被忽略
+	assert.Nil(t, oi.FindMethod("varargs"))
+
+	ctor := oi.FindMethod("new")
+	assert.Equal(t, FUNCTION_IS_CONSTRUCTOR|FUNCTION_THROWS, ctor.Flags())
+
+	fi := oi.FindMethod("get_points")
+	assert.Equal(t, "fix_thing_get_points", fi.Symbol())
+	assert.Equal(t, FUNCTION_IS_METHOD, fi.Flags())
+	assert.Equal(t, TRANSFER_CONTAINER, fi.CallerOwns())
+	retType := fi.ReturnType()
+	assert.Equal(t, TYPE_TAG_ARRAY, retType.Tag())
+	assert.Equal(t, 0, retType.ArrayLength())
+	assert.False(t, retType.IsZeroTerminated())
+	// FixPoint* 中的元素是结构体本身
+	assert.False(t, retType.ParamType(0).IsPointer())
+	arg := fi.Arg(0)
+	assert.Equal(t, DIRECTION_OUT, arg.Direction())
+	assert.Equal(t, TYPE_TAG_UINT64, arg.Type().Tag())
+	assert.False(t, arg.Type().IsPointer())
+
+	fi = oi.FindMethod("get_point")
+	assert.True(t, fi.Arg(0).IsCallerAllocates())
+	assert.False(t, fi.Arg(0).Type().IsPointer())
+
+	// 别名 Id 解析为 guint
+	fi = oi.FindMethod("lookup")
+	assert.Equal(t, TYPE_TAG_UINT32, fi.Arg(0).Type().Tag())
+	assert.Equal(t, TYPE_TAG_GHASH, fi.ReturnType().Tag())
+	assert.True(t, fi.MayReturnNil())
+
+	prop := oi.Property(1)
+	assert.Equal(t, "label", prop.Name())
+	assert.Equal(t, PARAM_WRITABLE, prop.Flags())
+	assert.True(t, prop.Type().IsPointer())
+
+	sig := oi.Signal(0)
+	assert.Equal(t, "changed", sig.Name())
+	assert.True(t, sig.Arg(0).Type().IsPointer())
+
+	vfunc := oi.VFunc(0)
+	assert.Equal(t, 8, vfunc.Offset())
+	assert.Equal(t, "emit_changed", vfunc.Invoker().Name())
+}
+
+func TestGirOnlyLayout(t *testing.T) {
+	repo := loadFix(t)
+
+	si := ToStructInfo(repo.FindByName("Fix", "Point"))
+	assert.True(t, si.GetGType().IsBoxed())
+	assert.Equal(t, 24, si.Size())
+	var offsets []int
+	for i := 0; i < si.NumField(); i++ {
+		offsets = append(offsets, si.Field(i).Offset())
+	}
+	assert.Equal(t, []int{0, 8, 16, 16, 17}, offsets)
+	assert.Equal(t, 2, si.Field(3).Size())
+
+	cs := ToStructInfo(repo.FindByName("Fix", "ThingClass"))
+	assert.True(t, cs.IsGTypeStruct())
+	assert.False(t, cs.GetGType().IsBoxed())
+	assert.Equal(t, 16, cs.Size())
+}
+
+func TestGirOnlyEnum(t *testing.T) {
+	repo := loadFix(t)
+
+	ei := ToEnumInfo(repo.FindByName("Fix", "Color"))
+	assert.Equal(t, INFO_TYPE_ENUM, ei.Type())
+	assert.Equal(t, TYPE_TAG_INT32, ei.StorageType())
+	assert.Equal(t, int64(-1), ei.Value(2).Value())
+
+	ei = ToEnumInfo(repo.FindByName("Fix", "Flags"))
+	assert.Equal(t, INFO_TYPE_FLAGS, ei.Type())
+	assert.Equal(t, TYPE_TAG_UINT32, ei.StorageType())
+
+	ei = ToEnumInfo(repo.FindByName("Fix", "Error"))
+	assert.Equal(t, "fix-error-quark", ei.ErrorDomain())
+	assert.Equal(t, "fix_error_quark", ei.Method(0).Symbol())
+
+	ci := ToConstantInfo(repo.FindByName("Fix", "MAX_SIZE"))
+	assert.Equal(t, int32(64), ci.Value())
+}
+
+func TestCheckLayoutTarget(t *testing.T) {
+	assert.NoError(t, CheckLayoutTarget("linux", "amd64"))
+	assert.NoError(t, CheckLayoutTarget("linux", "arm64"))
+	assert.Error(t, CheckLayoutTarget("linux", "386"))
+	assert.Error(t, CheckLayoutTarget("linux", "arm"))
+	assert.Error(t, CheckLayoutTarget("windows", "amd64"))
+}
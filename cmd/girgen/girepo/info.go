@@ -0,0 +1,1051 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package girepo
+
+import (
+	"fmt"
+
+	"github.com/electricface/go-gir3/cmd/girgen/girepo/typelib"
+)
+
+//------------------------------------------------------------------------------
+// BaseInfo
+//------------------------------------------------------------------------------
+
+// BaseInfo 的 t 和 x 最多只有一个不为 nil，t 来自 typelib 文件，x 来自 GIR 文件。
+type BaseInfo struct {
+	t *typelib.BaseInfo
+	x *xInfo
+}
+
+type BaseInfoLike interface {
+	inheritedFromBaseInfo() *BaseInfo
+}
+
+func (bi *BaseInfo) inheritedFromBaseInfo() *BaseInfo {
+	return bi
+}
+
+// tBaseInfo 把 gi 包的各种 info 转换为 *BaseInfo，info 为 nil 时返回 nil。
+func tBaseInfo(bil typelib.BaseInfoLike, isNil bool) *BaseInfo {
+	if isNil {
+		return nil
+	}
+	return &BaseInfo{t: typelib.ToBaseInfo(bil)}
+}
+
+func expectType(bi *BaseInfo, types ...InfoType) {
+	t := bi.Type()
+	for _, t0 := range types {
+		if t == t0 {
+			return
+		}
+	}
+	panic(fmt.Sprintf("expected info type %v, but got %v", types, t))
+}
+
+func ToBaseInfo(bil BaseInfoLike) *BaseInfo {
+	return bil.inheritedFromBaseInfo()
+}
+
+func ToArgInfo(bil BaseInfoLike) *ArgInfo {
+	bi := bil.inheritedFromBaseInfo()
+	expectType(bi, INFO_TYPE_ARG)
+	return &ArgInfo{*bi}
+}
+
+func ToConstantInfo(bil BaseInfoLike) *ConstantInfo {
+	bi := bil.inheritedFromBaseInfo()
+	expectType(bi, INFO_TYPE_CONSTANT)
+	return &ConstantInfo{*bi}
+}
+
+func ToFieldInfo(bil BaseInfoLike) *FieldInfo {
+	bi := bil.inheritedFromBaseInfo()
+	expectType(bi, INFO_TYPE_FIELD)
+	return &FieldInfo{*bi}
+}
+
+func ToPropertyInfo(bil BaseInfoLike) *PropertyInfo {
+	bi := bil.inheritedFromBaseInfo()
+	expectType(bi, INFO_TYPE_PROPERTY)
+	return &PropertyInfo{*bi}
+}
+
+func ToTypeInfo(bil BaseInfoLike) *TypeInfo {
+	bi := bil.inheritedFromBaseInfo()
+	expectType(bi, INFO_TYPE_TYPE)
+	return &TypeInfo{*bi}
+}
+
+func ToCallableInfo(bil BaseInfoLike) *CallableInfo {
+	bi := bil.inheritedFromBaseInfo()
+	expectType(bi, INFO_TYPE_FUNCTION, INFO_TYPE_CALLBACK, INFO_TYPE_SIGNAL, INFO_TYPE_VFUNC)
+	return &CallableInfo{*bi}
+}
+
+func ToFunctionInfo(bil BaseInfoLike) *FunctionInfo {
+	bi := bil.inheritedFromBaseInfo()
+	expectType(bi, INFO_TYPE_FUNCTION)
+	return &FunctionInfo{CallableInfo{*bi}}
+}
+
+func ToSignalInfo(bil BaseInfoLike) *SignalInfo {
+	bi := bil.inheritedFromBaseInfo()
+	expectType(bi, INFO_TYPE_SIGNAL)
+	return &SignalInfo{CallableInfo{*bi}}
+}
+
+func ToVFuncInfo(bil BaseInfoLike) *VFuncInfo {
+	bi := bil.inheritedFromBaseInfo()
+	expectType(bi, INFO_TYPE_VFUNC)
+	return &VFuncInfo{CallableInfo{*bi}}
+}
+
+func ToRegisteredTypeInfo(bil BaseInfoLike) *RegisteredTypeInfo {
+	bi := bil.inheritedFromBaseInfo()
+	expectType(bi, INFO_TYPE_BOXED, INFO_TYPE_ENUM, INFO_TYPE_FLAGS, INFO_TYPE_INTERFACE,
+		INFO_TYPE_OBJECT, INFO_TYPE_STRUCT, INFO_TYPE_UNION)
+	return &RegisteredTypeInfo{*bi}
+}
+
+func ToEnumInfo(bil BaseInfoLike) *EnumInfo {
+	bi := bil.inheritedFromBaseInfo()
+	expectType(bi, INFO_TYPE_ENUM, INFO_TYPE_FLAGS)
+	return &EnumInfo{RegisteredTypeInfo{*bi}}
+}
+
+func ToInterfaceInfo(bil BaseInfoLike) *InterfaceInfo {
+	bi := bil.inheritedFromBaseInfo()
+	expectType(bi, INFO_TYPE_INTERFACE)
+	return &InterfaceInfo{RegisteredTypeInfo{*bi}}
+}
+
+func ToObjectInfo(bil BaseInfoLike) *ObjectInfo {
+	bi := bil.inheritedFromBaseInfo()
+	expectType(bi, INFO_TYPE_OBJECT)
+	return &ObjectInfo{RegisteredTypeInfo{*bi}}
+}
+
+func ToStructInfo(bil BaseInfoLike) *StructInfo {
+	bi := bil.inheritedFromBaseInfo()
+	expectType(bi, INFO_TYPE_STRUCT, INFO_TYPE_BOXED)
+	return &StructInfo{RegisteredTypeInfo{*bi}}
+}
+
+func ToUnionInfo(bil BaseInfoLike) *UnionInfo {
+	bi := bil.inheritedFromBaseInfo()
+	expectType(bi, INFO_TYPE_UNION)
+	return &UnionInfo{RegisteredTypeInfo{*bi}}
+}
+
+func (bi *BaseInfo) IsNil() bool {
+	if bi.x != nil {
+		return false
+	}
+	return bi.t == nil || bi.t.IsNil()
+}
+
+func (bi *BaseInfo) Unref() {
+	if bi.t != nil {
+		bi.t.Unref()
+	}
+}
+
+func (bi *BaseInfo) Type() InfoType {
+	if bi.x != nil {
+		return bi.x.infoType
+	}
+	return bi.t.Type()
+}
+
+func (bi *BaseInfo) Name() string {
+	if bi.x != nil {
+		return bi.x.name
+	}
+	return bi.t.Name()
+}
+
+func (bi *BaseInfo) Namespace() string {
+	if bi.x != nil {
+		return bi.x.ns
+	}
+	return bi.t.Namespace()
+}
+
+func (bi *BaseInfo) IsDeprecated() bool {
+	if bi.x != nil {
+		return bi.x.deprecated
+	}
+	return bi.t.IsDeprecated()
+}
+
+func (bi *BaseInfo) Container() *BaseInfo {
+	if bi.x != nil {
+		if bi.x.container == nil {
+			return nil
+		}
+		return &BaseInfo{x: bi.x.container}
+	}
+	c := bi.t.Container()
+	return tBaseInfo(c, c == nil)
+}
+
+//------------------------------------------------------------------------------
+// ArgInfo
+//------------------------------------------------------------------------------
+
+type ArgInfo struct {
+	BaseInfo
+}
+
+func (ai *ArgInfo) Direction() Direction {
+	if ai.x != nil {
+		return ai.x.direction
+	}
+	return typelib.ToArgInfo(ai.t).Direction()
+}
+
+func (ai *ArgInfo) IsCallerAllocates() bool {
+	if ai.x != nil {
+		return ai.x.callerAllocates
+	}
+	return typelib.ToArgInfo(ai.t).IsCallerAllocates()
+}
+
+func (ai *ArgInfo) IsOptional() bool {
+	if ai.x != nil {
+		return ai.x.optional
+	}
+	return typelib.ToArgInfo(ai.t).IsOptional()
+}
+
+func (ai *ArgInfo) MayBeNil() bool {
+	if ai.x != nil {
+		return ai.x.nullable
+	}
+	return typelib.ToArgInfo(ai.t).MayBeNil()
+}
+
+func (ai *ArgInfo) OwnershipTransfer() Transfer {
+	if ai.x != nil {
+		return ai.x.transfer
+	}
+	return typelib.ToArgInfo(ai.t).OwnershipTransfer()
+}
+
+func (ai *ArgInfo) Scope() ScopeType {
+	if ai.x != nil {
+		return ai.x.scope
+	}
+	return typelib.ToArgInfo(ai.t).Scope()
+}
+
+func (ai *ArgInfo) Closure() int {
+	if ai.x != nil {
+		return ai.x.closure
+	}
+	return typelib.ToArgInfo(ai.t).Closure()
+}
+
+func (ai *ArgInfo) Destroy() int {
+	if ai.x != nil {
+		return ai.x.destroy
+	}
+	return typelib.ToArgInfo(ai.t).Destroy()
+}
+
+func (ai *ArgInfo) Type() *TypeInfo {
+	if ai.x != nil {
+		return ai.x.typeInfo(ai.x.type0)
+	}
+	return &TypeInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToArgInfo(ai.t).Type())}}
+}
+
+//------------------------------------------------------------------------------
+// ConstantInfo
+//------------------------------------------------------------------------------
+
+type ConstantInfo struct {
+	BaseInfo
+}
+
+func (ci *ConstantInfo) Type() *TypeInfo {
+	if ci.x != nil {
+		return ci.x.typeInfo(ci.x.type0)
+	}
+	return &TypeInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToConstantInfo(ci.t).Type())}}
+}
+
+// Value 返回值的类型和 gi 包的 ConstantInfo.Value 相同，枚举和标志类型的常量返回 nil。
+func (ci *ConstantInfo) Value() interface{} {
+	if ci.x != nil {
+		return ci.x.constantValue()
+	}
+	return typelib.ToConstantInfo(ci.t).Value()
+}
+
+//------------------------------------------------------------------------------
+// FieldInfo
+//------------------------------------------------------------------------------
+
+type FieldInfo struct {
+	BaseInfo
+}
+
+func (fi *FieldInfo) Flags() FieldInfoFlags {
+	if fi.x != nil {
+		return fi.x.fieldFlags
+	}
+	return typelib.ToFieldInfo(fi.t).Flags()
+}
+
+// Size 返回位域的位数，不是位域时返回 0，和 gi 包的相同。
+func (fi *FieldInfo) Size() int {
+	if fi.x != nil {
+		return fi.x.bits
+	}
+	return typelib.ToFieldInfo(fi.t).Size()
+}
+
+func (fi *FieldInfo) Offset() int {
+	if fi.x != nil {
+		fi.x.container.computeLayout()
+		return fi.x.offset
+	}
+	return typelib.ToFieldInfo(fi.t).Offset()
+}
+
+func (fi *FieldInfo) Type() *TypeInfo {
+	if fi.x != nil {
+		return fi.x.typeInfo(fi.x.type0)
+	}
+	return &TypeInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToFieldInfo(fi.t).Type())}}
+}
+
+//------------------------------------------------------------------------------
+// PropertyInfo
+//------------------------------------------------------------------------------
+
+type PropertyInfo struct {
+	BaseInfo
+}
+
+func (pi *PropertyInfo) Flags() ParamFlags {
+	if pi.x != nil {
+		return pi.x.paramFlags
+	}
+	return typelib.ToPropertyInfo(pi.t).Flags()
+}
+
+func (pi *PropertyInfo) Type() *TypeInfo {
+	if pi.x != nil {
+		return pi.x.typeInfo(pi.x.type0)
+	}
+	return &TypeInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToPropertyInfo(pi.t).Type())}}
+}
+
+func (pi *PropertyInfo) OwnershipTransfer() Transfer {
+	if pi.x != nil {
+		return pi.x.transfer
+	}
+	return typelib.ToPropertyInfo(pi.t).OwnershipTransfer()
+}
+
+//------------------------------------------------------------------------------
+// TypeInfo
+//------------------------------------------------------------------------------
+
+// TypeInfo 来自 GIR 文件时，x.type0 是它表示的类型。
+type TypeInfo struct {
+	BaseInfo
+}
+
+func (ti *TypeInfo) IsPointer() bool {
+	if ti.x != nil {
+		return ti.x.type0.isPointer()
+	}
+	return typelib.ToTypeInfo(ti.t).IsPointer()
+}
+
+func (ti *TypeInfo) Tag() TypeTag {
+	if ti.x != nil {
+		return ti.x.type0.tag
+	}
+	return typelib.ToTypeInfo(ti.t).Tag()
+}
+
+func (ti *TypeInfo) ParamType(n int) *TypeInfo {
+	if ti.x != nil {
+		params := ti.x.type0.params
+		if n < 0 || n >= len(params) {
+			return nil
+		}
+		return ti.x.typeInfo(params[n])
+	}
+	return &TypeInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToTypeInfo(ti.t).ParamType(n))}}
+}
+
+func (ti *TypeInfo) Interface() *BaseInfo {
+	if ti.x != nil {
+		ifc := ti.x.type0.interface0()
+		if ifc == nil {
+			return nil
+		}
+		return &BaseInfo{x: ifc}
+	}
+	ifc := typelib.ToTypeInfo(ti.t).Interface()
+	return tBaseInfo(ifc, ifc == nil)
+}
+
+func (ti *TypeInfo) ArrayLength() int {
+	if ti.x != nil {
+		return ti.x.type0.arrayLength
+	}
+	return typelib.ToTypeInfo(ti.t).ArrayLength()
+}
+
+func (ti *TypeInfo) ArrayFixedSize() int {
+	if ti.x != nil {
+		return ti.x.type0.arrayFixedSize
+	}
+	return typelib.ToTypeInfo(ti.t).ArrayFixedSize()
+}
+
+func (ti *TypeInfo) IsZeroTerminated() bool {
+	if ti.x != nil {
+		return ti.x.type0.zeroTerminated
+	}
+	return typelib.ToTypeInfo(ti.t).IsZeroTerminated()
+}
+
+func (ti *TypeInfo) ArrayType() ArrayType {
+	if ti.x != nil {
+		return ti.x.type0.arrayType
+	}
+	return typelib.ToTypeInfo(ti.t).ArrayType()
+}
+
+//------------------------------------------------------------------------------
+// CallableInfo
+//------------------------------------------------------------------------------
+
+type CallableInfo struct {
+	BaseInfo
+}
+
+func (ci *CallableInfo) ReturnType() *TypeInfo {
+	if ci.x != nil {
+		return ci.x.typeInfo(ci.x.type0)
+	}
+	return &TypeInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToCallableInfo(ci.t).ReturnType())}}
+}
+
+func (ci *CallableInfo) CallerOwns() Transfer {
+	if ci.x != nil {
+		return ci.x.transfer
+	}
+	return typelib.ToCallableInfo(ci.t).CallerOwns()
+}
+
+func (ci *CallableInfo) MayReturnNil() bool {
+	if ci.x != nil {
+		return ci.x.nullable
+	}
+	return typelib.ToCallableInfo(ci.t).MayReturnNil()
+}
+
+func (ci *CallableInfo) NumArg() int {
+	if ci.x != nil {
+		return len(ci.x.args)
+	}
+	return typelib.ToCallableInfo(ci.t).NumArg()
+}
+
+func (ci *CallableInfo) Arg(n int) *ArgInfo {
+	if ci.x != nil {
+		return &ArgInfo{BaseInfo{x: ci.x.args[n]}}
+	}
+	return &ArgInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToCallableInfo(ci.t).Arg(n))}}
+}
+
+//------------------------------------------------------------------------------
+// FunctionInfo
+//------------------------------------------------------------------------------
+
+type FunctionInfo struct {
+	CallableInfo
+}
+
+func (fi *FunctionInfo) Symbol() string {
+	if fi.x != nil {
+		return fi.x.symbol
+	}
+	return typelib.ToFunctionInfo(fi.t).Symbol()
+}
+
+func (fi *FunctionInfo) Flags() FunctionInfoFlags {
+	if fi.x != nil {
+		return fi.x.funcFlags
+	}
+	return typelib.ToFunctionInfo(fi.t).Flags()
+}
+
+//------------------------------------------------------------------------------
+// SignalInfo
+//------------------------------------------------------------------------------
+
+type SignalInfo struct {
+	CallableInfo
+}
+
+//------------------------------------------------------------------------------
+// VFuncInfo
+//------------------------------------------------------------------------------
+
+type VFuncInfo struct {
+	CallableInfo
+}
+
+func (vfi *VFuncInfo) Flags() VFuncInfoFlags {
+	if vfi.x != nil {
+		return vfi.x.vfuncFlags
+	}
+	return typelib.ToVFuncInfo(vfi.t).Flags()
+}
+
+// Offset 返回虚方法在类结构体中的偏移，找不到时返回 0xFFFF，和 gi 包的相同。
+func (vfi *VFuncInfo) Offset() int {
+	if vfi.x != nil {
+		return vfi.x.vfuncOffset()
+	}
+	return typelib.ToVFuncInfo(vfi.t).Offset()
+}
+
+func (vfi *VFuncInfo) Invoker() *FunctionInfo {
+	if vfi.x != nil {
+		if vfi.x.invoker == "" {
+			return nil
+		}
+		invoker := vfi.x.container.findMethod(vfi.x.invoker)
+		if invoker == nil {
+			return nil
+		}
+		return &FunctionInfo{CallableInfo{BaseInfo{x: invoker}}}
+	}
+	invoker := typelib.ToVFuncInfo(vfi.t).Invoker()
+	if invoker == nil {
+		return nil
+	}
+	return &FunctionInfo{CallableInfo{BaseInfo{t: typelib.ToBaseInfo(invoker)}}}
+}
+
+//------------------------------------------------------------------------------
+// RegisteredTypeInfo
+//------------------------------------------------------------------------------
+
+type RegisteredTypeInfo struct {
+	BaseInfo
+}
+
+func (rt *RegisteredTypeInfo) TypeName() string {
+	if rt.x != nil {
+		return rt.x.typeName
+	}
+	return typelib.ToRegisteredTypeInfo(rt.t).TypeName()
+}
+
+func (rt *RegisteredTypeInfo) TypeInit() string {
+	if rt.x != nil {
+		return rt.x.typeInit
+	}
+	return typelib.ToRegisteredTypeInfo(rt.t).TypeInit()
+}
+
+func (rt *RegisteredTypeInfo) GetGType() GType {
+	if rt.x != nil {
+		return GType{name: rt.x.typeName, boxed: rt.x.isBoxed()}
+	}
+	rti := typelib.ToRegisteredTypeInfo(rt.t)
+	return GType{name: rti.TypeName(), boxed: rti.GetGType().IsBoxed()}
+}
+
+//------------------------------------------------------------------------------
+// EnumInfo
+//------------------------------------------------------------------------------
+
+type EnumInfo struct {
+	RegisteredTypeInfo
+}
+
+type ValueInfo struct {
+	BaseInfo
+	value int64
+}
+
+func (ei *EnumInfo) NumValue() int {
+	if ei.x != nil {
+		return len(ei.x.values)
+	}
+	return typelib.ToEnumInfo(ei.t).NumValue()
+}
+
+func (ei *EnumInfo) Value(n int) *ValueInfo {
+	if ei.x != nil {
+		xi := ei.x.values[n]
+		return &ValueInfo{BaseInfo: BaseInfo{x: xi}, value: xi.value}
+	}
+	vi := typelib.ToEnumInfo(ei.t).Value(n)
+	return &ValueInfo{BaseInfo: BaseInfo{t: typelib.ToBaseInfo(vi)}, value: vi.Value()}
+}
+
+func (ei *EnumInfo) NumMethod() int {
+	if ei.x != nil {
+		return len(ei.x.methods)
+	}
+	return typelib.ToEnumInfo(ei.t).NumMethod()
+}
+
+func (ei *EnumInfo) Method(n int) *FunctionInfo {
+	if ei.x != nil {
+		return &FunctionInfo{CallableInfo{BaseInfo{x: ei.x.methods[n]}}}
+	}
+	return &FunctionInfo{CallableInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToEnumInfo(ei.t).Method(n))}}}
+}
+
+func (ei *EnumInfo) StorageType() TypeTag {
+	if ei.x != nil {
+		return ei.x.storageType()
+	}
+	return typelib.ToEnumInfo(ei.t).StorageType()
+}
+
+func (ei *EnumInfo) ErrorDomain() string {
+	if ei.x != nil {
+		return ei.x.errorDomain
+	}
+	return typelib.ToEnumInfo(ei.t).ErrorDomain()
+}
+
+func (vi *ValueInfo) Value() int64 {
+	return vi.value
+}
+
+//------------------------------------------------------------------------------
+// InterfaceInfo
+//------------------------------------------------------------------------------
+
+type InterfaceInfo struct {
+	RegisteredTypeInfo
+}
+
+func (ii *InterfaceInfo) NumPrerequisite() int {
+	if ii.x != nil {
+		return len(ii.x.interfaces)
+	}
+	return typelib.ToInterfaceInfo(ii.t).NumPrerequisite()
+}
+
+func (ii *InterfaceInfo) Prerequisite(n int) *BaseInfo {
+	if ii.x != nil {
+		return &BaseInfo{x: resolve(ii.x.interfaces[n])}
+	}
+	return &BaseInfo{t: typelib.ToInterfaceInfo(ii.t).Prerequisite(n)}
+}
+
+func (ii *InterfaceInfo) NumProperty() int {
+	if ii.x != nil {
+		return len(ii.x.properties)
+	}
+	return typelib.ToInterfaceInfo(ii.t).NumProperty()
+}
+
+func (ii *InterfaceInfo) Property(n int) *PropertyInfo {
+	if ii.x != nil {
+		return &PropertyInfo{BaseInfo{x: ii.x.properties[n]}}
+	}
+	return &PropertyInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToInterfaceInfo(ii.t).Property(n))}}
+}
+
+func (ii *InterfaceInfo) NumMethod() int {
+	if ii.x != nil {
+		return len(ii.x.methods)
+	}
+	return typelib.ToInterfaceInfo(ii.t).NumMethod()
+}
+
+func (ii *InterfaceInfo) Method(n int) *FunctionInfo {
+	if ii.x != nil {
+		return &FunctionInfo{CallableInfo{BaseInfo{x: ii.x.methods[n]}}}
+	}
+	return &FunctionInfo{CallableInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToInterfaceInfo(ii.t).Method(n))}}}
+}
+
+func (ii *InterfaceInfo) FindMethod(name string) *FunctionInfo {
+	if ii.x != nil {
+		return xFunctionInfo(ii.x.findMethod(name))
+	}
+	fi := typelib.ToInterfaceInfo(ii.t).FindMethod(name)
+	if fi == nil {
+		return nil
+	}
+	return &FunctionInfo{CallableInfo{BaseInfo{t: typelib.ToBaseInfo(fi)}}}
+}
+
+func (ii *InterfaceInfo) NumSignal() int {
+	if ii.x != nil {
+		return len(ii.x.signals)
+	}
+	return typelib.ToInterfaceInfo(ii.t).NumSignal()
+}
+
+func (ii *InterfaceInfo) Signal(n int) *SignalInfo {
+	if ii.x != nil {
+		return &SignalInfo{CallableInfo{BaseInfo{x: ii.x.signals[n]}}}
+	}
+	return &SignalInfo{CallableInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToInterfaceInfo(ii.t).Signal(n))}}}
+}
+
+func (ii *InterfaceInfo) NumVFunc() int {
+	if ii.x != nil {
+		return len(ii.x.vfuncs)
+	}
+	return typelib.ToInterfaceInfo(ii.t).NumVFunc()
+}
+
+func (ii *InterfaceInfo) VFunc(n int) *VFuncInfo {
+	if ii.x != nil {
+		return &VFuncInfo{CallableInfo{BaseInfo{x: ii.x.vfuncs[n]}}}
+	}
+	return &VFuncInfo{CallableInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToInterfaceInfo(ii.t).VFunc(n))}}}
+}
+
+//------------------------------------------------------------------------------
+// ObjectInfo
+//------------------------------------------------------------------------------
+
+type ObjectInfo struct {
+	RegisteredTypeInfo
+}
+
+func (oi *ObjectInfo) Abstract() bool {
+	if oi.x != nil {
+		return oi.x.abstract
+	}
+	return typelib.ToObjectInfo(oi.t).Abstract()
+}
+
+func (oi *ObjectInfo) Fundamental() bool {
+	if oi.x != nil {
+		return oi.x.fundamental
+	}
+	return typelib.ToObjectInfo(oi.t).Fundamental()
+}
+
+func (oi *ObjectInfo) Parent() *ObjectInfo {
+	if oi.x != nil {
+		if oi.x.parent == "" {
+			return nil
+		}
+		parent := resolve(oi.x.parent)
+		if parent.infoType != INFO_TYPE_OBJECT {
+			return nil
+		}
+		return &ObjectInfo{RegisteredTypeInfo{BaseInfo{x: parent}}}
+	}
+	parent := typelib.ToObjectInfo(oi.t).Parent()
+	if parent == nil {
+		return nil
+	}
+	return &ObjectInfo{RegisteredTypeInfo{BaseInfo{t: typelib.ToBaseInfo(parent)}}}
+}
+
+func (oi *ObjectInfo) NumInterface() int {
+	if oi.x != nil {
+		return len(oi.x.interfaces)
+	}
+	return typelib.ToObjectInfo(oi.t).NumInterface()
+}
+
+func (oi *ObjectInfo) Interface(n int) *InterfaceInfo {
+	if oi.x != nil {
+		return &InterfaceInfo{RegisteredTypeInfo{BaseInfo{x: resolve(oi.x.interfaces[n])}}}
+	}
+	return &InterfaceInfo{RegisteredTypeInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToObjectInfo(oi.t).Interface(n))}}}
+}
+
+func (oi *ObjectInfo) NumField() int {
+	if oi.x != nil {
+		return len(oi.x.fields)
+	}
+	return typelib.ToObjectInfo(oi.t).NumField()
+}
+
+func (oi *ObjectInfo) Field(n int) *FieldInfo {
+	if oi.x != nil {
+		return &FieldInfo{BaseInfo{x: oi.x.fields[n]}}
+	}
+	return &FieldInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToObjectInfo(oi.t).Field(n))}}
+}
+
+func (oi *ObjectInfo) NumProperty() int {
+	if oi.x != nil {
+		return len(oi.x.properties)
+	}
+	return typelib.ToObjectInfo(oi.t).NumProperty()
+}
+
+func (oi *ObjectInfo) Property(n int) *PropertyInfo {
+	if oi.x != nil {
+		return &PropertyInfo{BaseInfo{x: oi.x.properties[n]}}
+	}
+	return &PropertyInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToObjectInfo(oi.t).Property(n))}}
+}
+
+func (oi *ObjectInfo) NumMethod() int {
+	if oi.x != nil {
+		return len(oi.x.methods)
+	}
+	return typelib.ToObjectInfo(oi.t).NumMethod()
+}
+
+func (oi *ObjectInfo) Method(n int) *FunctionInfo {
+	if oi.x != nil {
+		return &FunctionInfo{CallableInfo{BaseInfo{x: oi.x.methods[n]}}}
+	}
+	return &FunctionInfo{CallableInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToObjectInfo(oi.t).Method(n))}}}
+}
+
+func (oi *ObjectInfo) FindMethod(name string) *FunctionInfo {
+	if oi.x != nil {
+		return xFunctionInfo(oi.x.findMethod(name))
+	}
+	fi := typelib.ToObjectInfo(oi.t).FindMethod(name)
+	if fi == nil {
+		return nil
+	}
+	return &FunctionInfo{CallableInfo{BaseInfo{t: typelib.ToBaseInfo(fi)}}}
+}
+
+func (oi *ObjectInfo) NumSignal() int {
+	if oi.x != nil {
+		return len(oi.x.signals)
+	}
+	return typelib.ToObjectInfo(oi.t).NumSignal()
+}
+
+func (oi *ObjectInfo) Signal(n int) *SignalInfo {
+	if oi.x != nil {
+		return &SignalInfo{CallableInfo{BaseInfo{x: oi.x.signals[n]}}}
+	}
+	return &SignalInfo{CallableInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToObjectInfo(oi.t).Signal(n))}}}
+}
+
+func (oi *ObjectInfo) NumVFunc() int {
+	if oi.x != nil {
+		return len(oi.x.vfuncs)
+	}
+	return typelib.ToObjectInfo(oi.t).NumVFunc()
+}
+
+func (oi *ObjectInfo) VFunc(n int) *VFuncInfo {
+	if oi.x != nil {
+		return &VFuncInfo{CallableInfo{BaseInfo{x: oi.x.vfuncs[n]}}}
+	}
+	return &VFuncInfo{CallableInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToObjectInfo(oi.t).VFunc(n))}}}
+}
+
+func (oi *ObjectInfo) ClassStruct() *StructInfo {
+	if oi.x != nil {
+		if oi.x.classStruct == "" {
+			return nil
+		}
+		return &StructInfo{RegisteredTypeInfo{BaseInfo{x: resolve(oi.x.classStruct)}}}
+	}
+	si := typelib.ToObjectInfo(oi.t).ClassStruct()
+	if si == nil {
+		return nil
+	}
+	return &StructInfo{RegisteredTypeInfo{BaseInfo{t: typelib.ToBaseInfo(si)}}}
+}
+
+func (oi *ObjectInfo) RefFunction() string {
+	if oi.x != nil {
+		return oi.x.refFunc
+	}
+	return typelib.ToObjectInfo(oi.t).RefFunction()
+}
+
+func (oi *ObjectInfo) UnrefFunction() string {
+	if oi.x != nil {
+		return oi.x.unrefFunc
+	}
+	return typelib.ToObjectInfo(oi.t).UnrefFunction()
+}
+
+//------------------------------------------------------------------------------
+// StructInfo
+//------------------------------------------------------------------------------
+
+type StructInfo struct {
+	RegisteredTypeInfo
+}
+
+func (si *StructInfo) NumField() int {
+	if si.x != nil {
+		return len(si.x.fields)
+	}
+	return typelib.ToStructInfo(si.t).NumField()
+}
+
+func (si *StructInfo) Field(n int) *FieldInfo {
+	if si.x != nil {
+		return &FieldInfo{BaseInfo{x: si.x.fields[n]}}
+	}
+	return &FieldInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToStructInfo(si.t).Field(n))}}
+}
+
+func (si *StructInfo) NumMethod() int {
+	if si.x != nil {
+		return len(si.x.methods)
+	}
+	return typelib.ToStructInfo(si.t).NumMethod()
+}
+
+func (si *StructInfo) Method(n int) *FunctionInfo {
+	if si.x != nil {
+		return &FunctionInfo{CallableInfo{BaseInfo{x: si.x.methods[n]}}}
+	}
+	return &FunctionInfo{CallableInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToStructInfo(si.t).Method(n))}}}
+}
+
+func (si *StructInfo) FindMethod(name string) *FunctionInfo {
+	if si.x != nil {
+		return xFunctionInfo(si.x.findMethod(name))
+	}
+	fi := typelib.ToStructInfo(si.t).FindMethod(name)
+	if fi == nil {
+		return nil
+	}
+	return &FunctionInfo{CallableInfo{BaseInfo{t: typelib.ToBaseInfo(fi)}}}
+}
+
+func (si *StructInfo) Size() int {
+	if si.x != nil {
+		si.x.computeLayout()
+		return si.x.size
+	}
+	return typelib.ToStructInfo(si.t).Size()
+}
+
+func (si *StructInfo) Alignment() int {
+	if si.x != nil {
+		si.x.computeLayout()
+		return si.x.align
+	}
+	return typelib.ToStructInfo(si.t).Alignment()
+}
+
+func (si *StructInfo) IsGTypeStruct() bool {
+	if si.x != nil {
+		return si.x.gtypeStruct
+	}
+	return typelib.ToStructInfo(si.t).IsGTypeStruct()
+}
+
+func (si *StructInfo) IsForeign() bool {
+	if si.x != nil {
+		return si.x.foreign
+	}
+	return typelib.ToStructInfo(si.t).IsForeign()
+}
+
+//------------------------------------------------------------------------------
+// UnionInfo
+//------------------------------------------------------------------------------
+
+type UnionInfo struct {
+	RegisteredTypeInfo
+}
+
+func (ui *UnionInfo) NumField() int {
+	if ui.x != nil {
+		return len(ui.x.fields)
+	}
+	return typelib.ToUnionInfo(ui.t).NumField()
+}
+
+func (ui *UnionInfo) Field(n int) FieldInfo {
+	if ui.x != nil {
+		return FieldInfo{BaseInfo{x: ui.x.fields[n]}}
+	}
+	fi := typelib.ToUnionInfo(ui.t).Field(n)
+	return FieldInfo{BaseInfo{t: typelib.ToBaseInfo(&fi)}}
+}
+
+func (ui *UnionInfo) NumMethod() int {
+	if ui.x != nil {
+		return len(ui.x.methods)
+	}
+	return typelib.ToUnionInfo(ui.t).NumMethod()
+}
+
+func (ui *UnionInfo) Method(n int) *FunctionInfo {
+	if ui.x != nil {
+		return &FunctionInfo{CallableInfo{BaseInfo{x: ui.x.methods[n]}}}
+	}
+	return &FunctionInfo{CallableInfo{BaseInfo{t: typelib.ToBaseInfo(typelib.ToUnionInfo(ui.t).Method(n))}}}
+}
+
+func (ui *UnionInfo) FindMethod(name string) *FunctionInfo {
+	if ui.x != nil {
+		return xFunctionInfo(ui.x.findMethod(name))
+	}
+	fi := typelib.ToUnionInfo(ui.t).FindMethod(name)
+	if fi == nil {
+		return nil
+	}
+	return &FunctionInfo{CallableInfo{BaseInfo{t: typelib.ToBaseInfo(fi)}}}
+}
+
+func (ui *UnionInfo) Size() int {
+	if ui.x != nil {
+		ui.x.computeLayout()
+		return ui.x.size
+	}
+	return typelib.ToUnionInfo(ui.t).Size()
+}
+
+func (ui *UnionInfo) Alignment() int {
+	if ui.x != nil {
+		ui.x.computeLayout()
+		return ui.x.align
+	}
+	return typelib.ToUnionInfo(ui.t).Alignment()
+}
+
+func xFunctionInfo(xi *xInfo) *FunctionInfo {
+	if xi == nil {
+		return nil
+	}
+	return &FunctionInfo{CallableInfo{BaseInfo{x: xi}}}
+}
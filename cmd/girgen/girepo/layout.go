@@ -0,0 +1,165 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package girepo
+
+import "fmt"
+
+// 按照 LP64 平台（如 x86_64 和 aarch64 Linux）的 C 语言规则计算结构体和联合体的内存布局，
+// 代替 typelib 中由 g-ir-compiler 计算好的字段偏移和结构体大小。
+
+// _lp64Archs 是 long 和指针都是 64 位的 GOARCH
+var _lp64Archs = map[string]bool{
+	"amd64":    true,
+	"arm64":    true,
+	"loong64":  true,
+	"mips64":   true,
+	"mips64le": true,
+	"ppc64":    true,
+	"ppc64le":  true,
+	"riscv64":  true,
+	"s390x":    true,
+	"sparc64":  true,
+}
+
+// CheckLayoutTarget 检查只使用 GIR 文件时计算的内存布局是否适用于目标平台 goos/goarch，
+// 只支持 LP64 平台，Windows 是 LLP64 的，long 只有 32 位。
+func CheckLayoutTarget(goos, goarch string) error {
+	if goos == "windows" || !_lp64Archs[goarch] {
+		return fmt.Errorf("struct layouts computed from gir files are for LP64 targets only, "+
+			"%s/%s is not supported", goos, goarch)
+	}
+	return nil
+}
+
+type layoutState int
+
+const (
+	layoutNone layoutState = iota
+	layoutComputing
+	layoutDone
+)
+
+const pointerSize = 8
+
+func alignUp(n, align int) int {
+	if align <= 1 {
+		return n
+	}
+	return (n + align - 1) / align * align
+}
+
+// computeLayout 计算 struct，union 和 object 的大小、对齐和每个字段的偏移。
+func (x *xInfo) computeLayout() {
+	if x.layout != layoutNone {
+		// 已经算好了，或者循环引用，循环引用时大小按 0 算
+		return
+	}
+	x.layout = layoutComputing
+
+	maxAlign := 1
+	if x.infoType == INFO_TYPE_UNION {
+		size := 0
+		for _, field := range x.fields {
+			fieldSize, fieldAlign := typeLayout(field.type0)
+			field.offset = 0
+			if fieldSize > size {
+				size = fieldSize
+			}
+			if fieldAlign > maxAlign {
+				maxAlign = fieldAlign
+			}
+		}
+		x.size = alignUp(size, maxAlign)
+	} else {
+		bitPos := 0 // 从结构体开始的位数
+		for _, field := range x.fields {
+			fieldSize, fieldAlign := typeLayout(field.type0)
+			if fieldAlign > maxAlign {
+				maxAlign = fieldAlign
+			}
+
+			if field.bits > 0 && fieldSize > 0 {
+				// 位域放在当前位置，除非它会跨过存储单元的边界
+				unitBits := fieldSize * 8
+				if bitPos/unitBits != (bitPos+field.bits-1)/unitBits {
+					bitPos = alignUp(bitPos, unitBits)
+				}
+				field.offset = bitPos / unitBits * fieldSize
+				bitPos += field.bits
+				continue
+			}
+
+			offset := alignUp((bitPos+7)/8, fieldAlign)
+			field.offset = offset
+			bitPos = (offset + fieldSize) * 8
+		}
+		x.size = alignUp((bitPos+7)/8, maxAlign)
+	}
+	x.align = maxAlign
+	x.layout = layoutDone
+}
+
+// typeLayout 返回类型的大小和对齐。
+func typeLayout(t *xType) (size, align int) {
+	if t.isPointer() {
+		return pointerSize, pointerSize
+	}
+
+	switch t.tag {
+	case TYPE_TAG_INT8, TYPE_TAG_UINT8:
+		return 1, 1
+	case TYPE_TAG_INT16, TYPE_TAG_UINT16:
+		return 2, 2
+	case TYPE_TAG_BOOLEAN, TYPE_TAG_INT32, TYPE_TAG_UINT32, TYPE_TAG_UNICHAR, TYPE_TAG_FLOAT:
+		return 4, 4
+	case TYPE_TAG_INT64, TYPE_TAG_UINT64, TYPE_TAG_DOUBLE, TYPE_TAG_GTYPE:
+		return 8, 8
+	case TYPE_TAG_UTF8, TYPE_TAG_FILENAME, TYPE_TAG_GLIST, TYPE_TAG_GSLIST, TYPE_TAG_GHASH,
+		TYPE_TAG_ERROR:
+		return pointerSize, pointerSize
+
+	case TYPE_TAG_ARRAY:
+		if t.arrayType == ARRAY_TYPE_C && t.arrayFixedSize > 0 && len(t.params) == 1 {
+			elemSize, elemAlign := typeLayout(t.params[0])
+			return elemSize * t.arrayFixedSize, elemAlign
+		}
+		return pointerSize, pointerSize
+
+	case TYPE_TAG_INTERFACE:
+		ifc := t.interface0()
+		switch ifc.infoType {
+		case INFO_TYPE_STRUCT, INFO_TYPE_UNION, INFO_TYPE_OBJECT:
+			ifc.computeLayout()
+			return ifc.size, ifc.align
+		case INFO_TYPE_ENUM, INFO_TYPE_FLAGS:
+			switch ifc.storageType() {
+			case TYPE_TAG_INT64, TYPE_TAG_UINT64:
+				return 8, 8
+			}
+			return 4, 4
+		case INFO_TYPE_CALLBACK:
+			// 函数指针
+			return pointerSize, pointerSize
+		}
+	}
+	return 0, 1
+}
@@ -0,0 +1,73 @@
+//go:build !gironly
+// +build !gironly
+
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package typelib 是 girepo 读取 typelib 文件的后端，它就是 gi 包，需要链接 libgirepository。
+// 使用 gironly 构建标签时换成不依赖 libgirepository 的空实现，girgen 只能使用 GIR 文件。
+package typelib
+
+import "github.com/electricface/go-gir3/gi"
+
+// Available 表示能否读取 typelib 文件
+const Available = true
+
+type (
+	Repository         = gi.Repository
+	BaseInfoLike       = gi.BaseInfoLike
+	BaseInfo           = gi.BaseInfo
+	ArgInfo            = gi.ArgInfo
+	ConstantInfo       = gi.ConstantInfo
+	FieldInfo          = gi.FieldInfo
+	PropertyInfo       = gi.PropertyInfo
+	TypeInfo           = gi.TypeInfo
+	CallableInfo       = gi.CallableInfo
+	FunctionInfo       = gi.FunctionInfo
+	SignalInfo         = gi.SignalInfo
+	VFuncInfo          = gi.VFuncInfo
+	RegisteredTypeInfo = gi.RegisteredTypeInfo
+	EnumInfo           = gi.EnumInfo
+	ValueInfo          = gi.ValueInfo
+	InterfaceInfo      = gi.InterfaceInfo
+	ObjectInfo         = gi.ObjectInfo
+	StructInfo         = gi.StructInfo
+	UnionInfo          = gi.UnionInfo
+)
+
+var (
+	DefaultRepository    = gi.DefaultRepository
+	ToBaseInfo           = gi.ToBaseInfo
+	ToArgInfo            = gi.ToArgInfo
+	ToConstantInfo       = gi.ToConstantInfo
+	ToFieldInfo          = gi.ToFieldInfo
+	ToPropertyInfo       = gi.ToPropertyInfo
+	ToTypeInfo           = gi.ToTypeInfo
+	ToCallableInfo       = gi.ToCallableInfo
+	ToFunctionInfo       = gi.ToFunctionInfo
+	ToVFuncInfo          = gi.ToVFuncInfo
+	ToRegisteredTypeInfo = gi.ToRegisteredTypeInfo
+	ToEnumInfo           = gi.ToEnumInfo
+	ToInterfaceInfo      = gi.ToInterfaceInfo
+	ToObjectInfo         = gi.ToObjectInfo
+	ToStructInfo         = gi.ToStructInfo
+	ToUnionInfo          = gi.ToUnionInfo
+)
@@ -0,0 +1,191 @@
+//go:build gironly
+// +build gironly
+
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package typelib
+
+import (
+	"errors"
+
+	"github.com/electricface/go-gir3/gi/gitypes"
+)
+
+// Available 表示能否读取 typelib 文件
+const Available = false
+
+// 使用 gironly 构建标签时 girepo 只使用 GIR 文件，不会调用这里的函数和方法。
+var errNoTypelib = errors.New("girgen is built with the gironly tag, typelib files are not supported")
+
+type Repository struct{}
+type Typelib struct{}
+type GType uint
+
+type BaseInfoLike interface {
+	inheritedFromBaseInfo() *BaseInfo
+}
+
+type BaseInfo struct{}
+
+func (bi *BaseInfo) inheritedFromBaseInfo() *BaseInfo { return bi }
+
+type ArgInfo struct{ BaseInfo }
+type ConstantInfo struct{ BaseInfo }
+type FieldInfo struct{ BaseInfo }
+type PropertyInfo struct{ BaseInfo }
+type TypeInfo struct{ BaseInfo }
+type CallableInfo struct{ BaseInfo }
+type FunctionInfo struct{ CallableInfo }
+type SignalInfo struct{ CallableInfo }
+type VFuncInfo struct{ CallableInfo }
+type RegisteredTypeInfo struct{ BaseInfo }
+type EnumInfo struct{ RegisteredTypeInfo }
+type ValueInfo struct{ BaseInfo }
+type InterfaceInfo struct{ RegisteredTypeInfo }
+type ObjectInfo struct{ RegisteredTypeInfo }
+type StructInfo struct{ RegisteredTypeInfo }
+type UnionInfo struct{ RegisteredTypeInfo }
+
+func DefaultRepository() *Repository                              { panic(errNoTypelib) }
+func ToBaseInfo(bil BaseInfoLike) *BaseInfo                       { panic(errNoTypelib) }
+func ToArgInfo(bil BaseInfoLike) *ArgInfo                         { panic(errNoTypelib) }
+func ToConstantInfo(bil BaseInfoLike) *ConstantInfo               { panic(errNoTypelib) }
+func ToFieldInfo(bil BaseInfoLike) *FieldInfo                     { panic(errNoTypelib) }
+func ToPropertyInfo(bil BaseInfoLike) *PropertyInfo               { panic(errNoTypelib) }
+func ToTypeInfo(bil BaseInfoLike) *TypeInfo                       { panic(errNoTypelib) }
+func ToCallableInfo(bil BaseInfoLike) *CallableInfo               { panic(errNoTypelib) }
+func ToFunctionInfo(bil BaseInfoLike) *FunctionInfo               { panic(errNoTypelib) }
+func ToVFuncInfo(bil BaseInfoLike) *VFuncInfo                     { panic(errNoTypelib) }
+func ToRegisteredTypeInfo(bil BaseInfoLike) *RegisteredTypeInfo   { panic(errNoTypelib) }
+func ToEnumInfo(bil BaseInfoLike) *EnumInfo                       { panic(errNoTypelib) }
+func ToInterfaceInfo(bil BaseInfoLike) *InterfaceInfo             { panic(errNoTypelib) }
+func ToObjectInfo(bil BaseInfoLike) *ObjectInfo                   { panic(errNoTypelib) }
+func ToStructInfo(bil BaseInfoLike) *StructInfo                   { panic(errNoTypelib) }
+func ToUnionInfo(bil BaseInfoLike) *UnionInfo                     { panic(errNoTypelib) }
+func (ai *ArgInfo) Direction() gitypes.Direction                  { panic(errNoTypelib) }
+func (ai *ArgInfo) IsCallerAllocates() bool                       { panic(errNoTypelib) }
+func (ai *ArgInfo) IsOptional() bool                              { panic(errNoTypelib) }
+func (ai *ArgInfo) MayBeNil() bool                                { panic(errNoTypelib) }
+func (ai *ArgInfo) OwnershipTransfer() gitypes.Transfer           { panic(errNoTypelib) }
+func (ai *ArgInfo) Scope() gitypes.ScopeType                      { panic(errNoTypelib) }
+func (ai *ArgInfo) Closure() int                                  { panic(errNoTypelib) }
+func (ai *ArgInfo) Destroy() int                                  { panic(errNoTypelib) }
+func (ai *ArgInfo) Type() *TypeInfo                               { panic(errNoTypelib) }
+func (bi *BaseInfo) IsNil() bool                                  { panic(errNoTypelib) }
+func (bi *BaseInfo) Unref()                                       { panic(errNoTypelib) }
+func (bi *BaseInfo) Type() gitypes.InfoType                       { panic(errNoTypelib) }
+func (bi *BaseInfo) Name() string                                 { panic(errNoTypelib) }
+func (bi *BaseInfo) Namespace() string                            { panic(errNoTypelib) }
+func (bi *BaseInfo) IsDeprecated() bool                           { panic(errNoTypelib) }
+func (bi *BaseInfo) Container() *BaseInfo                         { panic(errNoTypelib) }
+func (ci *CallableInfo) ReturnType() *TypeInfo                    { panic(errNoTypelib) }
+func (ci *CallableInfo) CallerOwns() gitypes.Transfer             { panic(errNoTypelib) }
+func (ci *CallableInfo) MayReturnNil() bool                       { panic(errNoTypelib) }
+func (ci *CallableInfo) NumArg() int                              { panic(errNoTypelib) }
+func (ci *CallableInfo) Arg(n int) *ArgInfo                       { panic(errNoTypelib) }
+func (ci *ConstantInfo) Type() *TypeInfo                          { panic(errNoTypelib) }
+func (ci *ConstantInfo) Value() interface{}                       { panic(errNoTypelib) }
+func (ei *EnumInfo) NumValue() int                                { panic(errNoTypelib) }
+func (ei *EnumInfo) Value(n int) *ValueInfo                       { panic(errNoTypelib) }
+func (ei *EnumInfo) NumMethod() int                               { panic(errNoTypelib) }
+func (ii *EnumInfo) Method(n int) *FunctionInfo                   { panic(errNoTypelib) }
+func (ei *EnumInfo) StorageType() gitypes.TypeTag                 { panic(errNoTypelib) }
+func (ei *EnumInfo) ErrorDomain() string                          { panic(errNoTypelib) }
+func (fi *FieldInfo) Flags() gitypes.FieldInfoFlags               { panic(errNoTypelib) }
+func (fi *FieldInfo) Size() int                                   { panic(errNoTypelib) }
+func (fi *FieldInfo) Offset() int                                 { panic(errNoTypelib) }
+func (fi *FieldInfo) Type() *TypeInfo                             { panic(errNoTypelib) }
+func (fi *FunctionInfo) Symbol() string                           { panic(errNoTypelib) }
+func (fi *FunctionInfo) Flags() gitypes.FunctionInfoFlags         { panic(errNoTypelib) }
+func (t GType) IsBoxed() bool                                     { panic(errNoTypelib) }
+func (ii *InterfaceInfo) NumPrerequisite() int                    { panic(errNoTypelib) }
+func (ii *InterfaceInfo) Prerequisite(n int) *BaseInfo            { panic(errNoTypelib) }
+func (ii *InterfaceInfo) NumProperty() int                        { panic(errNoTypelib) }
+func (ii *InterfaceInfo) Property(n int) *PropertyInfo            { panic(errNoTypelib) }
+func (ii *InterfaceInfo) NumMethod() int                          { panic(errNoTypelib) }
+func (ii *InterfaceInfo) Method(n int) *FunctionInfo              { panic(errNoTypelib) }
+func (ii *InterfaceInfo) FindMethod(name string) *FunctionInfo    { panic(errNoTypelib) }
+func (ii *InterfaceInfo) NumSignal() int                          { panic(errNoTypelib) }
+func (ii *InterfaceInfo) Signal(n int) *SignalInfo                { panic(errNoTypelib) }
+func (ii *InterfaceInfo) NumVFunc() int                           { panic(errNoTypelib) }
+func (ii *InterfaceInfo) VFunc(n int) *VFuncInfo                  { panic(errNoTypelib) }
+func (oi *ObjectInfo) Abstract() bool                             { panic(errNoTypelib) }
+func (oi *ObjectInfo) Fundamental() bool                          { panic(errNoTypelib) }
+func (oi *ObjectInfo) Parent() *ObjectInfo                        { panic(errNoTypelib) }
+func (oi *ObjectInfo) NumInterface() int                          { panic(errNoTypelib) }
+func (oi *ObjectInfo) Interface(n int) *InterfaceInfo             { panic(errNoTypelib) }
+func (oi *ObjectInfo) NumField() int                              { panic(errNoTypelib) }
+func (oi *ObjectInfo) Field(n int) *FieldInfo                     { panic(errNoTypelib) }
+func (oi *ObjectInfo) NumProperty() int                           { panic(errNoTypelib) }
+func (oi *ObjectInfo) Property(n int) *PropertyInfo               { panic(errNoTypelib) }
+func (oi *ObjectInfo) NumMethod() int                             { panic(errNoTypelib) }
+func (oi *ObjectInfo) Method(n int) *FunctionInfo                 { panic(errNoTypelib) }
+func (oi *ObjectInfo) FindMethod(name string) *FunctionInfo       { panic(errNoTypelib) }
+func (oi *ObjectInfo) NumSignal() int                             { panic(errNoTypelib) }
+func (oi *ObjectInfo) Signal(n int) *SignalInfo                   { panic(errNoTypelib) }
+func (oi *ObjectInfo) NumVFunc() int                              { panic(errNoTypelib) }
+func (oi *ObjectInfo) VFunc(n int) *VFuncInfo                     { panic(errNoTypelib) }
+func (oi *ObjectInfo) ClassStruct() *StructInfo                   { panic(errNoTypelib) }
+func (oi *ObjectInfo) UnrefFunction() string                      { panic(errNoTypelib) }
+func (oi *ObjectInfo) RefFunction() string                        { panic(errNoTypelib) }
+func (pi *PropertyInfo) Flags() gitypes.ParamFlags                { panic(errNoTypelib) }
+func (pi *PropertyInfo) Type() *TypeInfo                          { panic(errNoTypelib) }
+func (pi *PropertyInfo) OwnershipTransfer() gitypes.Transfer      { panic(errNoTypelib) }
+func (rt *RegisteredTypeInfo) TypeName() string                   { panic(errNoTypelib) }
+func (rt *RegisteredTypeInfo) TypeInit() string                   { panic(errNoTypelib) }
+func (rt *RegisteredTypeInfo) GetGType() GType                    { panic(errNoTypelib) }
+func (r *Repository) FindByName(namespace, name string) *BaseInfo { panic(errNoTypelib) }
+func (r *Repository) Require(namespace, version string, flags gitypes.RepositoryLoadFlags) (*Typelib, error) {
+	panic(errNoTypelib)
+}
+func (r *Repository) ImmediateDependencies(namespace string) []string { panic(errNoTypelib) }
+func (r *Repository) NumInfo(namespace string) int                    { panic(errNoTypelib) }
+func (r *Repository) Info(namespace string, index int) *BaseInfo      { panic(errNoTypelib) }
+func (r *Repository) CPrefix(namespace string) string                 { panic(errNoTypelib) }
+func (si *StructInfo) NumField() int                                  { panic(errNoTypelib) }
+func (si *StructInfo) Field(n int) *FieldInfo                         { panic(errNoTypelib) }
+func (si *StructInfo) NumMethod() int                                 { panic(errNoTypelib) }
+func (si *StructInfo) Method(n int) *FunctionInfo                     { panic(errNoTypelib) }
+func (si *StructInfo) FindMethod(name string) *FunctionInfo           { panic(errNoTypelib) }
+func (si *StructInfo) Size() int                                      { panic(errNoTypelib) }
+func (si *StructInfo) Alignment() int                                 { panic(errNoTypelib) }
+func (si *StructInfo) IsGTypeStruct() bool                            { panic(errNoTypelib) }
+func (si *StructInfo) IsForeign() bool                                { panic(errNoTypelib) }
+func (ti *TypeInfo) IsPointer() bool                                  { panic(errNoTypelib) }
+func (ti *TypeInfo) Tag() gitypes.TypeTag                             { panic(errNoTypelib) }
+func (ti *TypeInfo) ParamType(n int) *TypeInfo                        { panic(errNoTypelib) }
+func (ti *TypeInfo) Interface() *BaseInfo                             { panic(errNoTypelib) }
+func (ti *TypeInfo) ArrayLength() int                                 { panic(errNoTypelib) }
+func (ti *TypeInfo) ArrayFixedSize() int                              { panic(errNoTypelib) }
+func (ti *TypeInfo) IsZeroTerminated() bool                           { panic(errNoTypelib) }
+func (ti *TypeInfo) ArrayType() gitypes.ArrayType                     { panic(errNoTypelib) }
+func (ui *UnionInfo) NumField() int                                   { panic(errNoTypelib) }
+func (ui *UnionInfo) Field(n int) FieldInfo                           { panic(errNoTypelib) }
+func (ui *UnionInfo) NumMethod() int                                  { panic(errNoTypelib) }
+func (ui *UnionInfo) Method(n int) *FunctionInfo                      { panic(errNoTypelib) }
+func (ui *UnionInfo) FindMethod(name string) *FunctionInfo            { panic(errNoTypelib) }
+func (ui *UnionInfo) Size() int                                       { panic(errNoTypelib) }
+func (ui *UnionInfo) Alignment() int                                  { panic(errNoTypelib) }
+func (vfi *VFuncInfo) Flags() gitypes.VFuncInfoFlags                  { panic(errNoTypelib) }
+func (vfi *VFuncInfo) Offset() int                                    { panic(errNoTypelib) }
+func (vfi *VFuncInfo) Invoker() *FunctionInfo                         { panic(errNoTypelib) }
+func (vi *ValueInfo) Value() int64                                    { panic(errNoTypelib) }
@@ -24,6 +24,8 @@ package main
 import (
 	"testing"
 
+	"github.com/electricface/go-gir3/cmd/girgen/xmlp"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -60,3 +62,221 @@ func Test_getC(t *testing.T) {
 		getConstructorName("DesktopAppInfo", "NewFromFilename"))
 	assert.Equal(t, "KeyFileCreateWithPath", getConstructorName("KeyFile", "CreateWithPath"))
 }
+
+func Test_getPropertyName(t *testing.T) {
+	assert.Equal(t, "HasDefault", getPropertyName("has-default"))
+	assert.Equal(t, "Visible", getPropertyName("visible"))
+	assert.Equal(t, "WidthRequest", getPropertyName("width_request"))
+}
+
+func Test_getFieldPtrExpr(t *testing.T) {
+	assert.Equal(t, "v.P", getFieldPtrExpr("v", 0))
+	assert.Equal(t, "unsafe.Pointer(uintptr(v.P) + 8)", getFieldPtrExpr("v", 8))
+}
+
+func Test_splitNameType(t *testing.T) {
+	name, type0 := splitNameType("cancellable ICancellable")
+	assert.Equal(t, "cancellable", name)
+	assert.Equal(t, "ICancellable", type0)
+
+	name, type0 = splitNameType("callback int/*TODO_TYPE CALLBACK*/")
+	assert.Equal(t, "callback", name)
+	assert.Equal(t, "int/*TODO_TYPE CALLBACK*/", type0)
+}
+
+func Test_addContextFuncName(t *testing.T) {
+	assert.True(t, addContextFuncName("(v File)", "LoadContentsContext"))
+	assert.False(t, addContextFuncName("(v1 File)", "LoadContentsContext"))
+	assert.True(t, addContextFuncName("(v *FileIfc)", "LoadContentsContext"))
+	assert.True(t, addContextFuncName("", "LoadContentsContext"))
+}
+
+func Test_getEnumMemberName(t *testing.T) {
+	assert.Equal(t, "ModeRead", getEnumMemberName("Mode", "ModeFlags", "read"))
+	assert.Equal(t, "ModeFlags0", getEnumMemberName("Mode", "ModeFlags", "flags"))
+	assert.Equal(t, "ColorRed", getEnumMemberName("Color", "ColorEnum", "red"))
+}
+
+func Test_getEnumValueNick(t *testing.T) {
+	assert.Equal(t, "not-found", getEnumValueNick(nil, "not_found"))
+	assert.Equal(t, "none", getEnumValueNick(nil, "none"))
+
+	xEnum := &xmlp.EnumInfo{
+		Members: []*xmlp.EnumMember{
+			{Name: "dark_red", GlibNick: "maroon"},
+			{Name: "light_red"},
+		},
+	}
+	assert.Equal(t, "maroon", getEnumValueNick(xEnum, "dark_red"))
+	assert.Equal(t, "light-red", getEnumValueNick(xEnum, "light_red"))
+	assert.Equal(t, "not-found", getEnumValueNick(xEnum, "not_found"))
+}
+
+func Test_checkScalarLayout(t *testing.T) {
+	// GdkRectangle
+	fields := []scalarField{
+		{name: "X", type0: "int32", offset: 0},
+		{name: "Y", type0: "int32", offset: 4},
+		{name: "Width", type0: "int32", offset: 8},
+		{name: "Height", type0: "int32", offset: 12},
+	}
+	assert.True(t, checkScalarLayout(fields, 16))
+	assert.False(t, checkScalarLayout(fields, 20))
+
+	// 末尾需要填充
+	fields = []scalarField{
+		{name: "A", type0: "float64", offset: 0},
+		{name: "B", type0: "int16", offset: 8},
+	}
+	assert.True(t, checkScalarLayout(fields, 16))
+	assert.False(t, checkScalarLayout(fields, 10))
+
+	// 偏移量不是自然对齐的
+	fields = []scalarField{
+		{name: "A", type0: "int32", offset: 0},
+		{name: "B", type0: "int64", offset: 4},
+	}
+	assert.False(t, checkScalarLayout(fields, 12))
+
+	assert.False(t, checkScalarLayout(nil, 0))
+	assert.False(t, checkScalarLayout([]scalarField{{name: "P", type0: "unsafe.Pointer"}}, 8))
+}
+
+func Test_convertDoc(t *testing.T) {
+	symbols := map[string]string{
+		"GtkWidget":            "Widget",
+		"gtk_widget_show":      "Widget.Show",
+		"GDK_WINDOW_STATE_TOP": "gdk.WindowStateTop",
+	}
+	lookup := func(cName string) string {
+		return symbols[cName]
+	}
+
+	assert.Equal(t, "Returns true if widget is a Widget, see Widget.Show().",
+		convertDocRefs("Returns %TRUE if @widget is a #GtkWidget, see gtk_widget_show().", lookup))
+	assert.Equal(t, "nil or gdk.WindowStateTop, other_func() and Widget::destroy",
+		convertDocRefs("%NULL or %GDK_WINDOW_STATE_TOP, other_func() and #GtkWidget::destroy", lookup))
+	assert.Equal(t, "see https://example.com/a#anchor",
+		convertDocRefs("see https://example.com/a#anchor", lookup))
+
+	lines := convertDoc(`Shows @widget.
+
+  |[<!-- language="C" -->
+  gtk_widget_show (widget);
+  ]|
+`, lookup)
+	assert.Equal(t, []string{
+		"Shows widget.",
+		"",
+		"\t  gtk_widget_show (widget);",
+	}, lines)
+}
+
+func Test_versionLess(t *testing.T) {
+	major, minor, ok := parseVersion("2.28.1")
+	assert.True(t, ok)
+	assert.Equal(t, 2, major)
+	assert.Equal(t, 28, minor)
+
+	_, _, ok = parseVersion("3")
+	assert.False(t, ok)
+
+	assert.True(t, versionLess("3.18", "3.22"))
+	assert.True(t, versionLess("2.80", "3.0"))
+	assert.False(t, versionLess("3.22", "3.22"))
+	assert.False(t, versionLess("3.22", "3.4"))
+	assert.False(t, versionLess("x", "3.4"))
+}
+
+func Test_getDeprecatedDocLines(t *testing.T) {
+	oldDocSymbols := _docSymbols
+	_docSymbols = map[string]string{
+		"gtk_widget_show": "Widget.Show",
+	}
+	defer func() { _docSymbols = oldDocSymbols }()
+
+	assert.Equal(t, []string{"Deprecated: Do not use."}, getDeprecatedDocLines("", ""))
+	assert.Equal(t, []string{"Deprecated: Since 3.10."}, getDeprecatedDocLines("3.10", ""))
+	assert.Equal(t, []string{"Deprecated: Since 3.10. Use Widget.Show() instead."},
+		getDeprecatedDocLines("3.10", "Use gtk_widget_show() instead."))
+}
+
+func Test_getCgoType(t *testing.T) {
+	for ctype, want := range map[string]string{
+		"gint":                "C.gint",
+		"GtkWidget*":          "*C.GtkWidget",
+		"const gchar*":        "*C.gchar",
+		"const gchar* const*": "**C.gchar",
+		"unsigned int":        "C.uint",
+		"GDestroyNotify":      "C.GDestroyNotify",
+	} {
+		result, ok := getCgoType(ctype)
+		assert.True(t, ok, ctype)
+		assert.Equal(t, want, result, ctype)
+	}
+	for _, ctype := range []string{"", "va_list", "struct _GtkWidget*", "unsigned long"} {
+		_, ok := getCgoType(ctype)
+		assert.False(t, ok, ctype)
+	}
+}
+
+func Test_getCgoCallExpr(t *testing.T) {
+	xFunc := &xmlp.FunctionInfo{
+		Parameters: &xmlp.Parameters{
+			InstanceParameter: &xmlp.Parameter{Type: &xmlp.Type{CType: "GtkWidget*"}},
+			Parameters: []*xmlp.Parameter{
+				{Type: &xmlp.Type{CType: "double"}},
+			},
+		},
+		Throws: true,
+	}
+	argExprs, ok := getCgoArgExprs(xFunc, []string{"arg_v", "arg_opacity", "arg_err"},
+		[]string{"v_pointer", "v_double", "v_pointer"})
+	if assert.True(t, ok) {
+		assert.Equal(t, "C.gtk_widget_set_opacity((*C.GtkWidget)(arg_v.Pointer()), C.double(arg_opacity.Double()), "+
+			"(**C.GError)(arg_err.Pointer()))",
+			getCgoCallExpr("gtk_widget_set_opacity", argExprs, ""))
+	}
+
+	// 参数个数和 C 函数的不一致
+	_, ok = getCgoArgExprs(xFunc, []string{"arg_v"}, []string{"v_pointer"})
+	assert.False(t, ok)
+	_, ok = getCgoArgExprs(nil, nil, nil)
+	assert.False(t, ok)
+
+	assert.Equal(t, "gi.NewPointerArgument(unsafe.Pointer(C.gtk_widget_get_parent((*C.GtkWidget)(arg_v.Pointer()))))",
+		getCgoCallExpr("gtk_widget_get_parent", []string{"(*C.GtkWidget)(arg_v.Pointer())"}, "v_pointer"))
+	assert.Equal(t, "gi.NewBoolArgument(C.gtk_widget_get_visible(nil) != 0)",
+		getCgoCallExpr("gtk_widget_get_visible", []string{"nil"}, "v_boolean"))
+	assert.Equal(t, "gi.NewUint32Argument(uint32(C.gtk_get_major_version()))",
+		getCgoCallExpr("gtk_get_major_version", nil, "v_uint32"))
+}
+
+func TestReportAddTodoType(t *testing.T) {
+	r := &nsReport{Namespace: "Gtk"}
+	r.addTodoType(newReportEntry("function", "gtk_foo", 0, "a", "in", nil),
+		"int/*TODO_TYPE isPtr: true, tag: ghash*/")
+	r.addTodoType(newReportEntry("function", "gtk_foo", -1, "", dirReturn, nil),
+		"unsafe.Pointer /*TODO:TYPE*/")
+	r.addTodoType(newReportEntry("callback", "GtkCallback", 1, "b", "in", nil),
+		"unsafe.Pointer/*TODO_CB tag: ghash, isPtr: true*/")
+	r.addTodoType(newReportEntry("function", "gtk_bar", 0, "a", "in", nil),
+		"int/*TODO_TYPE isPtr: true, tag: ghash*/")
+	// 没有 TODO 标记的不记录
+	r.addTodoType(newReportEntry("function", "gtk_bar", 1, "b", "in", nil), "string")
+	assert.Equal(t, 4, len(r.Entries))
+	assert.Equal(t, "isPtr: true, tag: ghash", r.Entries[0].Reason)
+	assert.Equal(t, "unknown type", r.Entries[1].Reason)
+	assert.Equal(t, "tag: ghash, isPtr: true", r.Entries[2].Reason)
+
+	r.summarize()
+	assert.Equal(t, []reasonCount{
+		{Reason: "isPtr: true, tag: ghash", Count: 2},
+		{Reason: "tag: ghash, isPtr: true", Count: 1},
+		{Reason: "unknown type", Count: 1},
+	}, r.Summary)
+
+	// 没有 -report 参数时什么也不做
+	var nilReport *nsReport
+	nilReport.addTodoType(newReportEntry("function", "gtk_foo", 0, "a", "in", nil), "int/*TODO*/")
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"flag"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
+	"github.com/stretchr/testify/assert"
+)
+
+// 用 go test -run TestGolden -update 更新 testdata/golden 下的文件
+var _optUpdate = flag.Bool("update", false, "update golden files")
+
+// 设置了此环境变量时，测试程序作为 girgen 运行，供 golden 测试调用。
+const envRunGirgen = "GIRGEN_TEST_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(envRunGirgen) == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+var _goldenCases = []struct {
+	namespace string
+	version   string
+	mode      string
+	config    string // 配置文件 config.json 的内容，为空表示不使用配置文件
+	suffix    string // golden 文件名的后缀，区分使用不同配置的测试
+}{
+	{"Golden", "1.0", modeFfi, "", ""},
+	{"Golden", "1.0", modeCgo, "", ""},
+	{"Golden", "1.0", modeFfi, `{"ownership": true}`, "ownership"},
+	{"Golden", "1.0", modeFfi, `{"minVersion": "1.2"}`, "min-version"},
+}
+
+var _regYear = regexp.MustCompile(`2019 ~ \d+`)
+
+// runGirgen 在临时目录中为 testdata 中的 gir 文件生成代码，返回生成的代码。
+// typelibDir 为空时以 -gir-only 方式运行，否则从 typelibDir 中读取 typelib 文件。
+func runGirgen(t *testing.T, namespace, version, mode, config, typelibDir string) []byte {
+	girDir, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpDir, err := ioutil.TempDir("", "girgen-golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// 生成的文件在 out 目录中，它的配置文件在 lib.in/out 中
+	cfgDir := filepath.Join(tmpDir, "lib.in", "out")
+	err = os.MkdirAll(cfgDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config != "" {
+		err = ioutil.WriteFile(filepath.Join(cfgDir, "config.json"), []byte(config), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	outFile := filepath.Join(tmpDir, "out", "golden_auto.go")
+	args := []string{"-gir-dir", girDir, "-n", namespace, "-v", version, "-mode", mode, "-f", outFile}
+	env := append(os.Environ(), envRunGirgen+"=1", "GIRGEN_SYNC_MODE=build")
+	if typelibDir == "" {
+		args = append([]string{"-gir-only"}, args...)
+	} else {
+		// libgirepository 先在 GI_TYPELIB_PATH 中查找 typelib 文件
+		env = append(env, "GI_TYPELIB_PATH="+typelibDir)
+	}
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = tmpDir
+	cmd.Env = env
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("run girgen: %v\n%s", err, out)
+	}
+
+	data, err := ioutil.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// go fmt 可能因环境原因执行失败，这里再格式化一次。
+	formatted, err := format.Source(data)
+	if err != nil {
+		t.Fatalf("format %s: %v", outFile, err)
+	}
+	// 文件头中有当前年份
+	return _regYear.ReplaceAllLiteral(formatted, []byte("2019 ~ $year"))
+}
+
+func TestGolden(t *testing.T) {
+	for _, c := range _goldenCases {
+		name := c.namespace + "-" + c.version + "-" + c.mode
+		if c.suffix != "" {
+			name += "-" + c.suffix
+		}
+		t.Run(name, func(t *testing.T) {
+			got := runGirgen(t, c.namespace, c.version, c.mode, c.config, "")
+			goldenFile := filepath.Join("testdata", "golden", name+".go.golden")
+			if *_optUpdate {
+				err := os.MkdirAll(filepath.Dir(goldenFile), 0755)
+				if err != nil {
+					t.Fatal(err)
+				}
+				err = ioutil.WriteFile(goldenFile, got, 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := ioutil.ReadFile(goldenFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, string(want), string(got),
+				"output differs from %s, run go test -run TestGolden -update to update it", goldenFile)
+		})
+	}
+}
+
+// TestGoldenTypelib 用 g-ir-compiler 把 testdata 中的 gir 文件编译为 typelib 文件，
+// 不使用 -gir-only 生成代码，结果应该和 -gir-only 方式生成的 golden 文件相同。
+func TestGoldenTypelib(t *testing.T) {
+	if gi.GirOnly() {
+		t.Skip("girgen is built without typelib support")
+	}
+	compiler, err := exec.LookPath("g-ir-compiler")
+	if err != nil {
+		t.Skip("g-ir-compiler not found")
+	}
+	typelibDir, err := ioutil.TempDir("", "girgen-typelib")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(typelibDir)
+
+	girFiles, err := filepath.Glob(filepath.Join("testdata", "*.gir"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, girFile := range girFiles {
+		name := strings.TrimSuffix(filepath.Base(girFile), ".gir")
+		out, err := exec.Command(compiler, "--includedir", "testdata",
+			"-o", filepath.Join(typelibDir, name+".typelib"), girFile).CombinedOutput()
+		if err != nil {
+			t.Fatalf("compile %s: %v\n%s", girFile, err, out)
+		}
+	}
+
+	got := runGirgen(t, "Golden", "1.0", modeFfi, "", typelibDir)
+	goldenFile := filepath.Join("testdata", "golden", "Golden-1.0-ffi.go.golden")
+	want, err := ioutil.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(want), string(got),
+		"output with typelib files differs from %s", goldenFile)
+}
@@ -27,13 +27,15 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
 	"github.com/electricface/go-gir3/cmd/girgen/xmlp"
-	"github.com/electricface/go-gir3/gi"
+	"github.com/electricface/go-gir3/cmd/internal/flagutil"
 )
 
 var _girPkgPath = "github.com/electricface/go-gir"
@@ -70,6 +72,12 @@ var _optOutputFile string
 var _optCfgFile string
 var _optPkg string
 var _optSyncGi bool
+var _optGirDirs flagutil.Strings
+var _optMinVersion string
+var _optMode string
+var _optGirOnly bool
+var _optArch string
+var _optReport string
 
 func init() {
 	log.SetFlags(log.Lshortfile)
@@ -80,6 +88,12 @@ func init() {
 	flag.StringVar(&_optCfgFile, "c", "", "config file")
 	flag.StringVar(&_optPkg, "p", "", "package")
 	flag.BoolVar(&_optSyncGi, "sync-gi", false, "sync gi to out dir")
+	flag.Var(&_optGirDirs, "gir-dir", "directory to search for gir files, can be repeated")
+	flag.StringVar(&_optMinVersion, "min-version", "", "omit APIs newer than this version, such as 3.18")
+	flag.StringVar(&_optMode, "mode", modeFfi, "how generated functions call C functions, ffi or cgo")
+	flag.BoolVar(&_optGirOnly, "gir-only", false, "read type information from gir files only, no typelib files needed")
+	flag.StringVar(&_optArch, "arch", "", "target GOARCH of struct layouts computed in gir-only mode, defaults to $GOARCH or the host's")
+	flag.StringVar(&_optReport, "report", "", "write unsupported constructs to this json file")
 }
 
 var _structNamesMap = make(map[string]struct{}) // 键是所有 struct 类型名。
@@ -92,6 +106,9 @@ var _sigNamesMap = make(map[string]struct{})
 
 func main() {
 	flag.Parse()
+	if _optMode != modeFfi && _optMode != modeCgo {
+		log.Fatalf("invalid mode %q", _optMode)
+	}
 
 	if _optSyncGi {
 		err := syncLibGiToOut()
@@ -189,10 +206,34 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	if cfg.NumTrampolines <= 0 {
+		cfg.NumTrampolines = defaultNumTrampolines
+	}
+	if _optMinVersion != "" {
+		cfg.MinVersion = _optMinVersion
+	}
+	if cfg.MinVersion != "" {
+		if _, _, ok := parseVersion(cfg.MinVersion); !ok {
+			log.Fatalf("invalid min version %q", cfg.MinVersion)
+		}
+	}
 	_cfg = &cfg
 
+	// 先指定的目录优先
+	for i := len(_optGirDirs) - 1; i >= 0; i-- {
+		xmlp.PrependSearchPath(_optGirDirs[i])
+	}
+	if _optGirOnly {
+		gi.SetGirOnly()
+	}
+	if gi.GirOnly() {
+		goos, goarch := getTarget()
+		if err := gi.CheckLayoutTarget(goos, goarch); err != nil {
+			log.Fatal(err)
+		}
+	}
 	repo := gi.DefaultRepository()
-	_, err = repo.Require(_optNamespace, _optVersion, gi.REPOSITORY_LOAD_FLAG_LAZY)
+	err = repo.Require(_optNamespace, _optVersion, gi.REPOSITORY_LOAD_FLAG_LAZY)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -200,11 +241,19 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	_xRepo = xRepo
 
 	deps := getAllDeps(repo, _optNamespace)
 	log.Printf("deps: %#v\n", deps)
 	_deps = deps
 
+	if _optReport != "" {
+		_report = &nsReport{
+			Namespace: _optNamespace,
+			Version:   _optVersion,
+		}
+	}
+
 	sourceFile := NewSourceFile(pkg)
 	_sourceFile = sourceFile
 
@@ -222,6 +271,9 @@ func main() {
 	for _, pkg := range xRepo.Packages {
 		sourceFile.AddCPkg(pkg.Name)
 	}
+	if isCgoMode() {
+		pCgoInit(sourceFile)
+	}
 
 	sourceFile.AddGirImport("gi")
 	sourceFile.AddGoImport("unsafe")
@@ -235,6 +287,7 @@ func main() {
 	}
 	sourceFile.GoBody.Pn("var _ unsafe.Pointer")
 	sourceFile.GoBody.Pn("var _ *log.Logger")
+	pSince(sourceFile)
 	sourceFile.GoBody.Pn("func init() {")
 	sourceFile.GoBody.Pn("repo := gi.DefaultRepository()")
 	sourceFile.GoBody.Pn("_, err := repo.Require(%q, %q, gi.REPOSITORY_LOAD_FLAG_LAZY)",
@@ -258,6 +311,7 @@ func main() {
 
 	// 处理函数命名冲突
 	forEachFunctionInfo(repo, _optNamespace, handleFuncNameClash)
+	initDocSymbols(repo)
 	var constants []string
 
 	for idxLv1 := 0; idxLv1 < numInfos; idxLv1++ {
@@ -289,11 +343,11 @@ func main() {
 
 		case gi.INFO_TYPE_ENUM:
 			ei := gi.ToEnumInfo(bi)
-			pEnum(sourceFile, ei, true)
+			pEnum(sourceFile, ei, true, idxLv1)
 
 		case gi.INFO_TYPE_FLAGS:
 			ei := gi.ToEnumInfo(bi)
-			pEnum(sourceFile, ei, false)
+			pEnum(sourceFile, ei, false, idxLv1)
 
 		case gi.INFO_TYPE_CONSTANT:
 			ci := gi.ToConstantInfo(bi)
@@ -374,11 +428,21 @@ func main() {
 
 	log.Printf("stat %v TODO/ALL %d/%d %.2f%%\n", _optNamespace, _numTodoFunc, _numFunc,
 		float64(_numTodoFunc)/float64(_numFunc)*100)
+
+	if _report != nil {
+		_report.NumFunctions = _numFunc
+		_report.NumTodoFunctions = _numTodoFunc
+		err = saveReport(_optReport, _report)
+		if err != nil {
+			log.Fatal("failed to save report: ", err)
+		}
+	}
 }
 
-func pSignal(si *gi.SignalInfo) {
+func pSignal(s *SourceFile, si *gi.SignalInfo, container *gi.BaseInfo) {
 	name := si.Name()
 	_sigNamesMap[name] = struct{}{}
+	pSignalConnect(s, si, container)
 }
 
 func pSignalNameConstants(sf *SourceFile) {
@@ -424,46 +488,159 @@ func getFlagsTypeName(type0 string) string {
 	return type0 + "Flags"
 }
 
+// getEnumMemberName 返回枚举或标志类型 name 的成员 memberName 在生成的代码中的名称，
+// type0 是为类型生成的 Go 类型名。
+func getEnumMemberName(name, type0, memberName string) string {
+	result := name + snake2Camel(memberName)
+	if result == type0 {
+		// 成员和类型重名了
+		result += "0"
+	}
+	return result
+}
+
 func getEnumTypeName(type0 string) string {
 	return type0 + "Enum"
 }
 
-func pEnum(s *SourceFile, ei *gi.EnumInfo, isEnum bool) {
-	if ei.IsDeprecated() {
-		markDeprecated(s)
-	}
+func pEnum(s *SourceFile, ei *gi.EnumInfo, isEnum bool, idxLv1 int) {
 	name := ei.Name()
+	kind := "enum"
+	if !isEnum {
+		kind = "flags"
+	}
+	if pSkipNewer(s, kind, name, getTypeSince(name)) {
+		return
+	}
 	var type0 string
 	if isEnum {
 		s.GoBody.Pn("// Enum %v", name)
+		pTypeDoc(s, name)
+		if ei.IsDeprecated() {
+			pTypeDeprecated(s, name)
+		}
 		type0 = getEnumTypeName(name)
 	} else {
 		// is Flags
 		s.GoBody.Pn("// Flags %v", name)
+		pTypeDoc(s, name)
+		if ei.IsDeprecated() {
+			pTypeDeprecated(s, name)
+		}
 		type0 = getFlagsTypeName(name)
 	}
 	s.GoBody.Pn("type %s int", type0)
 	s.GoBody.Pn("const (")
 	num := ei.NumValue()
+	var memberNames []string
+	var nicks []string
+	var vals []int64
+	xEnum := getEnumDoc(name)
 	for i := 0; i < num; i++ {
 		value := ei.Value(i)
 		val := value.Value()
-		memberName := name + snake2Camel(value.Name())
-		if memberName == type0 {
-			// 成员和类型重名了
-			memberName += "0"
-		}
+		memberName := getEnumMemberName(name, type0, value.Name())
 		s.GoBody.Pn("%s %s = %v", memberName, type0, val)
+		memberNames = append(memberNames, memberName)
+		nicks = append(nicks, getEnumValueNick(xEnum, value.Name()))
+		vals = append(vals, val)
 		value.Unref()
 	}
 	s.GoBody.Pn(")") // end const
 
+	pEnumHelpers(s, type0, isEnum, memberNames, nicks, vals)
+
+	if isEnum {
+		if domain := ei.ErrorDomain(); domain != "" {
+			pErrorDomain(s, type0, domain)
+		}
+	}
+
 	// NOTE: enum 和 flags 也有类型的
 	pGetTypeFunc(s, name)
+
+	// 枚举的方法，比如错误域的 quark 函数
+	numMethod := ei.NumMethod()
+	for idxLv2 := 0; idxLv2 < numMethod; idxLv2++ {
+		fi := ei.Method(idxLv2)
+		pFunction(s, fi, idxLv1, idxLv2)
+	}
+}
+
+// getEnumValueNick 返回枚举值 name 在 GIR 中的 glib:nick，xEnum 可以为 nil。
+// GIR 中没有 nick 时才把名字转换为 nick，比如 not_found => not-found
+func getEnumValueNick(xEnum *xmlp.EnumInfo, name string) string {
+	if xEnum != nil {
+		for _, member := range xEnum.Members {
+			if member.Name == name && member.GlibNick != "" {
+				return member.GlibNick
+			}
+		}
+	}
+	return strings.Replace(name, "_", "-", -1)
+}
+
+// pEnumHelpers 为枚举或标志类型 type0 生成 String, ParseXXX 和 XXXValues，
+// 标志类型还有 Has, Set 和 Clear 方法。
+func pEnumHelpers(s *SourceFile, type0 string, isEnum bool, memberNames, nicks []string,
+	vals []int64) {
+	varValues := "_" + type0 + "Values"
+	s.GoBody.Pn("var %v = []gi.EnumValue{", varValues)
+	for i, nick := range nicks {
+		s.GoBody.Pn("{Value: %v, Nick: %q},", vals[i], nick)
+	}
+	s.GoBody.Pn("}") // end var
+
+	kind := "Flags"
+	if isEnum {
+		kind = "Enum"
+	}
+	s.GoBody.Pn("func (v %v) String() string {", type0)
+	s.GoBody.Pn("return gi.%vString(%v, %q, int(v))", kind, varValues, type0)
+	s.GoBody.Pn("}")
+
+	if isEnum {
+		s.GoBody.Pn("// Parse%v 根据 nick 解析 %v 的值", type0, type0)
+	} else {
+		s.GoBody.Pn("// Parse%v 解析 a|b|c 形式的 %v 的值，a, b, c 是 nick", type0, type0)
+	}
+	s.GoBody.Pn("func Parse%v(str string) (%v, error) {", type0, type0)
+	s.GoBody.Pn("v, err := gi.Parse%v(%v, %q, str)", kind, varValues, type0)
+	s.GoBody.Pn("return %v(v), err", type0)
+	s.GoBody.Pn("}")
+
+	s.GoBody.Pn("// %vValues 返回 %v 的所有值", type0, type0)
+	s.GoBody.Pn("func %vValues() []%v {", type0, type0)
+	s.GoBody.Pn("return []%v{%v}", type0, strings.Join(memberNames, ", "))
+	s.GoBody.Pn("}")
+
+	if !isEnum {
+		s.GoBody.Pn("// Has 判断 v 是否包含 flags 中所有的位")
+		s.GoBody.Pn("func (v %v) Has(flags %v) bool { return v&flags == flags }", type0, type0)
+		s.GoBody.Pn("// Set 设置 flags 中的位")
+		s.GoBody.Pn("func (v *%v) Set(flags %v) { *v |= flags }", type0, type0)
+		s.GoBody.Pn("// Clear 清除 flags 中的位")
+		s.GoBody.Pn("func (v *%v) Clear(flags %v) { *v &^= flags }", type0, type0)
+	}
+}
+
+// pErrorDomain 为带有错误域的枚举类型生成错误域常量，并实现 gi.GErrorCode 接口，
+// 这样枚举值就可以用在 errors.Is 和 errors.As 中与 *gi.GError 比较。
+func pErrorDomain(s *SourceFile, type0, domain string) {
+	s.GoBody.Pn("// %vDomain 是 %v 的错误域", type0, type0)
+	s.GoBody.Pn("const %vDomain = %q", type0, domain)
+	s.GoBody.Pn("func (v %v) Error() string {", type0)
+	s.GoBody.Pn("return (&gi.GError{DomainName: %vDomain, Code: int(v)}).Error()", type0)
+	s.GoBody.Pn("}")
+	s.GoBody.Pn("func (v %v) GErrorDomain() string { return %vDomain }", type0, type0)
+	s.GoBody.Pn("func (v %v) GErrorCode() int { return int(v) }", type0)
 }
 
 func pStruct(s *SourceFile, si *gi.StructInfo, idxLv1 int) {
 	name := si.Name()
+	if pSkipNewer(s, "struct", name, getTypeSince(name)) {
+		return
+	}
 
 	numMethods := si.NumMethod()
 	if si.IsGTypeStruct() {
@@ -488,13 +665,17 @@ func pStruct(s *SourceFile, si *gi.StructInfo, idxLv1 int) {
 		}
 	}
 
+	boxed := si.GetGType().IsBoxed()
+	s.GoBody.Pn("// Struct %s", name)
+	pTypeDoc(s, name)
 	if si.IsDeprecated() {
-		markDeprecated(s)
+		pTypeDeprecated(s, name)
 	}
-
-	s.GoBody.Pn("// Struct %s", name)
 	s.GoBody.Pn("type %s struct {", name)
 	s.GoBody.Pn("    P unsafe.Pointer")
+	if boxed {
+		pOwnRefField(s)
+	}
 	s.GoBody.Pn("}")
 
 	size := si.Size()
@@ -503,6 +684,19 @@ func pStruct(s *SourceFile, si *gi.StructInfo, idxLv1 int) {
 	}
 
 	pGetTypeFunc(s, name)
+	if boxed {
+		pBoxedFuncs(s, name, si)
+	}
+	if fields := getScalarStructFields(si); fields != nil {
+		pStructValue(s, name, fields)
+	}
+
+	numField := si.NumField()
+	for i := 0; i < numField; i++ {
+		fi := si.Field(i)
+		pField(s, fi, name)
+		fi.Unref()
+	}
 
 	for idxLv2 := 0; idxLv2 < numMethods; idxLv2++ {
 		fi := si.Method(idxLv2)
@@ -529,13 +723,21 @@ func pGetTypeFunc(s *SourceFile, name string) {
 }
 
 func pUnion(s *SourceFile, ui *gi.UnionInfo, idxLv1 int) {
-	if ui.IsDeprecated() {
-		markDeprecated(s)
-	}
 	name := ui.Name()
+	if pSkipNewer(s, "union", name, getTypeSince(name)) {
+		return
+	}
+	boxed := ui.GetGType().IsBoxed()
 	s.GoBody.Pn("// Union %s", name)
+	pTypeDoc(s, name)
+	if ui.IsDeprecated() {
+		pTypeDeprecated(s, name)
+	}
 	s.GoBody.Pn("type %s struct {", name)
 	s.GoBody.Pn("    P unsafe.Pointer")
+	if boxed {
+		pOwnRefField(s)
+	}
 	s.GoBody.Pn("}")
 
 	size := ui.Size()
@@ -544,6 +746,16 @@ func pUnion(s *SourceFile, ui *gi.UnionInfo, idxLv1 int) {
 	}
 
 	pGetTypeFunc(s, name)
+	if boxed {
+		pBoxedFuncs(s, name, ui)
+	}
+
+	numField := ui.NumField()
+	for i := 0; i < numField; i++ {
+		fi := ui.Field(i)
+		pField(s, &fi, name)
+		fi.Unref()
+	}
 
 	numMethod := ui.NumMethod()
 	for idxLv2 := 0; idxLv2 < numMethod; idxLv2++ {
@@ -553,14 +765,19 @@ func pUnion(s *SourceFile, ui *gi.UnionInfo, idxLv1 int) {
 }
 
 func pInterface(s *SourceFile, ii *gi.InterfaceInfo, idxLv1 int) {
-	if ii.IsDeprecated() {
-		markDeprecated(s)
-	}
 	name := ii.Name()
+	if pSkipNewer(s, "interface", name, getTypeSince(name)) {
+		return
+	}
 	s.GoBody.Pn("// Interface %s", name)
+	pTypeDoc(s, name)
+	if ii.IsDeprecated() {
+		pTypeDeprecated(s, name)
+	}
 	s.GoBody.Pn("type %s struct {", name)
 	s.GoBody.Pn("    %sIfc", name)
 	s.GoBody.Pn("    P unsafe.Pointer")
+	pOwnRefField(s)
 	s.GoBody.Pn("}") // end struct
 
 	s.GoBody.Pn("type %sIfc struct{}", name)
@@ -580,7 +797,23 @@ func pInterface(s *SourceFile, ii *gi.InterfaceInfo, idxLv1 int) {
 	numSig := ii.NumSignal()
 	for i := 0; i < numSig; i++ {
 		si := ii.Signal(i)
-		pSignal(si)
+		pSignal(s, si, gi.ToBaseInfo(ii))
+		si.Unref()
+	}
+
+	numProp := ii.NumProperty()
+	for i := 0; i < numProp; i++ {
+		pi := ii.Property(i)
+		pProperty(s, pi, gi.ToBaseInfo(ii))
+		pi.Unref()
+	}
+}
+
+// pOwnRefField 在所有权模式下给根对象、接口和 boxed 类型的结构体加上 OwnRef 字段，
+// 必须放在 P 字段后面，嵌入的 XxxIfc 结构体的方法依赖 P 在结构体的开头。
+func pOwnRefField(s *SourceFile) {
+	if _cfg.Ownership {
+		s.GoBody.Pn("    OwnRef *gi.OwnedRef")
 	}
 }
 
@@ -607,10 +840,14 @@ func isParentImplIfc(oi *gi.ObjectInfo, ifcInfo *gi.InterfaceInfo) bool {
 
 func pObject(s *SourceFile, oi *gi.ObjectInfo, idxLv1 int) {
 	name := oi.Name()
-	if oi.IsDeprecated() {
-		markDeprecated(s)
+	if pSkipNewer(s, "object", name, getTypeSince(name)) {
+		return
 	}
 	s.GoBody.Pn("// Object %s", name)
+	pTypeDoc(s, name)
+	if oi.IsDeprecated() {
+		pTypeDeprecated(s, name)
+	}
 	s.GoBody.Pn("type %s struct {", name)
 
 	var embeddedIfcs []string
@@ -619,8 +856,8 @@ func pObject(s *SourceFile, oi *gi.ObjectInfo, idxLv1 int) {
 	for i := 0; i < numIfcs; i++ {
 		ii := oi.Interface(i)
 
-		// 如果父类型没有实现此接口，才嵌入它
-		if !isParentImplIfc(oi, ii) {
+		// 如果父类型没有实现此接口，才嵌入它，比最低版本新的接口没有生成，也不嵌入
+		if !isParentImplIfc(oi, ii) && !isNewerIfc(ii) {
 			typeName := getTypeName(gi.ToBaseInfo(ii))
 			s.GoBody.Pn("%sIfc", typeName)
 			embeddedIfcs = append(embeddedIfcs, ii.Name())
@@ -637,6 +874,7 @@ func pObject(s *SourceFile, oi *gi.ObjectInfo, idxLv1 int) {
 		parent.Unref()
 	} else {
 		s.GoBody.Pn("P unsafe.Pointer")
+		pOwnRefField(s)
 	}
 
 	s.GoBody.Pn("}") // end struct
@@ -664,8 +902,43 @@ func pObject(s *SourceFile, oi *gi.ObjectInfo, idxLv1 int) {
 	numSig := oi.NumSignal()
 	for i := 0; i < numSig; i++ {
 		si := oi.Signal(i)
-		pSignal(si)
+		pSignal(s, si, gi.ToBaseInfo(oi))
+		si.Unref()
+	}
+
+	numProp := oi.NumProperty()
+	for i := 0; i < numProp; i++ {
+		pi := oi.Property(i)
+		pProperty(s, pi, gi.ToBaseInfo(oi))
+		pi.Unref()
+	}
+
+	pVFuncs(s, oi)
+}
+
+// getTarget 返回生成的代码的目标平台，GOARCH 可以用 -arch 参数指定，
+// 否则和 GOOS 一样先取环境变量，再取 girgen 运行的平台。
+func getTarget() (goos, goarch string) {
+	goos = os.Getenv("GOOS")
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	goarch = _optArch
+	if goarch == "" {
+		goarch = os.Getenv("GOARCH")
+	}
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+	return
+}
+
+// isNewerIfc 返回当前命名空间中的接口 ii 是否比配置的最低版本新
+func isNewerIfc(ii *gi.InterfaceInfo) bool {
+	if ii.Namespace() != _optNamespace {
+		return false
 	}
+	return isNewerThanMinVersion(getTypeSince(ii.Name()))
 }
 
 func forEachFunctionInfo(repo *gi.Repository, namespace string, fn func(fi *gi.FunctionInfo)) {
@@ -708,6 +981,14 @@ func forEachFunctionInfo(repo *gi.Repository, namespace string, fn func(fi *gi.F
 				fn(fi)
 				fi.Unref()
 			}
+		case gi.INFO_TYPE_ENUM, gi.INFO_TYPE_FLAGS:
+			ei := gi.ToEnumInfo(bi)
+			numMethods := ei.NumMethod()
+			for i := 0; i < numMethods; i++ {
+				fi := ei.Method(i)
+				fn(fi)
+				fi.Unref()
+			}
 		}
 		bi.Unref()
 	}
@@ -0,0 +1,239 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
+)
+
+// getOwnershipAssign 返回所有权模式下生成赋值语句的函数，生成的语句把实例的指针 ptrExpr 赋给 varResult，
+// 并且让 varResult 的 OwnRef 字段接管实例的一个引用，type0 是 varResult 的类型。
+// bi 不是 GObject 对象或接口，不是有 ref 和 unref 函数的基本类型，也不是 boxed 类型时，返回 nil。
+func getOwnershipAssign(bi *gi.BaseInfo, type0 string, transfer gi.Transfer) func(varResult, ptrExpr string) []string {
+	transferFull := transfer != gi.TRANSFER_NOTHING
+	switch bi.Type() {
+	case gi.INFO_TYPE_STRUCT, gi.INFO_TYPE_UNION, gi.INFO_TYPE_BOXED:
+		if !gi.ToRegisteredTypeInfo(bi).GetGType().IsBoxed() {
+			return nil
+		}
+		// 产生类似如下代码：
+		// result.P, result.OwnRef = gi.AdoptBoxed(ret.Pointer(), RGBAGetType(), false)
+		return func(varResult, ptrExpr string) []string {
+			return []string{fmt.Sprintf("%v.P, %v.OwnRef = gi.AdoptBoxed(%v, %vGetType(), %v)",
+				varResult, varResult, ptrExpr, type0, transferFull)}
+		}
+	}
+
+	adoptExpr := getOwnershipAdoptExpr(bi, transferFull)
+	if adoptExpr == "" {
+		return nil
+	}
+	// 产生类似如下代码：
+	// result.P = ret.Pointer()
+	// result.OwnRef = gi.AdoptObject(result.P, true)
+	return func(varResult, ptrExpr string) []string {
+		return []string{
+			fmt.Sprintf("%v.P = %v", varResult, ptrExpr),
+			fmt.Sprintf("%v.OwnRef = %v", varResult, fmt.Sprintf(adoptExpr, varResult+".P")),
+		}
+	}
+}
+
+// getOwnershipAdoptExpr 返回接管对象或接口实例 bi 的一个引用的表达式，
+// 其中 %v 是实例的指针，比如 gi.AdoptObject(%v, true)。
+// bi 不是 GObject 对象或接口，也不是有 ref 和 unref 函数的基本类型时，返回空字符串。
+func getOwnershipAdoptExpr(bi *gi.BaseInfo, transferFull bool) string {
+	switch bi.Type() {
+	case gi.INFO_TYPE_INTERFACE:
+		return fmt.Sprintf("gi.AdoptObject(%%v, %v)", transferFull)
+	case gi.INFO_TYPE_OBJECT:
+		// 沿着继承关系向上找，直到 GObject.Object 或者有 ref 函数的基本类型
+		oi := gi.ToObjectInfo(bi)
+		cur := oi
+		defer func() {
+			if cur != nil && cur != oi {
+				cur.Unref()
+			}
+		}()
+		for cur != nil {
+			if cur.Namespace() == "GObject" && cur.Name() == "Object" {
+				return fmt.Sprintf("gi.AdoptObject(%%v, %v)", transferFull)
+			}
+			if cur.RefFunction() != "" && cur.UnrefFunction() != "" {
+				return fmt.Sprintf("gi.AdoptFundamental(%%v, %q, %q, %v)",
+					bi.Namespace(), bi.Name(), transferFull)
+			}
+			parent := cur.Parent()
+			if cur != oi {
+				cur.Unref()
+			}
+			cur = parent
+		}
+	}
+	return ""
+}
+
+// getKeepAliveLine 返回所有权模式下在调用 C 函数之后保持 varName 存活的语句，防止它的 OwnRef
+// 在调用过程中被垃圾回收，提前释放了 C 函数正在使用的实例。bi 是 varName 的类型，
+// byRef 为 true 表示 varName 是 *XxxIfc 或 IXxx 类型的，它本身就引用着 OwnRef。
+// 不是所有权模式，或者 bi 的结构体没有 OwnRef 字段时返回空字符串。
+func getKeepAliveLine(s *SourceFile, bi *gi.BaseInfo, varName string, byRef bool) string {
+	if _cfg == nil || !_cfg.Ownership {
+		return ""
+	}
+	switch bi.Type() {
+	case gi.INFO_TYPE_OBJECT, gi.INFO_TYPE_INTERFACE:
+	case gi.INFO_TYPE_STRUCT, gi.INFO_TYPE_UNION:
+		if !gi.ToRegisteredTypeInfo(bi).GetGType().IsBoxed() {
+			return ""
+		}
+	default:
+		return ""
+	}
+	s.AddGoImport("runtime")
+	if byRef {
+		return fmt.Sprintf("runtime.KeepAlive(%v)", varName)
+	}
+	return fmt.Sprintf("runtime.KeepAlive(%v.OwnRef)", varName)
+}
+
+// getArgKeepAliveLine 和 getKeepAliveLine 类似，用于类型为 ti 的参数 varName，
+// 对象和接口类型的参数是 IXxx 接口，boxed 类型的参数是结构体。
+func getArgKeepAliveLine(s *SourceFile, ti *gi.TypeInfo, varName string) string {
+	if ti.Tag() != gi.TYPE_TAG_INTERFACE || !ti.IsPointer() {
+		return ""
+	}
+	bi := ti.Interface()
+	defer bi.Unref()
+	biType := bi.Type()
+	byRef := biType == gi.INFO_TYPE_OBJECT || biType == gi.INFO_TYPE_INTERFACE
+	return getKeepAliveLine(s, bi, varName, byRef)
+}
+
+// isGObjectType 判断 bi 是否为接口或者从 GObject.Object 派生的对象
+func isGObjectType(bi *gi.BaseInfo) bool {
+	switch bi.Type() {
+	case gi.INFO_TYPE_INTERFACE:
+		return true
+	case gi.INFO_TYPE_OBJECT:
+		oi := gi.ToObjectInfo(bi)
+		cur := oi
+		for cur != nil {
+			if cur.Namespace() == "GObject" && cur.Name() == "Object" {
+				if cur != oi {
+					cur.Unref()
+				}
+				return true
+			}
+			parent := cur.Parent()
+			if cur != oi {
+				cur.Unref()
+			}
+			cur = parent
+		}
+	}
+	return false
+}
+
+type methodFinder interface {
+	FindMethod(name string) *gi.FunctionInfo
+}
+
+func hasMethod(mf methodFinder, name string) bool {
+	fi := mf.FindMethod(name)
+	if fi == nil {
+		return false
+	}
+	fi.Unref()
+	return true
+}
+
+// isBoxedOwnMethod 判断在所有权模式下 fi 是否为 boxed 类型 container 自带的没有其他参数的方法 name，
+// 比如 copy 和 free。
+func isBoxedOwnMethod(fi *gi.FunctionInfo, container *gi.BaseInfo, name string) bool {
+	if _cfg == nil || !_cfg.Ownership || container == nil || fi.Name() != name {
+		return false
+	}
+	switch container.Type() {
+	case gi.INFO_TYPE_STRUCT, gi.INFO_TYPE_UNION:
+		if !gi.ToRegisteredTypeInfo(container).GetGType().IsBoxed() {
+			return false
+		}
+	default:
+		return false
+	}
+	return fi.Flags()&gi.FUNCTION_IS_METHOD != 0 && fi.NumArg() == 0
+}
+
+// getBoxedFreeLines 返回所有权模式下 boxed 类型自带的 free 方法 fi 开头的语句，varV 是接收者。
+// v 的 OwnRef 不为 nil 时通过它释放，之后垃圾回收时就不会再释放一次。fi 不是 boxed 类型的 free 方法时返回 nil。
+func getBoxedFreeLines(fi *gi.FunctionInfo, container *gi.BaseInfo, varV string) []string {
+	if !isBoxedOwnMethod(fi, container, "free") {
+		return nil
+	}
+	retType := fi.ReturnType()
+	defer retType.Unref()
+	if retType.Tag() != gi.TYPE_TAG_VOID || retType.IsPointer() {
+		return nil
+	}
+	return []string{
+		fmt.Sprintf("if %v.OwnRef != nil {", varV),
+		fmt.Sprintf("%v.OwnRef.Release()", varV),
+		"return",
+		"}",
+	}
+}
+
+// pBoxedFuncs 给 boxed 类型 name 生成 Copy 和 Free 方法，已经有同名的方法时跳过。
+// 所有权模式下 Copy 返回的值被垃圾回收时自动释放，Free 则提前释放它。
+// 已有的 copy 方法返回的复制品总是由调用者拥有，所有权模式下用 gi.AdoptBoxed 接管；
+// 已有的 free 方法见 getBoxedFreeLines。
+func pBoxedFuncs(s *SourceFile, name string, mf methodFinder) {
+	if !hasMethod(mf, "copy") {
+		if _cfg.Ownership {
+			s.GoBody.Pn("// Copy 用 g_boxed_copy 复制一份 v，返回值被垃圾回收时自动释放，也可以用 Free 提前释放。")
+		} else {
+			s.GoBody.Pn("// Copy 用 g_boxed_copy 复制一份 v，不再使用时需要用 Free 释放返回值。")
+		}
+		s.GoBody.Pn("func (v %v) Copy() (result %v) {", name, name)
+		if _cfg.Ownership {
+			s.GoBody.Pn("result.P, result.OwnRef = gi.AdoptBoxed(v.P, %vGetType(), false)", name)
+		} else {
+			s.GoBody.Pn("result.P = gi.BoxedCopy(%vGetType(), v.P)", name)
+		}
+		s.GoBody.Pn("return }")
+	}
+
+	if !hasMethod(mf, "free") {
+		s.GoBody.Pn("// Free 用 g_boxed_free 释放 v。")
+		s.GoBody.Pn("func (v %v) Free() {", name)
+		if _cfg.Ownership {
+			s.GoBody.Pn("if v.OwnRef != nil {")
+			s.GoBody.Pn("v.OwnRef.Release()")
+			s.GoBody.Pn("return }")
+		}
+		s.GoBody.Pn("gi.BoxedFree(%vGetType(), v.P)", name)
+		s.GoBody.Pn("}")
+	}
+}
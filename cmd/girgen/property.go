@@ -0,0 +1,220 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
+)
+
+// getPropertyName 把属性名转换为方法名中的部分，比如 has-default => HasDefault
+func getPropertyName(name string) string {
+	return toCamelCase(strings.Replace(name, "_", "-", -1), "-")
+}
+
+// isPropertyTypeSupported 检查属性的类型是否能被 parseRetType 和 parseArgTypeDirIn 处理。
+func isPropertyTypeSupported(ti *gi.TypeInfo) bool {
+	switch ti.Tag() {
+	case gi.TYPE_TAG_ARRAY:
+		// 属性中的数组没有长度参数，只支持以零结尾的。
+		if ti.ArrayLength() >= 0 || !ti.IsZeroTerminated() {
+			return false
+		}
+	case gi.TYPE_TAG_INTERFACE:
+		bi := ti.Interface()
+		biType := bi.Type()
+		bi.Unref()
+		if biType == gi.INFO_TYPE_CALLBACK {
+			return false
+		}
+	case gi.TYPE_TAG_ERROR:
+		return false
+	}
+	return true
+}
+
+// getPropertyGetTransfer 返回用 g_object_get 获取类型为 ti 的属性 pi 的值时调用者得到的所有权，它由属性的 GValue 类型决定。
+// 字符串、字符串数组（G_TYPE_STRV）、boxed 类型和 GVariant 等的值是复制过或者增加了引用的，调用者拥有它们；
+// G_TYPE_POINTER 类型的值，比如 GList、GSList、其他 C 数组和不是 boxed 的结构体，不会被复制，调用者不拥有它们。
+// GObject 对象增加的引用在属性的所有权不转移时已经由 GetPropertyArgument 去掉，所以按照属性的 transfer 处理。
+func getPropertyGetTransfer(pi *gi.PropertyInfo, ti *gi.TypeInfo) gi.Transfer {
+	if !ti.IsPointer() {
+		// 数值、枚举等
+		return gi.TRANSFER_NOTHING
+	}
+	switch ti.Tag() {
+	case gi.TYPE_TAG_VOID, gi.TYPE_TAG_GLIST, gi.TYPE_TAG_GSLIST:
+		return gi.TRANSFER_NOTHING
+	case gi.TYPE_TAG_ARRAY:
+		if ti.ArrayType() == gi.ARRAY_TYPE_C {
+			elemType := ti.ParamType(0)
+			elemTag := elemType.Tag()
+			elemType.Unref()
+			if elemTag != gi.TYPE_TAG_UTF8 && elemTag != gi.TYPE_TAG_FILENAME {
+				return gi.TRANSFER_NOTHING
+			}
+		}
+	case gi.TYPE_TAG_INTERFACE:
+		bi := ti.Interface()
+		defer bi.Unref()
+		if isGObjectType(bi) {
+			return pi.OwnershipTransfer()
+		}
+		switch bi.Type() {
+		case gi.INFO_TYPE_STRUCT, gi.INFO_TYPE_UNION:
+			if !isBoxedType(ti) {
+				return gi.TRANSFER_NOTHING
+			}
+		}
+	}
+	return gi.TRANSFER_EVERYTHING
+}
+
+// isBoxedType 判断 ti 是否为 boxed 类型的结构体或联合体
+func isBoxedType(ti *gi.TypeInfo) bool {
+	if ti.Tag() != gi.TYPE_TAG_INTERFACE {
+		return false
+	}
+	bi := ti.Interface()
+	defer bi.Unref()
+	switch bi.Type() {
+	case gi.INFO_TYPE_STRUCT, gi.INFO_TYPE_UNION:
+		return gi.ToRegisteredTypeInfo(bi).GetGType().IsBoxed()
+	}
+	return false
+}
+
+// pProperty 为属性 pi 生成 GetPropXXX 和 SetPropXXX 方法，container 是属性所在的对象或接口。
+func pProperty(s *SourceFile, pi *gi.PropertyInfo, container *gi.BaseInfo) {
+	propName := pi.Name()
+	flags := pi.Flags()
+	ti := pi.Type()
+	defer ti.Unref()
+
+	if pi.IsDeprecated() && _cfg.SkipDeprecated {
+		s.GoBody.Pn("// deprecated property %s.%s\n", container.Name(), propName)
+		return
+	}
+	if pSkipNewer(s, "property", container.Name()+"."+propName, getPropertySince(container.Name(), propName)) {
+		return
+	}
+
+	cSymbol := getCIdentifierPrefix(container) + container.Name() + ":" + propName
+	if !isPropertyTypeSupported(ti) {
+		s.GoBody.Pn("// TODO: property %s.%s, tag: %v, isPtr: %v\n",
+			container.Name(), propName, ti.Tag(), ti.IsPointer())
+		_report.add(newReportEntry("property", cSymbol, -1, "", "", ti),
+			fmt.Sprintf("unsupported property type, isPtr: %v", ti.IsPointer()))
+		return
+	}
+
+	isContainerIfc := container.Type() == gi.INFO_TYPE_INTERFACE
+	receiverType := container.Name()
+	if isContainerIfc {
+		receiverType = "*" + receiverType + "Ifc"
+	}
+	name := getPropertyName(propName)
+	prefix := getPkgPrefix("GObject")
+
+	if flags&gi.PARAM_READABLE != 0 {
+		var varReg VarReg
+		varV := varReg.alloc("v")
+		getPtrExpr := varV + ".P"
+		if isContainerIfc {
+			getPtrExpr = fmt.Sprintf("*(*unsafe.Pointer)(unsafe.Pointer(%v))", varV)
+		}
+		varRet := varReg.alloc("ret")
+		varResult := varReg.alloc("result")
+		retTransfer := getPropertyGetTransfer(pi, ti)
+		parseResult := parseRetType(varRet, ti, &varReg, nil, retTransfer)
+		_report.addTodoType(newReportEntry("property", cSymbol, -1, "", dirReturn, ti),
+			parseResult.type0)
+
+		if !strings.Contains(parseResult.type0, "TODO") {
+			s.GoBody.Pn("// GetProp%s 获取属性 %q 的值", name, propName)
+			s.GoBody.Pn("//")
+			s.GoBody.Pn("// [ %v ] trans: %v", varResult, retTransfer)
+			if !_cfg.Ownership && retTransfer == gi.TRANSFER_EVERYTHING && isBoxedType(ti) {
+				s.GoBody.Pn("//")
+				s.GoBody.Pn("// 返回值是 g_object_get 复制出来的，不再使用时需要用 Free 释放。")
+			}
+			if pi.IsDeprecated() {
+				pPropertyDeprecated(s, container.Name(), propName)
+			}
+			s.GoBody.Pn("func (%v %v) GetProp%v() (%v %v) {", varV, receiverType, name,
+				varResult, parseResult.type0)
+			s.GoBody.Pn("var %v gi.Argument", varRet)
+			transferNothing := pi.OwnershipTransfer() == gi.TRANSFER_NOTHING
+			s.GoBody.Pn("%vGetPropertyArgument(%v, %q, &%v, %v)", prefix, getPtrExpr, propName,
+				varRet, transferNothing)
+			if line := getKeepAliveLine(s, container, varV, isContainerIfc); line != "" {
+				s.GoBody.Pn(line)
+			}
+			for _, line := range parseResult.assignLines(varResult) {
+				s.GoBody.Pn(line)
+			}
+			s.GoBody.Pn("return")
+			s.GoBody.Pn("}") // end func
+		}
+	}
+
+	// construct-only 的属性只能在构造对象时设置
+	if flags&gi.PARAM_WRITABLE != 0 && flags&gi.PARAM_CONSTRUCT_ONLY == 0 {
+		var varReg VarReg
+		varV := varReg.alloc("v")
+		getPtrExpr := varV + ".P"
+		if isContainerIfc {
+			getPtrExpr = fmt.Sprintf("*(*unsafe.Pointer)(unsafe.Pointer(%v))", varV)
+		}
+		varValue := varReg.alloc("value")
+		parseResult := parseArgTypeDirIn(varValue, ti, &varReg, gi.TRANSFER_NOTHING)
+		_report.addTodoType(newReportEntry("property", cSymbol, -1, "", gi.DIRECTION_IN.String(), ti),
+			parseResult.type0)
+
+		if !strings.Contains(parseResult.type0, "TODO") {
+			s.GoBody.Pn("// SetProp%s 设置属性 %q 的值", name, propName)
+			if pi.IsDeprecated() {
+				pPropertyDeprecated(s, container.Name(), propName)
+			}
+			s.GoBody.Pn("func (%v %v) SetProp%v(%v %v) {", varV, receiverType, name,
+				varValue, parseResult.type0)
+			for _, line := range parseResult.beforeArgLines {
+				s.GoBody.Pn(line)
+			}
+			varArg := varReg.alloc("arg")
+			s.GoBody.Pn("%v := %v", varArg, parseResult.newArgExpr)
+			s.GoBody.Pn("%vSetPropertyArgument(%v, %q, %v)", prefix, getPtrExpr, propName, varArg)
+			if line := getKeepAliveLine(s, container, varV, isContainerIfc); line != "" {
+				s.GoBody.Pn(line)
+			}
+			if line := getArgKeepAliveLine(s, ti, varValue); line != "" {
+				s.GoBody.Pn(line)
+			}
+			for _, line := range parseResult.afterCallLines {
+				s.GoBody.Pn(line)
+			}
+			s.GoBody.Pn("}") // end func
+		}
+	}
+}
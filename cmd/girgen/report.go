@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
+)
+
+// reportFile 是 -report 参数指定的报告文件的内容，多次运行 girgen 时写入同一个文件，
+// 每个命名空间的结果分别保存。
+type reportFile struct {
+	Namespaces map[string]*nsReport `json:"namespaces"`
+}
+
+// nsReport 记录一个命名空间中所有生成了 TODO 代码的地方
+type nsReport struct {
+	Namespace        string         `json:"namespace"`
+	Version          string         `json:"version"`
+	NumFunctions     int            `json:"numFunctions"`
+	NumTodoFunctions int            `json:"numTodoFunctions"`
+	Entries          []*reportEntry `json:"entries"`
+	Summary          []reasonCount  `json:"summary"`
+}
+
+type reportEntry struct {
+	// function, callback, field, property, signal 或 vfunc
+	Kind string `json:"kind"`
+	// C 符号，函数是函数名，回调是类型名，字段是 Type.field，属性是 Type:prop，
+	// 信号是 Type::signal，虚函数是 Type.vfunc
+	Symbol string `json:"symbol"`
+	// 参数的 index，不包括方法的实例参数，返回值和没有参数的情况为 -1
+	ArgIndex  int    `json:"argIndex"`
+	ArgName   string `json:"argName,omitempty"`
+	Direction string `json:"direction,omitempty"` // in, out, inout 或 return
+	TypeTag   string `json:"typeTag,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+type reasonCount struct {
+	Reason string `json:"reason"`
+	Count  int    `json:"count"`
+}
+
+// 没有 -report 参数时为 nil
+var _report *nsReport
+
+const dirReturn = "return"
+
+func newReportEntry(kind, symbol string, argIdx int, argName, dir string, ti *gi.TypeInfo) *reportEntry {
+	e := &reportEntry{
+		Kind:      kind,
+		Symbol:    symbol,
+		ArgIndex:  argIdx,
+		ArgName:   argName,
+		Direction: dir,
+	}
+	if ti != nil {
+		e.TypeTag = ti.Tag().String()
+	}
+	return e
+}
+
+// add 记录一条，reason 为空时用 e.Reason。
+func (r *nsReport) add(e *reportEntry, reason string) {
+	if r == nil {
+		return
+	}
+	if reason != "" {
+		e.Reason = reason
+	}
+	r.Entries = append(r.Entries, e)
+}
+
+var _regTodoComment = regexp.MustCompile(`/\*\s*TODO(?:_TYPE|_CB|:TYPE)?:?\s*(.*?)\s*\*/`)
+
+// addTodoType 如果生成的类型或表达式 code 中有 TODO 标记，就记录一条，原因取自 TODO 注释。
+func (r *nsReport) addTodoType(e *reportEntry, code string) {
+	if r == nil {
+		return
+	}
+	match := _regTodoComment.FindStringSubmatch(code)
+	if match == nil {
+		return
+	}
+	reason := match[1]
+	if reason == "" {
+		reason = "unknown type"
+	}
+	r.add(e, reason)
+}
+
+// summarize 按原因统计条目数，数量多的在前。
+func (r *nsReport) summarize() {
+	counts := make(map[string]int)
+	for _, e := range r.Entries {
+		counts[e.Reason]++
+	}
+	r.Summary = make([]reasonCount, 0, len(counts))
+	for reason, count := range counts {
+		r.Summary = append(r.Summary, reasonCount{Reason: reason, Count: count})
+	}
+	sort.Slice(r.Summary, func(i, j int) bool {
+		a, b := r.Summary[i], r.Summary[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Reason < b.Reason
+	})
+}
+
+// saveReport 把 r 合并到报告文件 filename 中，替换同名命名空间之前的结果。
+func saveReport(filename string, r *nsReport) error {
+	var rf reportFile
+	data, err := ioutil.ReadFile(filename)
+	if err == nil {
+		err = json.Unmarshal(data, &rf)
+		if err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if rf.Namespaces == nil {
+		rf.Namespaces = make(map[string]*nsReport)
+	}
+
+	if r.Entries == nil {
+		r.Entries = []*reportEntry{}
+	}
+	r.summarize()
+	rf.Namespaces[r.Namespace] = r
+
+	data, err = json.MarshalIndent(&rf, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0644)
+}
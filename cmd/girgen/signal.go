@@ -0,0 +1,209 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
+)
+
+// getSignalConnectName 返回信号的连接方法名，比如 size-allocate => ConnectSizeAllocate，
+// 如果 container 已经有同名的方法，则加上 Signal 后缀。
+func getSignalConnectName(sigName string, container *gi.BaseInfo) string {
+	name := "Connect" + toCamelCase(strings.Replace(sigName, "_", "-", -1), "-")
+	methodName := "connect_" + strings.Replace(sigName, "-", "_", -1)
+	var fi *gi.FunctionInfo
+	switch container.Type() {
+	case gi.INFO_TYPE_OBJECT:
+		fi = gi.ToObjectInfo(container).FindMethod(methodName)
+	case gi.INFO_TYPE_INTERFACE:
+		fi = gi.ToInterfaceInfo(container).FindMethod(methodName)
+	}
+	if fi != nil {
+		fi.Unref()
+		name += "Signal"
+	}
+	return name
+}
+
+// pSignalConnect 为信号 si 生成 ConnectXXX 和 ConnectXXXAfter 方法，container 是信号所在的对象或接口。
+func pSignalConnect(s *SourceFile, si *gi.SignalInfo, container *gi.BaseInfo) {
+	sigName := si.Name()
+	if si.IsDeprecated() && _cfg.SkipDeprecated {
+		s.GoBody.Pn("// deprecated signal %s::%s\n", container.Name(), sigName)
+		return
+	}
+	if pSkipNewer(s, "signal", container.Name()+"::"+sigName, getSignalSince(container.Name(), sigName)) {
+		return
+	}
+	cSymbol := getCIdentifierPrefix(container) + container.Name() + "::" + sigName
+	isContainerIfc := container.Type() == gi.INFO_TYPE_INTERFACE
+	receiverType := container.Name()
+	if isContainerIfc {
+		receiverType = "*" + receiverType + "Ifc"
+	}
+
+	var varReg VarReg
+	varV := varReg.alloc("v")
+	varFn := varReg.alloc("fn")
+	varArgs := varReg.alloc("args")
+	varRet := varReg.alloc("ret")
+	getPtrExpr := varV + ".P"
+	if isContainerIfc {
+		getPtrExpr = fmt.Sprintf("*(*unsafe.Pointer)(unsafe.Pointer(%v))", varV)
+	}
+
+	var paramNameTypes []string
+	var paramNames []string
+	var convLines []string
+	numArgs := si.NumArg()
+	for i := 0; i < numArgs; i++ {
+		argInfo := si.Arg(i)
+		argTypeInfo := argInfo.Type()
+		dir := argInfo.Direction()
+		argName := argInfo.Name()
+		tag := argTypeInfo.Tag()
+		argInfo.Unref()
+
+		// 信号的数组参数没有办法带上长度参数
+		if dir != gi.DIRECTION_IN || !isPropertyTypeSupported(argTypeInfo) ||
+			(tag == gi.TYPE_TAG_ARRAY && argTypeInfo.ArrayLength() >= 0) {
+			reason := "unsupported signal argument type"
+			if dir != gi.DIRECTION_IN {
+				reason = "signal argument direction not in"
+			} else if tag == gi.TYPE_TAG_ARRAY && argTypeInfo.ArrayLength() >= 0 {
+				reason = "signal array argument with length"
+			}
+			_report.add(newReportEntry("signal", cSymbol, i, argName, dir.String(), argTypeInfo), reason)
+			argTypeInfo.Unref()
+			s.GoBody.Pn("// TODO: signal %s::%s, arg %s, dir: %v, tag: %v\n",
+				container.Name(), sigName, argName, dir, tag)
+			return
+		}
+
+		paramName := varReg.regParam(i, argName)
+		// args[0] 是发出信号的实例
+		argExpr := fmt.Sprintf("%v[%d]", varArgs, i+1)
+		// 信号参数的所有权都不转移，从 GValue 取出的值需要复制
+		parseResult := parseRetType(argExpr, argTypeInfo, &varReg, nil, gi.TRANSFER_NOTHING)
+		_report.addTodoType(newReportEntry("signal", cSymbol, i, argName, dir.String(), argTypeInfo),
+			parseResult.type0)
+		argTypeInfo.Unref()
+		if strings.Contains(parseResult.type0, "TODO") {
+			s.GoBody.Pn("// TODO: signal %s::%s, arg %s, type: %v\n",
+				container.Name(), sigName, argName, parseResult.type0)
+			return
+		}
+		paramNameTypes = append(paramNameTypes, paramName+" "+parseResult.type0)
+		paramNames = append(paramNames, paramName)
+		convLines = append(convLines, fmt.Sprintf("var %v %v", paramName, parseResult.type0))
+		convLines = append(convLines, parseResult.assignLines(paramName)...)
+	}
+
+	retTypeInfo := si.ReturnType()
+	defer retTypeInfo.Unref()
+	var retParseResult *parseArgTypeDirInResult
+	var varResult string
+	retType := ""
+	if !(retTypeInfo.Tag() == gi.TYPE_TAG_VOID && !retTypeInfo.IsPointer()) {
+		if !isPropertyTypeSupported(retTypeInfo) {
+			_report.add(newReportEntry("signal", cSymbol, -1, "", dirReturn, retTypeInfo),
+				"unsupported signal return type")
+			s.GoBody.Pn("// TODO: signal %s::%s, return tag: %v\n",
+				container.Name(), sigName, retTypeInfo.Tag())
+			return
+		}
+		varResult = varReg.alloc("result")
+		retParseResult = parseArgTypeDirIn(varResult, retTypeInfo, &varReg, gi.TRANSFER_NOTHING)
+		// GValue 中的指针不会被复制，转换出来的容器在返回前就被释放了。
+		if strings.Contains(retParseResult.type0, "TODO") ||
+			strings.HasPrefix(retParseResult.type0, "[]") ||
+			strings.HasPrefix(retParseResult.type0, "map[") {
+			_report.add(newReportEntry("signal", cSymbol, -1, "", dirReturn, retTypeInfo),
+				"signal return value is a container or unsupported type")
+			s.GoBody.Pn("// TODO: signal %s::%s, return type: %v\n",
+				container.Name(), sigName, retParseResult.type0)
+			return
+		}
+		retType = " " + retParseResult.type0
+	}
+
+	fnType := fmt.Sprintf("func(%v)%v", strings.Join(paramNameTypes, ", "), retType)
+	name := getSignalConnectName(sigName, container)
+	prefix := getPkgPrefix("GObject")
+	// 所有权模式下连接信号之后再返回 handle，保证连接时实例还没有被释放
+	keepAliveLine := getKeepAliveLine(s, container, varV, isContainerIfc)
+	var varHandle string
+	if keepAliveLine != "" {
+		varHandle = varReg.alloc("handle")
+	}
+
+	for _, after := range []bool{false, true} {
+		methodName := name
+		if after {
+			methodName += "After"
+		}
+		if after {
+			s.GoBody.Pn("// %s 连接信号 %q，处理函数在默认处理函数之后调用", methodName, sigName)
+		} else {
+			s.GoBody.Pn("// %s 连接信号 %q", methodName, sigName)
+		}
+		if si.IsDeprecated() {
+			pSignalDeprecated(s, container.Name(), sigName)
+		}
+		s.GoBody.Pn("func (%v %v) %v(%v %v) %vSignalHandle {", varV, receiverType, methodName,
+			varFn, fnType, prefix)
+		connectStart := "return "
+		if varHandle != "" {
+			connectStart = varHandle + " := "
+		}
+		s.GoBody.Pn("%v%vConnectSignal(%v, %q, %v, func(%v []gi.Argument, %v %vValue) {",
+			connectStart, prefix, getPtrExpr, sigName, after, varArgs, varRet, prefix)
+		for _, line := range convLines {
+			s.GoBody.Pn(line)
+		}
+		callExpr := fmt.Sprintf("%v(%v)", varFn, strings.Join(paramNames, ", "))
+		if retParseResult == nil {
+			s.GoBody.Pn(callExpr)
+		} else {
+			s.GoBody.Pn("%v := %v", varResult, callExpr)
+			s.GoBody.Pn("if %v.P == nil {", varRet)
+			s.GoBody.Pn("return")
+			s.GoBody.Pn("}")
+			for _, line := range retParseResult.beforeArgLines {
+				s.GoBody.Pn(line)
+			}
+			s.GoBody.Pn("%v.SetArgument(%v)", varRet, retParseResult.newArgExpr)
+			for _, line := range retParseResult.afterCallLines {
+				s.GoBody.Pn(line)
+			}
+		}
+		s.GoBody.Pn("})") // end closure
+		if varHandle != "" {
+			s.GoBody.Pn(keepAliveLine)
+			s.GoBody.Pn("return %v", varHandle)
+		}
+		s.GoBody.Pn("}") // end func
+	}
+}
@@ -188,10 +188,6 @@ func getConstructorName(containerName, fnName string) string {
 	return containerName + fnName
 }
 
-func markDeprecated(s *SourceFile) {
-	s.GoBody.Pn("// Deprecated\n//")
-}
-
 // copyFileContent copies the contents of the file named src to the file named
 // by dst. The file will be created if it does not already exist. If the
 // destination file exists, all it's contents will be replaced by the contents
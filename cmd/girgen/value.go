@@ -0,0 +1,168 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
+)
+
+type scalarField struct {
+	name   string // Go 结构体中的字段名，不可读的字段为 _
+	type0  string
+	offset int
+}
+
+// 键是简单类型在 Go 中的类型名，值是它的大小，也是它的自然对齐。
+var _scalarTypeSizes = map[string]int{
+	"int8":    1,
+	"uint8":   1,
+	"int16":   2,
+	"uint16":  2,
+	"int32":   4,
+	"uint32":  4,
+	"int64":   8,
+	"uint64":  8,
+	"float32": 4,
+	"float64": 8,
+}
+
+// checkScalarLayout 按照字段类型的自然对齐计算 fields 的偏移量和结构体的大小，
+// 检查它们是否和 C 结构体的一致，一致时相应的 Go 结构体的内存布局和 C 结构体的相同。
+func checkScalarLayout(fields []scalarField, size int) bool {
+	if len(fields) == 0 {
+		return false
+	}
+	offset := 0
+	maxAlign := 1
+	for _, field := range fields {
+		n := _scalarTypeSizes[field.type0]
+		if n == 0 {
+			return false
+		}
+		offset = (offset + n - 1) / n * n
+		if offset != field.offset {
+			return false
+		}
+		offset += n
+		if n > maxAlign {
+			maxAlign = n
+		}
+	}
+	offset = (offset + maxAlign - 1) / maxAlign * maxAlign
+	return offset == size
+}
+
+// getScalarStructFields 返回结构体 si 的字段，si 的字段不全是简单的数值类型，
+// 或者不能用内存布局相同的 Go 结构体表示时，返回 nil。
+func getScalarStructFields(si *gi.StructInfo) []scalarField {
+	if si.IsGTypeStruct() || si.IsForeign() {
+		return nil
+	}
+	// 避免和已有的类型或者方法同名
+	bi := gi.DefaultRepository().FindByName(si.Namespace(), si.Name()+"Value")
+	if !bi.IsNil() {
+		bi.Unref()
+		return nil
+	}
+	for _, method := range []string{"value", "set_value", "ptr"} {
+		if hasMethod(si, method) {
+			return nil
+		}
+	}
+
+	numField := si.NumField()
+	fields := make([]scalarField, 0, numField)
+	for i := 0; i < numField; i++ {
+		fi := si.Field(i)
+		ti := fi.Type()
+		tag := ti.Tag()
+		// 位域的 Size 不为 0
+		ok := fi.Size() == 0 && !ti.IsPointer()
+		var type0 string
+		if ok {
+			switch tag {
+			case gi.TYPE_TAG_INT8, gi.TYPE_TAG_UINT8,
+				gi.TYPE_TAG_INT16, gi.TYPE_TAG_UINT16,
+				gi.TYPE_TAG_INT32, gi.TYPE_TAG_UINT32,
+				gi.TYPE_TAG_INT64, gi.TYPE_TAG_UINT64,
+				gi.TYPE_TAG_FLOAT, gi.TYPE_TAG_DOUBLE:
+				type0 = getTypeWithTag(tag)
+			default:
+				ok = false
+			}
+		}
+		name := "_"
+		if fi.Flags()&gi.FIELD_IS_READABLE != 0 {
+			name = snake2Camel(fi.Name())
+		}
+		offset := fi.Offset()
+		ti.Unref()
+		fi.Unref()
+		if !ok {
+			return nil
+		}
+		fields = append(fields, scalarField{name: name, type0: type0, offset: offset})
+	}
+
+	if !checkScalarLayout(fields, si.Size()) {
+		return nil
+	}
+	return fields
+}
+
+// isScalarStruct 返回 bi 是否是可以用 Go 值类型表示的结构体
+func isScalarStruct(bi *gi.BaseInfo) bool {
+	if bi.Type() != gi.INFO_TYPE_STRUCT {
+		return false
+	}
+	return getScalarStructFields(gi.ToStructInfo(bi)) != nil
+}
+
+// pStructValue 为只有简单类型字段的结构体 name 生成内存布局相同的 Go 结构体 nameValue，
+// 以及在两者之间转换的方法。
+func pStructValue(s *SourceFile, name string, fields []scalarField) {
+	valueName := name + "Value"
+	s.GoBody.Pn("// %v 是和 %v 内存布局相同的 Go 结构体，可以按值传递。", valueName, name)
+	s.GoBody.Pn("type %v struct {", valueName)
+	for _, field := range fields {
+		s.GoBody.Pn("%v %v", field.name, field.type0)
+	}
+	s.GoBody.Pn("}") // end struct
+
+	s.GoBody.Pn("// Value 返回 v 指向的结构体的一份拷贝")
+	s.GoBody.Pn("func (v %v) Value() %v {", name, valueName)
+	s.GoBody.Pn("return *(*%v)(v.P)", valueName)
+	s.GoBody.Pn("}")
+
+	s.GoBody.Pn("// SetValue 把 value 复制到 v 指向的结构体中")
+	s.GoBody.Pn("func (v %v) SetValue(value %v) {", name, valueName)
+	s.GoBody.Pn("*(*%v)(v.P) = value", valueName)
+	s.GoBody.Pn("}")
+
+	// 返回值可能被 C 函数保存下来，所以不能使用 Go 的内存。
+	s.GoBody.Pn("// Ptr 用 gi.Malloc 分配内存并复制一份 value，返回指向它的 %v，", name)
+	s.GoBody.Pn("// 不再使用时需要用 gi.Free 释放返回值的 P 字段。")
+	s.GoBody.Pn("func (value %v) Ptr() (result %v) {", valueName, name)
+	s.GoBody.Pn("result.P = gi.Malloc(int(unsafe.Sizeof(value)))")
+	s.GoBody.Pn("*(*%v)(result.P) = value", valueName)
+	s.GoBody.Pn("return }")
+}
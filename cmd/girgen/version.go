@@ -0,0 +1,183 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// parseVersion 解析 3.22 或者 2.28.1 这样的版本号，只取主版本号和次版本号。
+func parseVersion(version string) (major, minor int, ok bool) {
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+// versionLess 返回版本 a 是否比版本 b 低，不能解析的版本号返回 false。
+func versionLess(a, b string) bool {
+	aMajor, aMinor, ok := parseVersion(a)
+	if !ok {
+		return false
+	}
+	bMajor, bMinor, ok := parseVersion(b)
+	if !ok {
+		return false
+	}
+	if aMajor != bMajor {
+		return aMajor < bMajor
+	}
+	return aMinor < bMinor
+}
+
+// isNewerThanMinVersion 返回从 since 版本开始才有的 API 是否比配置的最低版本新
+func isNewerThanMinVersion(since string) bool {
+	if _cfg == nil || _cfg.MinVersion == "" || since == "" {
+		return false
+	}
+	return versionLess(_cfg.MinVersion, since)
+}
+
+// pSkipNewer 在从 since 版本开始才有的 API 比配置的最低版本新时，输出一行说明跳过它的注释并返回 true，
+// kind 和 name 是 API 的种类和名字。
+func pSkipNewer(s *SourceFile, kind, name, since string) bool {
+	if !isNewerThanMinVersion(since) {
+		return false
+	}
+	s.GoBody.Pn("\n// %s %s since %s is newer than min version %s\n", kind, name, since, _cfg.MinVersion)
+	return true
+}
+
+// getTypeSince 返回当前命名空间中的类型 name 从哪个版本开始有
+func getTypeSince(name string) string {
+	if _xRepo == nil {
+		return ""
+	}
+	typ, _ := _xRepo.GetType(name)
+	if b, ok := typ.(interface{ SinceVersion() string }); ok {
+		return b.SinceVersion()
+	}
+	return ""
+}
+
+// getPropertySince 返回类型 typeName 的属性 propName 从哪个版本开始有
+func getPropertySince(typeName, propName string) string {
+	if _xRepo == nil {
+		return ""
+	}
+	if prop := _xRepo.GetProperty(typeName, propName); prop != nil {
+		return prop.Since
+	}
+	return ""
+}
+
+// getSignalSince 返回类型 typeName 的信号 sigName 从哪个版本开始有
+func getSignalSince(typeName, sigName string) string {
+	if _xRepo == nil {
+		return ""
+	}
+	if signal := _xRepo.GetSignal(typeName, sigName); signal != nil {
+		return signal.Since
+	}
+	return ""
+}
+
+// getVFuncSince 返回类型 typeName 的虚函数 vfName 从哪个版本开始有
+func getVFuncSince(typeName, vfName string) string {
+	if _xRepo == nil {
+		return ""
+	}
+	if vfunc := _xRepo.GetVirtualMethod(typeName, vfName); vfunc != nil {
+		return vfunc.Since
+	}
+	return ""
+}
+
+// getSinceFuncName 返回生成的 Since 函数的名字，GLib、GObject 和 Gio 在同一个包中，需要加上命名空间作为前缀。
+func getSinceFuncName() string {
+	if getPkgName(_optNamespace) == "g" {
+		return _optNamespace + "Since"
+	}
+	return "Since"
+}
+
+// pSince 生成 Since 函数，以及每个版本新加的一个函数组成的表，用于在运行时检查加载的库的版本。
+func pSince(s *SourceFile) {
+	if _xRepo == nil {
+		return
+	}
+	// 键是版本，值是这个版本新加的函数中按字母顺序最前的一个
+	versionSymbols := make(map[string]string)
+	for symbol, fn := range _xRepo.GetFunctions() {
+		if _, _, ok := parseVersion(fn.Since); !ok {
+			continue
+		}
+		if old, ok := versionSymbols[fn.Since]; !ok || symbol < old {
+			versionSymbols[fn.Since] = symbol
+		}
+	}
+	versions := make([]string, 0, len(versionSymbols))
+	for version := range versionSymbols {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		if versionLess(versions[i], versions[j]) {
+			return true
+		}
+		if versionLess(versions[j], versions[i]) {
+			return false
+		}
+		return versions[i] < versions[j]
+	})
+
+	funcName := getSinceFuncName()
+	varSymbols := "_" + funcName + "Symbols"
+	s.GoBody.Pn("// %v 中每个版本新加的一个函数，按版本从低到高排列", varSymbols)
+	s.GoBody.Pn("var %v = []gi.SinceSymbol{", varSymbols)
+	var prevMajor, prevMinor int
+	for i, version := range versions {
+		major, minor, _ := parseVersion(version)
+		if i > 0 && major == prevMajor && minor == prevMinor {
+			// 比如 2.28 和 2.28.1
+			continue
+		}
+		prevMajor, prevMinor = major, minor
+		s.GoBody.Pn("{Major: %v, Minor: %v, Symbol: %q},", major, minor, versionSymbols[version])
+	}
+	s.GoBody.Pn("}")
+
+	s.GoBody.Pn("// %v 返回运行时加载的 %v 库的版本是否不低于 major.minor", funcName, _optNamespace)
+	s.GoBody.Pn("func %v(major, minor int) bool {", funcName)
+	s.GoBody.Pn("return gi.Since(%q, %v, major, minor)", _optNamespace, varSymbols)
+	s.GoBody.Pn("}")
+}
@@ -0,0 +1,304 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	gi "github.com/electricface/go-gir3/cmd/girgen/girepo"
+)
+
+// pVFuncs 为对象 oi 的虚函数生成 OverrideXXX 函数，用于在 Go 定义的子类型中覆盖虚函数。
+func pVFuncs(s *SourceFile, oi *gi.ObjectInfo) {
+	classStruct := oi.ClassStruct()
+	if classStruct == nil {
+		return
+	}
+	defer classStruct.Unref()
+
+	// 类结构体中的字段名，虚函数必须有同名的字段才能覆盖
+	fieldNames := make(map[string]struct{})
+	numField := classStruct.NumField()
+	for i := 0; i < numField; i++ {
+		field := classStruct.Field(i)
+		fieldNames[field.Name()] = struct{}{}
+		field.Unref()
+	}
+
+	numVFunc := oi.NumVFunc()
+	for i := 0; i < numVFunc; i++ {
+		vfi := oi.VFunc(i)
+		if _, ok := fieldNames[vfi.Name()]; ok {
+			pVFunc(s, vfi, oi, classStruct)
+		}
+		vfi.Unref()
+	}
+}
+
+// isCbTypeSupported 检查 parseCbArgTypeDirOut 的结果是否能安全地写回 C 指针
+func isCbTypeSupported(ti *gi.TypeInfo, goType string) bool {
+	if ti.Tag() == gi.TYPE_TAG_ARRAY {
+		return false
+	}
+	// 不认识的非指针类型，大小可能不是指针的大小
+	return goType != "unsafe.Pointer" || ti.IsPointer()
+}
+
+func pVFunc(s *SourceFile, vfi *gi.VFuncInfo, oi *gi.ObjectInfo, classStruct *gi.StructInfo) {
+	vfName := vfi.Name()
+	objName := oi.Name()
+	flags := vfi.Flags()
+	if flags&gi.VFUNC_MUST_NOT_OVERRIDE != 0 {
+		return
+	}
+	if pSkipNewer(s, "vfunc", objName+"."+vfName, getVFuncSince(objName, vfName)) {
+		return
+	}
+	bi := gi.ToBaseInfo(oi)
+	cPrefix := getCIdentifierPrefix(bi)
+	if flags&gi.VFUNC_THROWS != 0 {
+		s.GoBody.Pn("// TODO: vfunc %s.%s throws error\n", objName, vfName)
+		_report.add(newReportEntry("vfunc", cPrefix+objName+"."+vfName, -1, "", "", nil),
+			"vfunc throws error")
+		return
+	}
+
+	classCType := cPrefix + classStruct.Name()
+	selfCType, selfCgoType := getCbInterfaceCType(bi)
+	key := cPrefix + objName + "." + vfName
+	goName := objName + snake2Camel(vfName)
+	structName := goName + "VFuncStruct"
+	myFuncName := "myVFunc" + _optNamespace + objName + "_" + vfName
+	prefix := getPkgPrefix("GObject")
+
+	var varReg VarReg
+	varSelf := varReg.alloc("self")
+	numArgs := vfi.NumArg()
+	for i := 0; i < numArgs; i++ {
+		argInfo := vfi.Arg(i)
+		varReg.regParam(i, argInfo.Name())
+		argInfo.Unref()
+	}
+	varFn := varReg.alloc("fn")
+	varArgs := varReg.alloc("args")
+	varGType := varReg.alloc("gType")
+
+	// C 中的参数类型列表和 "类型 名字" 列表
+	cParamTypes := []string{selfCType}
+	cParamTypeNames := []string{selfCType + " " + varSelf}
+	paramNames := []string{varSelf}
+	paramNameTypes := []string{varSelf + " " + selfCgoType}
+	fields := []string{"F_" + varSelf + " " + objName}
+	cFields := []string{"c_" + varSelf + " " + selfCgoType}
+	fieldSetLines := []string{
+		fmt.Sprintf("F_%v: Wrap%v(unsafe.Pointer(%v)),", varSelf, objName, varSelf),
+		fmt.Sprintf("c_%v: %v,", varSelf, varSelf),
+	}
+	var writeBackLines []string
+	// Chain 方法中调用 C 实现之后，用 out 参数的新值更新字段
+	var chainAfterLines []string
+
+	for i := 0; i < numArgs; i++ {
+		argInfo := vfi.Arg(i)
+		argTypeInfo := argInfo.Type()
+		paramName := varReg.getParam(i)
+		fieldName := "F_" + paramName
+		cFieldName := "c_" + paramName
+		dir := argInfo.Direction()
+		if dir == gi.DIRECTION_OUT && argInfo.IsCallerAllocates() {
+			dir = gi.DIRECTION_IN
+		}
+		transfer := argInfo.OwnershipTransfer()
+		argName := argInfo.Name()
+		argInfo.Unref()
+
+		var cType, cgoType string
+		supported := true
+		switch dir {
+		case gi.DIRECTION_IN:
+			parseResult := parseCbArgTypeDirIn(paramName, argTypeInfo)
+			if strings.Contains(parseResult.goType, "TODO") ||
+				(argTypeInfo.Tag() == gi.TYPE_TAG_ARRAY && argTypeInfo.ArrayLength() >= 0) {
+				supported = false
+				break
+			}
+			cType = parseResult.cType
+			cgoType = parseResult.cgoType
+			fields = append(fields, fieldName+" "+parseResult.goType)
+			fieldSetLines = append(fieldSetLines, fmt.Sprintf("%v: %v,", fieldName, parseResult.expr))
+
+		case gi.DIRECTION_OUT, gi.DIRECTION_INOUT:
+			parseResult := parseCbArgTypeDirOut(varArgs+"."+fieldName, "(*"+paramName+")",
+				argTypeInfo, transfer)
+			if !isCbTypeSupported(argTypeInfo, parseResult.goType) {
+				supported = false
+				break
+			}
+			cType = parseResult.cType + "*"
+			cgoType = "*" + parseResult.cgoType
+			fields = append(fields, fieldName+" "+parseResult.goType)
+			if dir == gi.DIRECTION_INOUT {
+				fieldSetLines = append(fieldSetLines, fmt.Sprintf("%v: %v,", fieldName, parseResult.goExpr))
+			}
+			writeBackLines = append(writeBackLines,
+				fmt.Sprintf("if %v != nil {", paramName),
+				fmt.Sprintf("*%v = %v", paramName, parseResult.cExpr),
+				"}")
+
+			chainResult := parseCbArgTypeDirOut("v."+fieldName, "(*v."+cFieldName+")",
+				argTypeInfo, transfer)
+			chainAfterLines = append(chainAfterLines,
+				fmt.Sprintf("if v.%v != nil {", cFieldName),
+				fmt.Sprintf("v.%v = %v", fieldName, chainResult.goExpr),
+				"}")
+		}
+		if !supported {
+			_report.add(newReportEntry("vfunc", key, i, argName, dir.String(), argTypeInfo),
+				"unsupported vfunc argument type")
+			argTypeInfo.Unref()
+			s.GoBody.Pn("// TODO: vfunc %s.%s, arg %s, dir: %v\n", objName, vfName, paramName, dir)
+			return
+		}
+		argTypeInfo.Unref()
+		cParamTypes = append(cParamTypes, cType)
+		cParamTypeNames = append(cParamTypeNames, cType+" "+paramName)
+		paramNames = append(paramNames, paramName)
+		paramNameTypes = append(paramNameTypes, paramName+" "+cgoType)
+		cFields = append(cFields, cFieldName+" "+cgoType)
+		fieldSetLines = append(fieldSetLines, fmt.Sprintf("%v: %v,", cFieldName, paramName))
+	}
+
+	retTypeInfo := vfi.ReturnType()
+	defer retTypeInfo.Unref()
+	cRetType := "void"
+	cgoRetType := ""
+	var retExpr string
+	var chainRetLines []string
+	if !(retTypeInfo.Tag() == gi.TYPE_TAG_VOID && !retTypeInfo.IsPointer()) {
+		varResult := varReg.alloc("result")
+		fieldName := "F_" + varResult
+		parseResult := parseCbArgTypeDirOut(varArgs+"."+fieldName, "", retTypeInfo, vfi.CallerOwns())
+		if !isCbTypeSupported(retTypeInfo, parseResult.goType) {
+			s.GoBody.Pn("// TODO: vfunc %s.%s, return tag: %v\n", objName, vfName, retTypeInfo.Tag())
+			_report.add(newReportEntry("vfunc", key, -1, "", dirReturn, retTypeInfo),
+				"unsupported vfunc return type")
+			return
+		}
+		cRetType = parseResult.cType
+		cgoRetType = " " + parseResult.cgoType
+		retExpr = parseResult.cExpr
+		fields = append(fields, fieldName+" "+parseResult.goType)
+
+		chainResult := parseCbArgTypeDirOut("v."+fieldName, "ret", retTypeInfo, vfi.CallerOwns())
+		chainRetLines = append(chainRetLines, fmt.Sprintf("v.%v = %v", fieldName, chainResult.goExpr))
+		if chainResult.goType == "string" {
+			// 父类型的实现返回的字符串已经复制到 Go 中了
+			chainRetLines = append(chainRetLines, "gi.Free(unsafe.Pointer(ret))")
+		}
+	}
+
+	// C 部分
+	s.CBody.Pn("extern %v %v(%v);", cRetType, myFuncName, strings.Join(cParamTypeNames, ", "))
+	s.CBody.Pn("static void _override_%v(gpointer klass) {", myFuncName)
+	// 通过 gpointer 赋值，避免 C 函数指针类型不完全一致导致的编译错误
+	s.CBody.Pn("*(gpointer*)(&((%v*)klass)->%v) = (gpointer)(%v);", classCType, vfName, myFuncName)
+	s.CBody.Pn("}")
+	chainParams := append([]string{"GType type"}, cParamTypeNames...)
+	s.CBody.Pn("static %v _chain_%v(%v) {", cRetType, myFuncName, strings.Join(chainParams, ", "))
+	s.CBody.Pn("%v *klass = g_type_class_peek(g_type_parent(type));", classCType)
+	s.CBody.Pn("%v (*fn)(%v) = (%v (*)(%v))(klass->%v);", cRetType, strings.Join(cParamTypes, ", "),
+		cRetType, strings.Join(cParamTypes, ", "), vfName)
+	if cRetType == "void" {
+		s.CBody.Pn("if (fn != NULL) fn(%v);", strings.Join(paramNames, ", "))
+	} else {
+		s.CBody.Pn("if (fn == NULL) return (%v)0;", cRetType)
+		s.CBody.Pn("return fn(%v);", strings.Join(paramNames, ", "))
+	}
+	s.CBody.Pn("}")
+
+	// Go 部分
+	s.GoBody.Pn("// %v 是虚函数 %v 的参数，F_ 开头的字段是参数和返回值", structName, key)
+	s.GoBody.Pn("type %v struct {", structName)
+	for _, field := range fields {
+		s.GoBody.Pn(field)
+	}
+	s.GoBody.Pn("gType gi.GType // 实现虚函数的类型")
+	for _, field := range cFields {
+		s.GoBody.Pn(field)
+	}
+	s.GoBody.Pn("}") // end struct
+
+	s.GoBody.Pn("// Override%v 在类结构体 klass 中用 fn 实现虚函数 %v，fn 的参数是 *%v。",
+		goName, vfName, structName)
+	s.GoBody.Pn("func Override%v(klass unsafe.Pointer, fn func(v interface{})) {", goName)
+	s.GoBody.Pn("%vSetVFunc(klass, %q, fn)", prefix, key)
+	s.GoBody.Pn("C._override_%v(C.gpointer(klass))", myFuncName)
+	s.GoBody.Pn("}") // end func
+
+	s.GoBody.Pn("// Chain 调用父类型中虚函数 %v 的实现", vfName)
+	s.GoBody.Pn("func (v *%v) Chain() {", structName)
+	s.GoBody.Pn("fn, owner := %vFindParentVFunc(v.gType, %q)", prefix, key)
+	s.GoBody.Pn("if fn != nil {")
+	s.GoBody.Pn("gType := v.gType")
+	s.GoBody.Pn("v.gType = owner")
+	s.GoBody.Pn("fn(v)")
+	s.GoBody.Pn("v.gType = gType")
+	s.GoBody.Pn("return")
+	s.GoBody.Pn("}") // end if
+	chainArgs := []string{"C.GType(v.gType)"}
+	for _, name := range paramNames {
+		chainArgs = append(chainArgs, "v.c_"+name)
+	}
+	chainCall := fmt.Sprintf("C._chain_%v(%v)", myFuncName, strings.Join(chainArgs, ", "))
+	if len(chainRetLines) > 0 {
+		s.GoBody.Pn("ret := %v", chainCall)
+		for _, line := range chainRetLines {
+			s.GoBody.Pn(line)
+		}
+	} else {
+		s.GoBody.Pn(chainCall)
+	}
+	for _, line := range chainAfterLines {
+		s.GoBody.Pn(line)
+	}
+	s.GoBody.Pn("}") // end func
+
+	s.GoBody.Pn("//export %v", myFuncName)
+	s.GoBody.Pn("func %v(%v)%v {", myFuncName, strings.Join(paramNameTypes, ", "), cgoRetType)
+	s.GoBody.Pn("%v, %v := %vFindVFunc(%vTypeFromInstance(unsafe.Pointer(%v)), %q)",
+		varFn, varGType, prefix, prefix, varSelf, key)
+	s.GoBody.Pn("%v := &%v{", varArgs, structName)
+	for _, line := range fieldSetLines {
+		s.GoBody.Pn(line)
+	}
+	s.GoBody.Pn("gType: %v,", varGType)
+	s.GoBody.Pn("}") // end struct
+	s.GoBody.Pn("%v(%v)", varFn, varArgs)
+	for _, line := range writeBackLines {
+		s.GoBody.Pn(line)
+	}
+	if retExpr != "" {
+		s.GoBody.Pn("return %v", retExpr)
+	}
+	s.GoBody.Pn("}") // end func
+}
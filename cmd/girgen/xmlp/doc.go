@@ -0,0 +1,139 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package xmlp
+
+// Doc 是 GIR 文件中的 doc 和 doc-deprecated 元素，内容是 gtk-doc 格式的文档。
+type Doc struct {
+	Text string `xml:",chardata"`
+}
+
+// String 返回文档的内容，d 为 nil 时返回空字符串。
+func (d *Doc) String() string {
+	if d == nil {
+		return ""
+	}
+	return d.Text
+}
+
+// Documentation 返回文档
+func (b *BaseInfo) Documentation() string {
+	return b.Doc.String()
+}
+
+// Deprecation 返回从哪个版本开始废弃和废弃说明
+func (b *BaseInfo) Deprecation() (version, docDeprecated string) {
+	return b.DeprecatedVersion, b.DocDeprecated.String()
+}
+
+// Deprecation 返回从哪个版本开始废弃和废弃说明
+func (p *Property) Deprecation() (version, docDeprecated string) {
+	return p.DeprecatedVersion, p.DocDeprecated.String()
+}
+
+// SinceVersion 返回从哪个版本开始有
+func (b *BaseInfo) SinceVersion() string {
+	return b.Since
+}
+
+// GetProperty 返回类型 typeName 的属性 name，typeName 不是对象或接口，或者没有这个属性时返回 nil。
+func (r *Repository) GetProperty(typeName, name string) *Property {
+	var props []*Property
+	switch typ := r.typeMap[typeName].(type) {
+	case *ObjectInfo:
+		props = typ.Properties
+	case *InterfaceInfo:
+		props = typ.Properties
+	}
+	for _, prop := range props {
+		if prop.Name == name {
+			return prop
+		}
+	}
+	return nil
+}
+
+// GetSignal 返回类型 typeName 的信号 name，typeName 不是对象或接口，或者没有这个信号时返回 nil。
+func (r *Repository) GetSignal(typeName, name string) *SignalInfo {
+	var signals []*SignalInfo
+	switch typ := r.typeMap[typeName].(type) {
+	case *ObjectInfo:
+		signals = typ.Signals
+	case *InterfaceInfo:
+		signals = typ.Signals
+	}
+	for _, signal := range signals {
+		if signal.NameAttr == name {
+			return signal
+		}
+	}
+	return nil
+}
+
+// GetVirtualMethod 返回类型 typeName 的虚函数 name，typeName 不是对象或接口，或者没有这个虚函数时返回 nil。
+func (r *Repository) GetVirtualMethod(typeName, name string) *VFuncInfo {
+	var vfuncs []*VFuncInfo
+	switch typ := r.typeMap[typeName].(type) {
+	case *ObjectInfo:
+		vfuncs = typ.VirtualMethods
+	case *InterfaceInfo:
+		vfuncs = typ.VirtualMethods
+	}
+	for _, vfunc := range vfuncs {
+		if vfunc.NameAttr == name {
+			return vfunc
+		}
+	}
+	return nil
+}
+
+// GetParameterDoc 返回参数 name 的文档
+func (f *FunctionInfo) GetParameterDoc(name string) string {
+	if f.Parameters == nil {
+		return ""
+	}
+	if p := f.Parameters.InstanceParameter; p != nil && p.Name == name {
+		return p.Doc.String()
+	}
+	for _, p := range f.Parameters.Parameters {
+		if p.Name == name {
+			return p.Doc.String()
+		}
+	}
+	return ""
+}
+
+// GetReturnDoc 返回返回值的文档
+func (f *FunctionInfo) GetReturnDoc() string {
+	if f.ReturnValue == nil {
+		return ""
+	}
+	return f.ReturnValue.Doc.String()
+}
+
+// LoadedRepos 返回所有已经加载的仓库
+func LoadedRepos() []*Repository {
+	repos := make([]*Repository, 0, len(loadedRepos))
+	for _, repo := range loadedRepos {
+		repos = append(repos, repo)
+	}
+	return repos
+}
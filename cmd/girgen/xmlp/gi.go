@@ -26,6 +26,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -38,7 +39,7 @@ func GetLoadedRepo(ns string) *Repository {
 	}
 
 	for nsVer, repo := range loadedRepos {
-		if strings.HasPrefix(nsVer, ns) {
+		if strings.HasPrefix(nsVer, ns+"-") {
 			return repo
 		}
 	}
@@ -54,6 +55,8 @@ type Repository struct {
 
 	includeRepos map[string]*Repository
 	typeMap      map[string]TypeDefine
+	// 键是函数的 C 符号
+	funcMap map[string]*FunctionInfo
 }
 
 type TypeDefine interface {
@@ -143,6 +146,16 @@ func (r *Repository) postDecode() {
 			})
 		}
 
+		for _, fn := range union.Functions {
+			fn.container = union
+		}
+		for _, fn := range union.Constructors {
+			fn.container = union
+		}
+		for _, fn := range union.Methods {
+			fn.container = union
+		}
+
 		if _, ok := r.typeMap[union.NameAttr]; ok {
 			panic("duplicate type " + union.NameAttr)
 		}
@@ -240,7 +253,7 @@ func (r *Repository) postDecode() {
 		}
 		r.typeMap[callback.NameAttr] = callback
 	}
-	fmt.Println("// finish type register", r.Namespace.Name, r.Namespace.Version)
+	fmt.Fprintln(os.Stderr, "// finish type register", r.Namespace.Name, r.Namespace.Version)
 }
 
 func (r *Repository) GetType(name string) (TypeDefine, string) {
@@ -272,6 +285,46 @@ func (r *Repository) GetTypes() map[string]TypeDefine {
 	return r.typeMap
 }
 
+// GetFunction 根据 C 符号查找函数，包括各种类型的函数、构造器和方法。
+func (r *Repository) GetFunction(symbol string) *FunctionInfo {
+	return r.GetFunctions()[symbol]
+}
+
+// GetFunctions 返回所有的函数，键是函数的 C 符号。
+func (r *Repository) GetFunctions() map[string]*FunctionInfo {
+	if r.funcMap == nil {
+		r.funcMap = make(map[string]*FunctionInfo)
+		ns := r.Namespace
+		lists := [][]*FunctionInfo{ns.Functions}
+		for _, struct0 := range ns.Structs {
+			lists = append(lists, struct0.Functions, struct0.Constructors, struct0.Methods)
+		}
+		for _, union := range ns.Unions {
+			lists = append(lists, union.Functions, union.Constructors, union.Methods)
+		}
+		for _, class := range ns.Objects {
+			lists = append(lists, class.Functions, class.Constructors, class.Methods)
+		}
+		for _, ifc := range ns.Interfaces {
+			lists = append(lists, ifc.Functions, ifc.Methods)
+		}
+		for _, enum := range ns.Enums {
+			lists = append(lists, enum.Functions)
+		}
+		for _, enum := range ns.Bitfields {
+			lists = append(lists, enum.Functions)
+		}
+		for _, list := range lists {
+			for _, fn := range list {
+				if fn.CIdentifier != "" {
+					r.funcMap[fn.CIdentifier] = fn
+				}
+			}
+		}
+	}
+	return r.funcMap
+}
+
 func (r *Repository) CIncludes() []*Include {
 	var ret []*Include
 	for _, r := range r.Includes {
@@ -338,6 +391,9 @@ type BaseInfo struct {
 	CTypeAttr         string `xml:"type,attr"` // c:type attr
 	Deprecated        bool   `xml:"deprecated,attr"`
 	DeprecatedVersion string `xml:"deprecated-version,attr"`
+	Since             string `xml:"version,attr"` // 从哪个版本开始有
+	Doc               *Doc   `xml:"doc"`
+	DocDeprecated     *Doc   `xml:"doc-deprecated"`
 	cType             *CType
 }
 
@@ -356,9 +412,18 @@ type AliasInfo struct {
 }
 
 type Type struct {
-	Name     string `xml:"name,attr"`
-	CType    string `xml:"type,attr"`
-	ElemType *Type  `xml:"type"`
+	Name      string     `xml:"name,attr"`
+	CType     string     `xml:"type,attr"`
+	ElemTypes []*Type    `xml:"type"`
+	Array     *ArrayType `xml:"array"`
+}
+
+// ElemType 返回第一个元素类型，没有则返回 nil。
+func (t *Type) ElemType() *Type {
+	if len(t.ElemTypes) == 0 {
+		return nil
+	}
+	return t.ElemTypes[0]
 }
 
 type FunctionInfo struct {
@@ -377,13 +442,30 @@ type FunctionInfo struct {
 
 type CallbackInfo struct {
 	BaseInfo
-	ReturnValue *Parameter  `xml:"return-value"`
-	Parameters  *Parameters `xml:"parameters"`
+	ReturnValue    *Parameter  `xml:"return-value"`
+	Parameters     *Parameters `xml:"parameters"`
+	Throws         bool        `xml:"throws,attr"`
+	Introspectable bool        `xml:"introspectable,attr"`
+}
+
+// set Introspectable default value to true
+func (c *CallbackInfo) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type callbackInfo0 CallbackInfo
+	c0 := callbackInfo0{
+		Introspectable: true,
+	}
+	if err := d.DecodeElement(&c0, &start); err != nil {
+		return err
+	}
+	*c = CallbackInfo(c0)
+	return nil
 }
 
+// new type to prevent recursion
+type functionInfo0 FunctionInfo
+
 // set Introspectable default value to true
 func (f *FunctionInfo) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
-	type functionInfo0 FunctionInfo // new type to prevent recursion
 	f0 := functionInfo0{
 		Introspectable: true,
 	}
@@ -435,6 +517,7 @@ type Parameter struct {
 	AllowNone               bool       `xml:"allow-none,attr"`
 	Type                    *Type      `xml:"type"`
 	Array                   *ArrayType `xml:"array"`
+	Doc                     *Doc       `xml:"doc"`
 	LengthForParameter      *Parameter
 	ClosureForCallbackParam *Parameter
 	ClosureParam            *Parameter
@@ -463,41 +546,72 @@ func (p *Parameter) IsArray() bool {
 }
 
 type ArrayType struct {
-	Name           string         `xml:"name,attr"`
-	LengthIndex    int            `xml:"length,attr"`
-	ZeroTerminated bool           `xml:"zero-terminated,attr"`
-	FixedSize      int            `xml:"fixed-size,attr"`
-	CType          string         `xml:"type,attr"`
-	ElemType       *ArrayElemType `xml:"type"`
+	Name           string     `xml:"name,attr"`
+	LengthIndex    int        `xml:"length,attr"`
+	ZeroTerminated bool       `xml:"zero-terminated,attr"`
+	FixedSize      int        `xml:"fixed-size,attr"`
+	CType          string     `xml:"type,attr"`
+	ElemType       *Type      `xml:"type"`
+	ElemArray      *ArrayType `xml:"array"` // 元素也是数组
 
 	LengthParameter *Parameter
 }
 
-type ArrayElemType struct {
-	Name  string `xml:"name,attr"`
-	CType string `xml:"type,attr"`
-}
-
-// set LengthIndex default value to -1
+// set LengthIndex default value to -1,
+// ZeroTerminated default value is true only if there is no length or fixed size.
 func (arr *ArrayType) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	type arrayType0 ArrayType // new type to prevent recursion
 	a := arrayType0{
-		LengthIndex:    -1,
-		ZeroTerminated: true,
+		LengthIndex: -1,
 	}
 	if err := d.DecodeElement(&a, &start); err != nil {
 		return err
 	}
+	if !hasAttr(start, "zero-terminated") {
+		a.ZeroTerminated = a.LengthIndex == -1 && a.FixedSize == 0
+	}
 	*arr = ArrayType(a)
 	return nil
 }
 
+func hasAttr(start xml.StartElement, name string) bool {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == name {
+			return true
+		}
+	}
+	return false
+}
+
 type Property struct {
 	Name              string     `xml:"name,attr"`
+	Readable          bool       `xml:"readable,attr"`
 	Writable          bool       `xml:"writable,attr"`
+	Construct         bool       `xml:"construct,attr"`
 	ConstructOnly     bool       `xml:"construct-only,attr"`
 	TransferOwnership string     `xml:"transfer-ownership,attr"`
+	Introspectable    bool       `xml:"introspectable,attr"`
+	Type              *Type      `xml:"type"`
 	Array             *ArrayType `xml:"array"`
+	Deprecated        bool       `xml:"deprecated,attr"`
+	DeprecatedVersion string     `xml:"deprecated-version,attr"`
+	Since             string     `xml:"version,attr"` // 从哪个版本开始有
+	Doc               *Doc       `xml:"doc"`
+	DocDeprecated     *Doc       `xml:"doc-deprecated"`
+}
+
+// set Readable and Introspectable default value to true
+func (p *Property) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type property0 Property
+	p0 := property0{
+		Readable:       true,
+		Introspectable: true,
+	}
+	if err := d.DecodeElement(&p0, &start); err != nil {
+		return err
+	}
+	*p = Property(p0)
+	return nil
 }
 
 type Field struct {
@@ -505,8 +619,24 @@ type Field struct {
 	Readable bool          `xml:"readable,attr"`
 	Writable bool          `xml:"writable,attr"`
 	Private  bool          `xml:"private,attr"`
+	Bits     int           `xml:"bits,attr"`
 	Type     *Type         `xml:"type"`
+	Array    *ArrayType    `xml:"array"`
 	Callback *CallbackInfo `xml:"callback"`
+	Doc      *Doc          `xml:"doc"`
+}
+
+// set Readable default value to true
+func (f *Field) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type field0 Field
+	f0 := field0{
+		Readable: true,
+	}
+	if err := d.DecodeElement(&f0, &start); err != nil {
+		return err
+	}
+	*f = Field(f0)
+	return nil
 }
 
 type SignalInfo struct {
@@ -514,11 +644,45 @@ type SignalInfo struct {
 	When string `xml:"when,attr"`
 }
 
+// 需要自己的 UnmarshalXML，否则会使用 FunctionInfo 的，从而丢失 When。
+func (s *SignalInfo) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type signalInfo0 struct {
+		functionInfo0
+		When string `xml:"when,attr"`
+	}
+	s0 := signalInfo0{
+		functionInfo0: functionInfo0{Introspectable: true},
+	}
+	if err := d.DecodeElement(&s0, &start); err != nil {
+		return err
+	}
+	s.FunctionInfo = FunctionInfo(s0.functionInfo0)
+	s.When = s0.When
+	return nil
+}
+
 type VFuncInfo struct {
 	FunctionInfo
 	Invoker string `xml:"invoker,attr"`
 }
 
+// 需要自己的 UnmarshalXML，否则会使用 FunctionInfo 的，从而丢失 Invoker。
+func (v *VFuncInfo) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type vfuncInfo0 struct {
+		functionInfo0
+		Invoker string `xml:"invoker,attr"`
+	}
+	v0 := vfuncInfo0{
+		functionInfo0: functionInfo0{Introspectable: true},
+	}
+	if err := d.DecodeElement(&v0, &start); err != nil {
+		return err
+	}
+	v.FunctionInfo = FunctionInfo(v0.functionInfo0)
+	v.Invoker = v0.Invoker
+	return nil
+}
+
 type RegisteredTypeInfo struct {
 	BaseInfo
 	GlibGetType  string `xml:"get-type,attr"`
@@ -530,6 +694,7 @@ type StructInfo struct {
 	GlibIsGtypeStructFor string   `xml:"is-gtype-struct-for,attr"`
 	Fields               []*Field `xml:"field"`
 	Disguised            bool     `xml:"disguised,attr"`
+	Foreign              bool     `xml:"foreign,attr"`
 
 	Functions    []*FunctionInfo `xml:"function"`
 	Constructors []*FunctionInfo `xml:"constructor"`
@@ -539,6 +704,10 @@ type StructInfo struct {
 type UnionInfo struct {
 	RegisteredTypeInfo
 	Fields []*Field `xml:"field"`
+
+	Functions    []*FunctionInfo `xml:"function"`
+	Constructors []*FunctionInfo `xml:"constructor"`
+	Methods      []*FunctionInfo `xml:"method"`
 }
 
 type ConstantInfo struct {
@@ -549,7 +718,9 @@ type ConstantInfo struct {
 
 type EnumInfo struct {
 	RegisteredTypeInfo
-	Members []*EnumMember `xml:"member"`
+	GlibErrorDomain string          `xml:"error-domain,attr"`
+	Members         []*EnumMember   `xml:"member"`
+	Functions       []*FunctionInfo `xml:"function"`
 }
 
 type EnumMember struct {
@@ -557,6 +728,7 @@ type EnumMember struct {
 	Value       string `xml:"value,attr"`
 	CIdentifier string `xml:"identifier,attr"`
 	GlibNick    string `xml:"nick,attr"`
+	Doc         *Doc   `xml:"doc"`
 }
 
 type ObjectInfo struct {
@@ -564,6 +736,10 @@ type ObjectInfo struct {
 	CSymbolPrefixes string `xml:"symbol-prefix,attr"`
 	Parent          string `xml:"parent,attr"`
 	GlibTypeStruct  string `xml:"type-struct,attr"`
+	Abstract        bool   `xml:"abstract,attr"`
+	GlibFundamental bool   `xml:"fundamental,attr"`
+	GlibRefFunc     string `xml:"ref-func,attr"`
+	GlibUnrefFunc   string `xml:"unref-func,attr"`
 
 	Functions      []*FunctionInfo `xml:"function"`
 	Constructors   []*FunctionInfo `xml:"constructor"`
@@ -598,23 +774,106 @@ type InterfaceInfo struct {
 	VirtualMethods []*VFuncInfo    `xml:"virtual-method"`
 	Methods        []*FunctionInfo `xml:"method"`
 
-	Properties []*Property `xml:"property"`
+	Properties []*Property   `xml:"property"`
+	Signals    []*SignalInfo `xml:"signal"`
+
+	Prerequisites []*ImplementedInterface `xml:"prerequisite"`
+}
+
+// 由 PrependSearchPath 添加的目录，在前的优先
+var searchPaths []string
+
+// PrependSearchPath 把目录 dir 添加到 GIR 文件搜索路径的最前面，
+// 和 gi.PreprendRepositorySearchPath 对于 typelib 文件的作用类似。
+func PrependSearchPath(dir string) {
+	searchPaths = append([]string{dir}, searchPaths...)
+}
+
+// SearchPath 返回 GIR 文件的搜索路径，依次是 PrependSearchPath 添加的目录，
+// 环境变量 GI_GIR_PATH 中的目录，$XDG_DATA_HOME/gir-1.0，$XDG_DATA_DIRS 中每个目录下的 gir-1.0，
+// 最后是 /usr/share/gir-1.0。
+func SearchPath() []string {
+	var dirs []string
+	dirs = append(dirs, searchPaths...)
+	dirs = append(dirs, filepath.SplitList(os.Getenv("GI_GIR_PATH"))...)
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dataHome = filepath.Join(home, ".local/share")
+		}
+	}
+	if dataHome != "" {
+		dirs = append(dirs, filepath.Join(dataHome, "gir-1.0"))
+	}
+
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range filepath.SplitList(dataDirs) {
+		dirs = append(dirs, filepath.Join(dir, "gir-1.0"))
+	}
+	dirs = append(dirs, "/usr/share/gir-1.0")
+
+	// 去掉空的和重复的目录
+	result := make([]string, 0, len(dirs))
+	seen := make(map[string]struct{}, len(dirs))
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		if _, ok := seen[dir]; ok {
+			continue
+		}
+		seen[dir] = struct{}{}
+		result = append(result, dir)
+	}
+	return result
+}
+
+type GirNotFoundError struct {
+	File     string   // 文件名，比如 Gtk-3.0.gir
+	Searched []string // 找过的所有文件路径
+}
+
+func (err *GirNotFoundError) Error() string {
+	return fmt.Sprintf("not found %s, searched:\n\t%s", err.File,
+		strings.Join(err.Searched, "\n\t"))
+}
+
+// findGirFile 在 SearchPath() 返回的目录中查找 namespace-version.gir 文件
+func findGirFile(namespace, version string) (string, error) {
+	name := namespace + "-" + version + ".gir"
+	var searched []string
+	for _, dir := range SearchPath() {
+		filename := filepath.Join(dir, name)
+		info, err := os.Stat(filename)
+		if err == nil && !info.IsDir() {
+			return filename, nil
+		}
+		searched = append(searched, filename)
+	}
+	return "", &GirNotFoundError{File: name, Searched: searched}
 }
 
 func Load(namespace, version string) (*Repository, error) {
 	nsVer := namespace + "-" + version
 	if repo, ok := loadedRepos[nsVer]; ok {
-		fmt.Printf("// repo %s loaded\n", nsVer)
+		fmt.Fprintf(os.Stderr, "// repo %s loaded\n", nsVer)
 		return repo, nil
 	}
 
-	var err error
-	girFile := fmt.Sprintf("/usr/share/gir-1.0/%s-%s.gir", namespace, version)
-	fmt.Println("// load file:", girFile)
+	girFile, err := findGirFile(namespace, version)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintln(os.Stderr, "// load file:", girFile)
 	girFh, err := os.Open(girFile)
 	if err != nil {
 		return nil, err
 	}
+	defer girFh.Close()
 
 	var repo Repository
 	dec := xml.NewDecoder(bufio.NewReader(girFh))
@@ -623,7 +882,7 @@ func Load(namespace, version string) (*Repository, error) {
 		return nil, err
 	}
 	repo.postDecode()
-	fmt.Println("// end load", namespace, version)
+	fmt.Fprintln(os.Stderr, "// end load", namespace, version)
 	loadedRepos[nsVer] = &repo
 	return &repo, nil
 }
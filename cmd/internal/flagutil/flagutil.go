@@ -0,0 +1,37 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package flagutil 提供 girgen 和 check 共用的命令行参数类型。
+package flagutil
+
+import "strings"
+
+// Strings 是可以重复指定的字符串参数
+type Strings []string
+
+func (f *Strings) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *Strings) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
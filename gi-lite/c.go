@@ -50,7 +50,6 @@ package gi
 */
 import "C"
 import (
-	"errors"
 	"unsafe"
 )
 
@@ -73,6 +72,14 @@ func Free(p unsafe.Pointer) {
 	C.g_free(C.gpointer(p))
 }
 
+// ObjectRef 增加对象 p 的引用计数，p 为 nil 时什么都不做。
+func ObjectRef(p unsafe.Pointer) unsafe.Pointer {
+	if p != nil {
+		C.g_object_ref(C.gpointer(p))
+	}
+	return p
+}
+
 // 注意需要 free 这个字符串
 func CString(str string) unsafe.Pointer {
 	if str == NilStr {
@@ -137,6 +144,29 @@ func (r Repository) Info(namespace string, index int) BaseInfo {
 	return BaseInfo{P: unsafe.Pointer(ret)}
 }
 
+// g_irepository_get_version
+func (r Repository) Version(namespace string) string {
+	gNamespace := _GoStringToGString(namespace)
+	ret := C.g_irepository_get_version(r.p(), gNamespace)
+	C.free_gstring(gNamespace)
+	return _GStringToGoString(ret)
+}
+
+// HasSymbol 返回已经加载的命名空间 namespace 对应的库中是否有函数 symbol
+func (r Repository) HasSymbol(namespace, symbol string) bool {
+	gNamespace := _GoStringToGString(namespace)
+	typelib := C.g_irepository_require(r.p(), gNamespace, nil, 0, nil)
+	C.free_gstring(gNamespace)
+	if typelib == nil {
+		return false
+	}
+	gSymbol := _GoStringToGString(symbol)
+	var p C.gpointer
+	ret := C.g_typelib_symbol(typelib, gSymbol, &p)
+	C.free_gstring(gSymbol)
+	return ret != 0
+}
+
 type Typelib struct {
 	//c *C.GITypelib
 	P unsafe.Pointer
@@ -349,6 +379,44 @@ func (si StructInfo) Method(index int) FunctionInfo {
 	return WrapFunctionInfo(unsafe.Pointer(ret))
 }
 
+type EnumInfo struct {
+	RegisteredTypeInfo
+}
+
+func (ei EnumInfo) p() *C.GIEnumInfo {
+	return (*C.GIEnumInfo)(ei.P)
+}
+
+func WrapEnumInfo(p unsafe.Pointer) (ret EnumInfo) {
+	ret.P = p
+	return
+}
+
+// FindMethod 查找名字为 name 的方法，找不到时返回的 FunctionInfo 的 P 为 nil。
+// libgirepository 没有 g_enum_info_find_method，所以遍历所有方法。
+func (ei EnumInfo) FindMethod(name string) FunctionInfo {
+	num := ei.NumMethods()
+	for i := 0; i < num; i++ {
+		method := ei.Method(i)
+		if method.Name() == name {
+			return method
+		}
+		method.Unref()
+	}
+	return FunctionInfo{}
+}
+
+// g_enum_info_get_n_methods
+func (ei EnumInfo) NumMethods() int {
+	return int(C.g_enum_info_get_n_methods(ei.p()))
+}
+
+// g_enum_info_get_method
+func (ei EnumInfo) Method(index int) FunctionInfo {
+	ret := C.g_enum_info_get_method(ei.p(), C.gint(index))
+	return WrapFunctionInfo(unsafe.Pointer(ret))
+}
+
 type UnionInfo struct {
 	RegisteredTypeInfo
 }
@@ -480,11 +548,29 @@ func (invoker Invoker) Call(args []Argument, retVal *Argument, pOutArgs *Argumen
 
 // GError to os.Error, frees "err"
 func _GErrorToOSError(err *C.GError) (goerr error) {
-	goerr = errors.New(_GStringToGoString(err.message))
+	goerr = &GError{
+		Domain:     uint32(err.domain),
+		DomainName: _GStringToGoString(C.g_quark_to_string(err.domain)),
+		Code:       int(err.code),
+		Message:    _GStringToGoString(err.message),
+	}
 	C.g_error_free(err)
 	return
 }
 
+// NewGError 创建错误域为 domainName，错误码为 code 的 GError
+func NewGError(domainName string, code int, message string) *GError {
+	cDomainName := C.CString(domainName)
+	domain := C.g_quark_from_string((*C.gchar)(unsafe.Pointer(cDomainName)))
+	C.free(unsafe.Pointer(cDomainName))
+	return &GError{
+		Domain:     uint32(domain),
+		DomainName: domainName,
+		Code:       code,
+		Message:    message,
+	}
+}
+
 func ToError(ptr unsafe.Pointer) (err error) {
 	if ptr == nil {
 		return nil
@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EnumValue 是枚举或标志类型的一个值和它的 nick
+type EnumValue struct {
+	Value int
+	Nick  string
+}
+
+// EnumString 返回 value 在 values 中的 nick，找不到时返回类似 typeName(value) 的字符串。
+func EnumString(values []EnumValue, typeName string, value int) string {
+	for _, v := range values {
+		if v.Value == value {
+			return v.Nick
+		}
+	}
+	return typeName + "(" + strconv.Itoa(value) + ")"
+}
+
+// ParseEnum 根据 nick 在 values 中查找值
+func ParseEnum(values []EnumValue, typeName, nick string) (int, error) {
+	for _, v := range values {
+		if v.Nick == nick {
+			return v.Value, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid %s value %q", typeName, nick)
+}
+
+// FlagsString 把标志值表示为 a|b|c 的形式，按照 values 的顺序匹配，
+// 剩下未知的位用十六进制数表示。value 为 0 时返回值为 0 的 nick，没有则返回 "0"。
+func FlagsString(values []EnumValue, typeName string, value int) string {
+	if value == 0 {
+		for _, v := range values {
+			if v.Value == 0 {
+				return v.Nick
+			}
+		}
+		return "0"
+	}
+
+	var parts []string
+	rest := value
+	for _, v := range values {
+		if v.Value != 0 && rest&v.Value == v.Value {
+			parts = append(parts, v.Nick)
+			rest &^= v.Value
+		}
+	}
+	if rest != 0 {
+		parts = append(parts, fmt.Sprintf("0x%x", uint(rest)))
+	}
+	return strings.Join(parts, "|")
+}
+
+// ParseFlags 解析 a|b|c 形式的字符串，每个部分是 nick 或者数字。
+func ParseFlags(values []EnumValue, typeName, str string) (int, error) {
+	result := 0
+	for _, part := range strings.Split(str, "|") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		v, err := ParseEnum(values, typeName, part)
+		if err != nil {
+			num, err1 := strconv.ParseUint(part, 0, 64)
+			if err1 != nil {
+				return 0, err
+			}
+			v = int(num)
+		}
+		result |= v
+	}
+	return result, nil
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gi
+
+/*
+#include <girepository.h>
+
+typedef struct {
+	gpointer p;
+	GIObjectInfoUnrefFunction unref;
+	GType boxed_type;
+} _gi_unref_data;
+
+// 释放 p 的一个引用，boxed_type 不为 0 时使用 g_boxed_free，
+// 否则 unref 为 NULL 时使用 g_object_unref。
+static void _gi_unref(gpointer p, GIObjectInfoUnrefFunction unref, GType boxed_type) {
+	if (boxed_type) {
+		g_boxed_free(boxed_type, p);
+	} else if (unref) {
+		unref(p);
+	} else {
+		g_object_unref(p);
+	}
+}
+
+static gboolean _gi_unref_cb(gpointer data) {
+	_gi_unref_data *d = data;
+	_gi_unref(d->p, d->unref, d->boxed_type);
+	g_free(d);
+	return G_SOURCE_REMOVE;
+}
+
+// 在默认的主上下文中释放 p 的一个引用。如果可以获得默认的主上下文，即没有其他线程在运行它的主循环，
+// 比如程序没有使用主循环，就直接在当前线程释放，否则添加到主上下文中，由主循环的线程释放。
+static void _gi_unref_on_main_context(gpointer p, GIObjectInfoUnrefFunction unref, GType boxed_type) {
+	GMainContext *ctx = g_main_context_default();
+	if (g_main_context_acquire(ctx)) {
+		_gi_unref(p, unref, boxed_type);
+		g_main_context_release(ctx);
+		return;
+	}
+	_gi_unref_data *d = g_new(_gi_unref_data, 1);
+	d->p = p;
+	d->unref = unref;
+	d->boxed_type = boxed_type;
+	g_main_context_invoke(ctx, _gi_unref_cb, d);
+}
+
+static void _gi_call_ref_function(GIObjectInfoRefFunction ref, gpointer p) {
+	ref(p);
+}
+
+#cgo pkg-config: gobject-introspection-1.0
+*/
+import "C"
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// OwnedRef 持有一个实例的引用，被垃圾回收时在 GLib 默认的主上下文中释放这个引用，
+// 这样对象的 dispose 和 finalize 不会在 Go 的 finalizer 协程中运行。
+// 没有线程在运行默认主上下文的主循环时，引用在 finalizer 协程中直接释放；
+// 如果有线程获得了默认的主上下文却不运行主循环，引用要等到主循环运行时才会被释放。
+type OwnedRef struct {
+	p         unsafe.Pointer
+	unref     C.GIObjectInfoUnrefFunction
+	boxedType GType
+}
+
+func newOwnedRef(p unsafe.Pointer, unref C.GIObjectInfoUnrefFunction, boxedType GType) *OwnedRef {
+	r := &OwnedRef{p: p, unref: unref, boxedType: boxedType}
+	runtime.SetFinalizer(r, (*OwnedRef).finalize)
+	return r
+}
+
+func (r *OwnedRef) finalize() {
+	C._gi_unref_on_main_context(C.gpointer(r.p), r.unref, C.GType(r.boxedType))
+}
+
+// Release 立即释放持有的引用，之后不会再在垃圾回收时释放。只能调用一次，r 为 nil 时什么都不做。
+func (r *OwnedRef) Release() {
+	if r == nil {
+		return
+	}
+	runtime.SetFinalizer(r, nil)
+	C._gi_unref(C.gpointer(r.p), r.unref, C.GType(r.boxedType))
+}
+
+// AdoptObject 接管 GObject 实例 p 的一个引用。transferFull 为 true 表示调用者已经拥有一个引用，
+// 否则增加一个引用。浮动引用会被 g_object_ref_sink 转为普通引用。p 为 nil 时返回 nil。
+func AdoptObject(p unsafe.Pointer, transferFull bool) *OwnedRef {
+	if p == nil {
+		return nil
+	}
+	if !transferFull || C.g_object_is_floating(C.gpointer(p)) != 0 {
+		C.g_object_ref_sink(C.gpointer(p))
+	}
+	return newOwnedRef(p, nil, 0)
+}
+
+type refFuncs struct {
+	ref   C.GIObjectInfoRefFunction
+	unref C.GIObjectInfoUnrefFunction
+}
+
+var refFuncsCache struct {
+	mu sync.RWMutex
+	m  map[string]refFuncs
+}
+
+// getRefFuncs 返回命名空间 ns 中的对象类型 typeName 的 ref 和 unref 函数，会考虑它的父类型。
+func getRefFuncs(ns, typeName string) (refFuncs, bool) {
+	key := ns + "." + typeName
+	refFuncsCache.mu.RLock()
+	funcs, ok := refFuncsCache.m[key]
+	refFuncsCache.mu.RUnlock()
+	if ok {
+		return funcs, true
+	}
+
+	bi := defaultRepo.FindByName(ns, typeName)
+	if bi.P == nil {
+		return funcs, false
+	}
+	defer bi.Unref()
+	if bi.Type() != INFO_TYPE_OBJECT {
+		return funcs, false
+	}
+	oi := (*C.GIObjectInfo)(bi.P)
+	funcs.ref = C.g_object_info_get_ref_function_pointer(oi)
+	funcs.unref = C.g_object_info_get_unref_function_pointer(oi)
+	if funcs.ref == nil || funcs.unref == nil {
+		return funcs, false
+	}
+
+	refFuncsCache.mu.Lock()
+	if refFuncsCache.m == nil {
+		refFuncsCache.m = make(map[string]refFuncs)
+	}
+	refFuncsCache.m[key] = funcs
+	refFuncsCache.mu.Unlock()
+	return funcs, true
+}
+
+// AdoptFundamental 和 AdoptObject 类似，用于不是 GObject 的基本类型的实例，
+// 比如 GParamSpec，通过 GIR 中声明的 ref 和 unref 函数管理引用计数。
+// ns 和 typeName 是实例的类型在 GIR 中的命名空间和名字。
+// 找不到 ref 和 unref 函数时返回 nil，此时不接管引用。
+func AdoptFundamental(p unsafe.Pointer, ns, typeName string, transferFull bool) *OwnedRef {
+	if p == nil {
+		return nil
+	}
+	funcs, ok := getRefFuncs(ns, typeName)
+	if !ok {
+		_, _ = fmt.Fprintf(os.Stderr, "not found ref and unref functions of type %v.%v\n", ns, typeName)
+		return nil
+	}
+	if !transferFull {
+		C._gi_call_ref_function(funcs.ref, C.gpointer(p))
+	}
+	return newOwnedRef(p, funcs.unref, 0)
+}
+
+// BoxedCopy 用 g_boxed_copy 复制 boxed 类型 gType 的实例 p，p 为 nil 时返回 nil。
+func BoxedCopy(gType GType, p unsafe.Pointer) unsafe.Pointer {
+	if p == nil {
+		return nil
+	}
+	return unsafe.Pointer(C.g_boxed_copy(C.GType(gType), C.gconstpointer(p)))
+}
+
+// BoxedFree 用 g_boxed_free 释放 boxed 类型 gType 的实例 p，p 为 nil 时什么都不做。
+func BoxedFree(gType GType, p unsafe.Pointer) {
+	if p == nil {
+		return
+	}
+	C.g_boxed_free(C.GType(gType), C.gpointer(p))
+}
+
+// AdoptBoxed 接管 boxed 类型 gType 的实例 p，被垃圾回收时用 g_boxed_free 释放它。
+// transferFull 为 false 时 p 属于别的对象，先复制一份再接管复制出的实例。
+// 返回被接管的实例的指针，p 为 nil 时返回 nil, nil。
+func AdoptBoxed(p unsafe.Pointer, gType GType, transferFull bool) (unsafe.Pointer, *OwnedRef) {
+	if p == nil {
+		return nil, nil
+	}
+	if !transferFull {
+		p = BoxedCopy(gType, p)
+	}
+	return p, newOwnedRef(p, nil, gType)
+}
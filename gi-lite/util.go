@@ -22,6 +22,7 @@
 package gi
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
@@ -57,6 +58,52 @@ func GetFunc(id uint) func(interface{}) {
 	return fn
 }
 
+// ErrNoFreeTrampoline 表示跳板函数池中的跳板函数都被占用了
+var ErrNoFreeTrampoline = errors.New("no free trampoline")
+
+// TrampolinePool 管理没有 user_data 参数的回调类型的一组 C 跳板函数，
+// 每个跳板函数同一时间只能绑定一个 Go 函数，跳板函数用序号 slot 表示。
+type TrampolinePool struct {
+	mu  sync.RWMutex
+	fns []func(interface{})
+}
+
+func NewTrampolinePool(size int) *TrampolinePool {
+	return &TrampolinePool{
+		fns: make([]func(interface{}), size),
+	}
+}
+
+// Alloc 找到一个空闲的跳板函数绑定 fn，返回跳板函数的序号。
+func (p *TrampolinePool) Alloc(fn func(v interface{})) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for slot, f := range p.fns {
+		if f == nil {
+			p.fns[slot] = fn
+			return slot, nil
+		}
+	}
+	return -1, ErrNoFreeTrampoline
+}
+
+// Free 释放跳板函数 slot，使它可以再次被分配。
+func (p *TrampolinePool) Free(slot int) {
+	p.mu.Lock()
+	if slot >= 0 && slot < len(p.fns) {
+		p.fns[slot] = nil
+	}
+	p.mu.Unlock()
+}
+
+// Get 返回跳板函数 slot 绑定的 Go 函数，slot 已经被释放时返回 nil。
+func (p *TrampolinePool) Get(slot int) func(interface{}) {
+	p.mu.RLock()
+	fn := p.fns[slot]
+	p.mu.RUnlock()
+	return fn
+}
+
 type InvokerCache struct {
 	namespace string
 	mu        sync.RWMutex
@@ -189,9 +236,12 @@ func (ic *InvokerCache) Get1(id uint, ns, nameLv1, nameLv2 string, idxLv1, idxLv
 		funcInfo = WrapFunctionInfo(bi.P)
 		// NOTE: 不要再 unref funcInfo 了, 因为所有权在 bi。
 
-	case INFO_TYPE_INTERFACE, INFO_TYPE_OBJECT, INFO_TYPE_STRUCT, INFO_TYPE_UNION:
+	case INFO_TYPE_INTERFACE, INFO_TYPE_OBJECT, INFO_TYPE_STRUCT, INFO_TYPE_UNION,
+		INFO_TYPE_ENUM, INFO_TYPE_FLAGS:
 		var infoM infoWithMethod
 		switch type0 {
+		case INFO_TYPE_ENUM, INFO_TYPE_FLAGS:
+			infoM = WrapEnumInfo(bi.P)
 		case INFO_TYPE_INTERFACE:
 			infoM = WrapInterfaceInfo(bi.P)
 		case INFO_TYPE_OBJECT:
@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gi
+
+import "sync"
+
+// SinceSymbol 表示从版本 Major.Minor 开始才有的一个函数
+type SinceSymbol struct {
+	Major  int
+	Minor  int
+	Symbol string
+}
+
+var sinceCache struct {
+	mu sync.Mutex
+	m  map[string]bool // 键是命名空间和函数的 C 符号
+}
+
+func hasSymbol(ns, symbol string) bool {
+	key := ns + "." + symbol
+	sinceCache.mu.Lock()
+	defer sinceCache.mu.Unlock()
+	ok, found := sinceCache.m[key]
+	if !found {
+		if sinceCache.m == nil {
+			sinceCache.m = make(map[string]bool)
+		}
+		ok = defaultRepo.HasSymbol(ns, symbol)
+		sinceCache.m[key] = ok
+	}
+	return ok
+}
+
+// Since 返回运行时加载的命名空间 ns 对应的库的版本是否不低于 major.minor。
+// 只用 probeSymbol 找到的函数是否在库中来判断，不和 Repository.Version 返回的 API 版本比较，
+// 因为库的版本可以和 API 版本无关，比如 Vte-2.91 的库版本是 0.50 这样的。
+// symbols 按版本从低到高排列，major.minor 比 symbols 中的版本都新时返回 false。
+func Since(ns string, symbols []SinceSymbol, major, minor int) bool {
+	return since(symbols, major, minor, func(symbol string) bool {
+		return hasSymbol(ns, symbol)
+	})
+}
+
+// since 是 Since 的实现，has 判断库中是否有函数 symbol。
+func since(symbols []SinceSymbol, major, minor int, has func(symbol string) bool) bool {
+	symbol, ok := probeSymbol(symbols, major, minor)
+	if !ok {
+		return false
+	}
+	if symbol == "" {
+		// 在 major.minor 及之前的版本中都没有新加函数
+		return true
+	}
+	return has(symbol)
+}
+
+// probeSymbol 返回用来判断库的版本是否不低于 major.minor 的函数，即 symbols 中版本不高于 major.minor 的最后一个函数。
+// 没有新加函数的版本，比如 3.21，和它之前最近的有新加函数的版本无法区分，所以使用那个版本的函数。
+// major.minor 比 symbols 中的版本都新时 ok 为 false，没有版本不高于 major.minor 的函数时 symbol 为空。
+func probeSymbol(symbols []SinceSymbol, major, minor int) (symbol string, ok bool) {
+	for _, sym := range symbols {
+		if sym.Major > major || (sym.Major == major && sym.Minor > minor) {
+			return symbol, true
+		}
+		symbol = sym.Symbol
+	}
+	if len(symbols) > 0 {
+		last := symbols[len(symbols)-1]
+		if last.Major == major && last.Minor == minor {
+			return last.Symbol, true
+		}
+	}
+	return "", false
+}
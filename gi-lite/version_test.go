@@ -0,0 +1,99 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbeSymbol(t *testing.T) {
+	symbols := []SinceSymbol{
+		{Major: 3, Minor: 18, Symbol: "a_3_18"},
+		{Major: 3, Minor: 20, Symbol: "a_3_20"},
+		{Major: 3, Minor: 22, Symbol: "a_3_22"},
+	}
+
+	symbol, ok := probeSymbol(symbols, 3, 20)
+	assert.True(t, ok)
+	assert.Equal(t, "a_3_20", symbol)
+
+	// 3.21 没有新加函数，使用 3.20 的
+	symbol, ok = probeSymbol(symbols, 3, 21)
+	assert.True(t, ok)
+	assert.Equal(t, "a_3_20", symbol)
+
+	symbol, ok = probeSymbol(symbols, 3, 22)
+	assert.True(t, ok)
+	assert.Equal(t, "a_3_22", symbol)
+
+	symbol, ok = probeSymbol(symbols, 3, 16)
+	assert.True(t, ok)
+	assert.Equal(t, "", symbol)
+
+	_, ok = probeSymbol(symbols, 3, 24)
+	assert.False(t, ok)
+
+	_, ok = probeSymbol(nil, 3, 20)
+	assert.False(t, ok)
+}
+
+// newHasSymbol 返回判断库中是否有函数的 has，库中有 symbols 中版本不高于 major.minor 的函数
+func newHasSymbol(symbols []SinceSymbol, major, minor int) func(symbol string) bool {
+	return func(symbol string) bool {
+		for _, sym := range symbols {
+			if sym.Symbol == symbol {
+				return sym.Major < major || (sym.Major == major && sym.Minor <= minor)
+			}
+		}
+		return false
+	}
+}
+
+func TestSince(t *testing.T) {
+	// Vte-2.91 的 API 版本是 2.91，库的版本是 0.xx
+	vteSymbols := []SinceSymbol{
+		{Major: 0, Minor: 46, Symbol: "vte_0_46"},
+		{Major: 0, Minor: 48, Symbol: "vte_0_48"},
+		{Major: 0, Minor: 50, Symbol: "vte_0_50"},
+	}
+	has := newHasSymbol(vteSymbols, 0, 48)
+	assert.True(t, since(vteSymbols, 0, 40, has))
+	assert.True(t, since(vteSymbols, 0, 46, has))
+	assert.True(t, since(vteSymbols, 0, 48, has))
+	assert.False(t, since(vteSymbols, 0, 50, has))
+	assert.False(t, since(vteSymbols, 2, 91, has))
+
+	// Atk-1.0 的 API 版本是 1.0，库的版本是 2.xx
+	atkSymbols := []SinceSymbol{
+		{Major: 2, Minor: 12, Symbol: "atk_2_12"},
+		{Major: 2, Minor: 30, Symbol: "atk_2_30"},
+	}
+	has = newHasSymbol(atkSymbols, 2, 30)
+	assert.True(t, since(atkSymbols, 1, 0, has))
+	assert.True(t, since(atkSymbols, 2, 12, has))
+	assert.True(t, since(atkSymbols, 2, 30, has))
+	has = newHasSymbol(atkSymbols, 2, 28)
+	assert.True(t, since(atkSymbols, 2, 28, has))
+	assert.False(t, since(atkSymbols, 2, 30, has))
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gi
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// GError 对应 GLib 的 GError，保留了错误域和错误码，可以用 errors.Is 和 errors.As 判断错误。
+type GError struct {
+	Domain     uint32 // 错误域的 quark
+	DomainName string // 错误域的名称，比如 g-io-error-quark
+	Code       int
+	Message    string
+}
+
+// GErrorCode 由带有错误域的枚举类型实现，枚举值可以作为 errors.Is 的 target，
+// 枚举类型的指针可以作为 errors.As 的 target。
+type GErrorCode interface {
+	error
+	GErrorDomain() string
+	GErrorCode() int
+}
+
+func (e *GError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: code %d", e.DomainName, e.Code)
+}
+
+// Is 在 target 的错误域和错误码都与 e 相同时返回 true，不比较错误信息。
+func (e *GError) Is(target error) bool {
+	switch t := target.(type) {
+	case *GError:
+		return e.DomainName == t.DomainName && e.Code == t.Code
+	case GErrorCode:
+		return e.DomainName == t.GErrorDomain() && e.Code == t.GErrorCode()
+	}
+	return false
+}
+
+// As 在 target 是指向同一个错误域的枚举类型的指针时，把错误码赋值给它。
+func (e *GError) As(target interface{}) bool {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return false
+	}
+	elem := rv.Elem()
+	code, ok := elem.Interface().(GErrorCode)
+	if !ok || code.GErrorDomain() != e.DomainName {
+		return false
+	}
+	switch elem.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		elem.SetInt(int64(e.Code))
+		return true
+	}
+	return false
+}
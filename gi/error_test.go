@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gi
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testErrorEnum 模拟生成的带有错误域的枚举类型
+type testErrorEnum int
+
+const (
+	testErrorFailed testErrorEnum = iota
+	testErrorNotFound
+)
+
+func (v testErrorEnum) Error() string {
+	return fmt.Sprintf("test error %d", int(v))
+}
+
+func (v testErrorEnum) GErrorDomain() string {
+	return "test-error-quark"
+}
+
+func (v testErrorEnum) GErrorCode() int {
+	return int(v)
+}
+
+// otherErrorEnum 的错误码与 testErrorEnum 相同，但是错误域不同
+type otherErrorEnum int
+
+func (v otherErrorEnum) Error() string {
+	return fmt.Sprintf("other error %d", int(v))
+}
+
+func (v otherErrorEnum) GErrorDomain() string {
+	return "other-error-quark"
+}
+
+func (v otherErrorEnum) GErrorCode() int {
+	return int(v)
+}
+
+func newTestGError(code int) error {
+	return &GError{DomainName: "test-error-quark", Code: code, Message: "not found"}
+}
+
+func TestGErrorError(t *testing.T) {
+	assert.Equal(t, "not found", newTestGError(1).Error())
+	err := &GError{DomainName: "test-error-quark", Code: 1}
+	assert.Equal(t, "test-error-quark: code 1", err.Error())
+}
+
+func TestGErrorIs(t *testing.T) {
+	err := fmt.Errorf("load: %w", newTestGError(int(testErrorNotFound)))
+
+	// 枚举值
+	assert.True(t, errors.Is(err, testErrorNotFound))
+	assert.False(t, errors.Is(err, testErrorFailed))
+	assert.False(t, errors.Is(err, otherErrorEnum(testErrorNotFound)))
+
+	// GError 只比较错误域和错误码
+	assert.True(t, errors.Is(err, &GError{DomainName: "test-error-quark", Code: 1, Message: "x"}))
+	assert.False(t, errors.Is(err, &GError{DomainName: "test-error-quark", Code: 0}))
+	assert.False(t, errors.Is(err, &GError{DomainName: "other-error-quark", Code: 1}))
+}
+
+func TestGErrorAs(t *testing.T) {
+	err := fmt.Errorf("load: %w", newTestGError(int(testErrorNotFound)))
+
+	var code testErrorEnum
+	assert.True(t, errors.As(err, &code))
+	assert.Equal(t, testErrorNotFound, code)
+
+	var other otherErrorEnum
+	assert.False(t, errors.As(err, &other))
+
+	var gErr *GError
+	if assert.True(t, errors.As(err, &gErr)) {
+		assert.Equal(t, 1, gErr.Code)
+		assert.Equal(t, "not found", gErr.Message)
+	}
+
+	assert.False(t, (&GError{}).As(nil))
+}
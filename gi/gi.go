@@ -30,6 +30,7 @@ static inline void free_string(char *p) { free(p); }
 static inline void free_gstring(gchar *p) { if (p) g_free(p); }
 static inline char *gpointer_to_charp(gpointer p) { return p; }
 static inline gchar **next_gcharptr(gchar **s) { return s+1; }
+static inline gboolean _g_type_is_boxed(GType t) { return G_TYPE_IS_BOXED(t); }
 
 static void wrap_ffi_call(ffi_cif *cif, void (*fn)(void), void *rvalue,
 	GIArgument *args, int n_args, void *out_args) {
@@ -49,14 +50,20 @@ static void wrap_ffi_call(ffi_cif *cif, void (*fn)(void), void *rvalue,
 */
 import "C"
 import (
-	"errors"
 	"fmt"
 	"strings"
 	"unsafe"
+
+	"github.com/electricface/go-gir3/gi/gitypes"
 )
 
 type GType C.GType
 
+// G_TYPE_IS_BOXED
+func (t GType) IsBoxed() bool {
+	return C._g_type_is_boxed(C.GType(t)) != 0
+}
+
 // utils
 
 // Convert GSList containing strings to []string
@@ -112,11 +119,29 @@ func _CStringToGoString(s *C.char) string {
 
 // GError to os.Error, frees "err"
 func _GErrorToOSError(err *C.GError) (goerr error) {
-	goerr = errors.New(_GStringToGoString(err.message))
+	goerr = &GError{
+		Domain:     uint32(err.domain),
+		DomainName: _GStringToGoString(C.g_quark_to_string(err.domain)),
+		Code:       int(err.code),
+		Message:    _GStringToGoString(err.message),
+	}
 	C.g_error_free(err)
 	return
 }
 
+// NewGError 创建错误域为 domainName，错误码为 code 的 GError
+func NewGError(domainName string, code int, message string) *GError {
+	cDomainName := C.CString(domainName)
+	domain := C.g_quark_from_string((*C.gchar)(unsafe.Pointer(cDomainName)))
+	C.free(unsafe.Pointer(cDomainName))
+	return &GError{
+		Domain:     uint32(domain),
+		DomainName: domainName,
+		Code:       code,
+		Message:    message,
+	}
+}
+
 func ToError(ptr unsafe.Pointer) (err error) {
 	if ptr == nil {
 		return nil
@@ -256,36 +281,31 @@ func ToUnionInfo(bil BaseInfoLike) *UnionInfo {
 // InfoType
 //------------------------------------------------------------------------------
 
-type InfoType int
+type InfoType = gitypes.InfoType
 
 const (
-	INFO_TYPE_INVALID    InfoType = C.GI_INFO_TYPE_INVALID
-	INFO_TYPE_FUNCTION   InfoType = C.GI_INFO_TYPE_FUNCTION
-	INFO_TYPE_CALLBACK   InfoType = C.GI_INFO_TYPE_CALLBACK
-	INFO_TYPE_STRUCT     InfoType = C.GI_INFO_TYPE_STRUCT
-	INFO_TYPE_BOXED      InfoType = C.GI_INFO_TYPE_BOXED
-	INFO_TYPE_ENUM       InfoType = C.GI_INFO_TYPE_ENUM
-	INFO_TYPE_FLAGS      InfoType = C.GI_INFO_TYPE_FLAGS
-	INFO_TYPE_OBJECT     InfoType = C.GI_INFO_TYPE_OBJECT
-	INFO_TYPE_INTERFACE  InfoType = C.GI_INFO_TYPE_INTERFACE
-	INFO_TYPE_CONSTANT   InfoType = C.GI_INFO_TYPE_CONSTANT
-	INFO_TYPE_INVALID_0  InfoType = C.GI_INFO_TYPE_INVALID_0
-	INFO_TYPE_UNION      InfoType = C.GI_INFO_TYPE_UNION
-	INFO_TYPE_VALUE      InfoType = C.GI_INFO_TYPE_VALUE
-	INFO_TYPE_SIGNAL     InfoType = C.GI_INFO_TYPE_SIGNAL
-	INFO_TYPE_VFUNC      InfoType = C.GI_INFO_TYPE_VFUNC
-	INFO_TYPE_PROPERTY   InfoType = C.GI_INFO_TYPE_PROPERTY
-	INFO_TYPE_FIELD      InfoType = C.GI_INFO_TYPE_FIELD
-	INFO_TYPE_ARG        InfoType = C.GI_INFO_TYPE_ARG
-	INFO_TYPE_TYPE       InfoType = C.GI_INFO_TYPE_TYPE
-	INFO_TYPE_UNRESOLVED InfoType = C.GI_INFO_TYPE_UNRESOLVED
+	INFO_TYPE_INVALID    = gitypes.INFO_TYPE_INVALID
+	INFO_TYPE_FUNCTION   = gitypes.INFO_TYPE_FUNCTION
+	INFO_TYPE_CALLBACK   = gitypes.INFO_TYPE_CALLBACK
+	INFO_TYPE_STRUCT     = gitypes.INFO_TYPE_STRUCT
+	INFO_TYPE_BOXED      = gitypes.INFO_TYPE_BOXED
+	INFO_TYPE_ENUM       = gitypes.INFO_TYPE_ENUM
+	INFO_TYPE_FLAGS      = gitypes.INFO_TYPE_FLAGS
+	INFO_TYPE_OBJECT     = gitypes.INFO_TYPE_OBJECT
+	INFO_TYPE_INTERFACE  = gitypes.INFO_TYPE_INTERFACE
+	INFO_TYPE_CONSTANT   = gitypes.INFO_TYPE_CONSTANT
+	INFO_TYPE_INVALID_0  = gitypes.INFO_TYPE_INVALID_0
+	INFO_TYPE_UNION      = gitypes.INFO_TYPE_UNION
+	INFO_TYPE_VALUE      = gitypes.INFO_TYPE_VALUE
+	INFO_TYPE_SIGNAL     = gitypes.INFO_TYPE_SIGNAL
+	INFO_TYPE_VFUNC      = gitypes.INFO_TYPE_VFUNC
+	INFO_TYPE_PROPERTY   = gitypes.INFO_TYPE_PROPERTY
+	INFO_TYPE_FIELD      = gitypes.INFO_TYPE_FIELD
+	INFO_TYPE_ARG        = gitypes.INFO_TYPE_ARG
+	INFO_TYPE_TYPE       = gitypes.INFO_TYPE_TYPE
+	INFO_TYPE_UNRESOLVED = gitypes.INFO_TYPE_UNRESOLVED
 )
 
-// g_info_type_to_string
-func (it InfoType) String() string {
-	return _GStringToGoString(C.g_info_type_to_string(C.GIInfoType(it)))
-}
-
 //------------------------------------------------------------------------------
 // Repository
 //------------------------------------------------------------------------------
@@ -294,10 +314,10 @@ type Repository struct {
 	c *C.GIRepository
 }
 
-type RepositoryLoadFlags int
+type RepositoryLoadFlags = gitypes.RepositoryLoadFlags
 
 const (
-	REPOSITORY_LOAD_FLAG_LAZY RepositoryLoadFlags = C.G_IREPOSITORY_LOAD_FLAG_LAZY
+	REPOSITORY_LOAD_FLAG_LAZY = gitypes.REPOSITORY_LOAD_FLAG_LAZY
 )
 
 // g_irepository_get_default
@@ -566,73 +586,31 @@ type ArgInfo struct {
 	BaseInfo
 }
 
-type Direction int
-type ScopeType int
-type Transfer int
+type Direction = gitypes.Direction
 
 const (
-	DIRECTION_IN    Direction = C.GI_DIRECTION_IN
-	DIRECTION_OUT   Direction = C.GI_DIRECTION_OUT
-	DIRECTION_INOUT Direction = C.GI_DIRECTION_INOUT
+	DIRECTION_IN    = gitypes.DIRECTION_IN
+	DIRECTION_OUT   = gitypes.DIRECTION_OUT
+	DIRECTION_INOUT = gitypes.DIRECTION_INOUT
 )
 
-func (d Direction) String() (str string) {
-	switch d {
-	case DIRECTION_IN:
-		str = "in"
-	case DIRECTION_OUT:
-		str = "out"
-	case DIRECTION_INOUT:
-		str = "inout"
-	default:
-		str = fmt.Sprintf("invalid-direction(%d)", d)
-	}
-	return
-}
+type ScopeType = gitypes.ScopeType
 
 const (
-	SCOPE_TYPE_INVALID  ScopeType = C.GI_SCOPE_TYPE_INVALID
-	SCOPE_TYPE_CALL     ScopeType = C.GI_SCOPE_TYPE_CALL
-	SCOPE_TYPE_ASYNC    ScopeType = C.GI_SCOPE_TYPE_ASYNC
-	SCOPE_TYPE_NOTIFIED ScopeType = C.GI_SCOPE_TYPE_NOTIFIED
+	SCOPE_TYPE_INVALID  = gitypes.SCOPE_TYPE_INVALID
+	SCOPE_TYPE_CALL     = gitypes.SCOPE_TYPE_CALL
+	SCOPE_TYPE_ASYNC    = gitypes.SCOPE_TYPE_ASYNC
+	SCOPE_TYPE_NOTIFIED = gitypes.SCOPE_TYPE_NOTIFIED
 )
 
-func (s ScopeType) String() (str string) {
-	switch s {
-	case SCOPE_TYPE_INVALID:
-		str = "invalid"
-	case SCOPE_TYPE_CALL:
-		str = "call"
-	case SCOPE_TYPE_ASYNC:
-		str = "async"
-	case SCOPE_TYPE_NOTIFIED:
-		str = "notified"
-	default:
-		str = fmt.Sprintf("invalid-scope-type(%d)", s)
-	}
-	return
-}
+type Transfer = gitypes.Transfer
 
 const (
-	TRANSFER_NOTHING    Transfer = C.GI_TRANSFER_NOTHING
-	TRANSFER_CONTAINER  Transfer = C.GI_TRANSFER_CONTAINER
-	TRANSFER_EVERYTHING Transfer = C.GI_TRANSFER_EVERYTHING
+	TRANSFER_NOTHING    = gitypes.TRANSFER_NOTHING
+	TRANSFER_CONTAINER  = gitypes.TRANSFER_CONTAINER
+	TRANSFER_EVERYTHING = gitypes.TRANSFER_EVERYTHING
 )
 
-func (t Transfer) String() (str string) {
-	switch t {
-	case TRANSFER_NOTHING:
-		str = "nothing"
-	case TRANSFER_CONTAINER:
-		str = "container"
-	case TRANSFER_EVERYTHING:
-		str = "everything"
-	default:
-		str = fmt.Sprintf("invalid-transfer(%d)", t)
-	}
-	return
-}
-
 // g_arg_info_get_direction
 func (ai *ArgInfo) Direction() Direction {
 	return Direction(C.g_arg_info_get_direction((*C.GIArgInfo)(ai.c)))
@@ -771,11 +749,11 @@ type FieldInfo struct {
 	BaseInfo
 }
 
-type FieldInfoFlags int
+type FieldInfoFlags = gitypes.FieldInfoFlags
 
 const (
-	FIELD_IS_READABLE FieldInfoFlags = C.GI_FIELD_IS_READABLE
-	FIELD_IS_WRITABLE FieldInfoFlags = C.GI_FIELD_IS_WRITABLE
+	FIELD_IS_READABLE = gitypes.FIELD_IS_READABLE
+	FIELD_IS_WRITABLE = gitypes.FIELD_IS_WRITABLE
 )
 
 // g_field_info_get_flags
@@ -814,7 +792,20 @@ type PropertyInfo struct {
 	BaseInfo
 }
 
-//GParamFlags         g_property_info_get_flags           (GIPropertyInfo *info);
+type ParamFlags = gitypes.ParamFlags
+
+const (
+	PARAM_READABLE       = gitypes.PARAM_READABLE
+	PARAM_WRITABLE       = gitypes.PARAM_WRITABLE
+	PARAM_CONSTRUCT      = gitypes.PARAM_CONSTRUCT
+	PARAM_CONSTRUCT_ONLY = gitypes.PARAM_CONSTRUCT_ONLY
+	PARAM_DEPRECATED     = gitypes.PARAM_DEPRECATED
+)
+
+// g_property_info_get_flags
+func (pi *PropertyInfo) Flags() ParamFlags {
+	return ParamFlags(C.g_property_info_get_flags((*C.GIPropertyInfo)(pi.c)))
+}
 
 // g_property_info_get_type
 func (pi *PropertyInfo) Type() *TypeInfo {
@@ -835,49 +826,42 @@ type TypeInfo struct {
 	BaseInfo
 }
 
-type ArrayType int
-type TypeTag int
+type ArrayType = gitypes.ArrayType
 
 const (
-	ARRAY_TYPE_C          ArrayType = C.GI_ARRAY_TYPE_C
-	ARRAY_TYPE_ARRAY      ArrayType = C.GI_ARRAY_TYPE_ARRAY
-	ARRAY_TYPE_PTR_ARRAY  ArrayType = C.GI_ARRAY_TYPE_PTR_ARRAY
-	ARRAY_TYPE_BYTE_ARRAY ArrayType = C.GI_ARRAY_TYPE_BYTE_ARRAY
+	ARRAY_TYPE_C          = gitypes.ARRAY_TYPE_C
+	ARRAY_TYPE_ARRAY      = gitypes.ARRAY_TYPE_ARRAY
+	ARRAY_TYPE_PTR_ARRAY  = gitypes.ARRAY_TYPE_PTR_ARRAY
+	ARRAY_TYPE_BYTE_ARRAY = gitypes.ARRAY_TYPE_BYTE_ARRAY
 )
 
+type TypeTag = gitypes.TypeTag
+
 const (
-	TYPE_TAG_VOID      TypeTag = C.GI_TYPE_TAG_VOID
-	TYPE_TAG_BOOLEAN   TypeTag = C.GI_TYPE_TAG_BOOLEAN
-	TYPE_TAG_INT8      TypeTag = C.GI_TYPE_TAG_INT8
-	TYPE_TAG_UINT8     TypeTag = C.GI_TYPE_TAG_UINT8
-	TYPE_TAG_INT16     TypeTag = C.GI_TYPE_TAG_INT16
-	TYPE_TAG_UINT16    TypeTag = C.GI_TYPE_TAG_UINT16
-	TYPE_TAG_INT32     TypeTag = C.GI_TYPE_TAG_INT32
-	TYPE_TAG_UINT32    TypeTag = C.GI_TYPE_TAG_UINT32
-	TYPE_TAG_INT64     TypeTag = C.GI_TYPE_TAG_INT64
-	TYPE_TAG_UINT64    TypeTag = C.GI_TYPE_TAG_UINT64
-	TYPE_TAG_FLOAT     TypeTag = C.GI_TYPE_TAG_FLOAT
-	TYPE_TAG_DOUBLE    TypeTag = C.GI_TYPE_TAG_DOUBLE
-	TYPE_TAG_GTYPE     TypeTag = C.GI_TYPE_TAG_GTYPE
-	TYPE_TAG_UTF8      TypeTag = C.GI_TYPE_TAG_UTF8
-	TYPE_TAG_FILENAME  TypeTag = C.GI_TYPE_TAG_FILENAME
-	TYPE_TAG_ARRAY     TypeTag = C.GI_TYPE_TAG_ARRAY
-	TYPE_TAG_INTERFACE TypeTag = C.GI_TYPE_TAG_INTERFACE
-	TYPE_TAG_GLIST     TypeTag = C.GI_TYPE_TAG_GLIST
-	TYPE_TAG_GSLIST    TypeTag = C.GI_TYPE_TAG_GSLIST
-	TYPE_TAG_GHASH     TypeTag = C.GI_TYPE_TAG_GHASH
-	TYPE_TAG_ERROR     TypeTag = C.GI_TYPE_TAG_ERROR
-	TYPE_TAG_UNICHAR   TypeTag = C.GI_TYPE_TAG_UNICHAR
+	TYPE_TAG_VOID      = gitypes.TYPE_TAG_VOID
+	TYPE_TAG_BOOLEAN   = gitypes.TYPE_TAG_BOOLEAN
+	TYPE_TAG_INT8      = gitypes.TYPE_TAG_INT8
+	TYPE_TAG_UINT8     = gitypes.TYPE_TAG_UINT8
+	TYPE_TAG_INT16     = gitypes.TYPE_TAG_INT16
+	TYPE_TAG_UINT16    = gitypes.TYPE_TAG_UINT16
+	TYPE_TAG_INT32     = gitypes.TYPE_TAG_INT32
+	TYPE_TAG_UINT32    = gitypes.TYPE_TAG_UINT32
+	TYPE_TAG_INT64     = gitypes.TYPE_TAG_INT64
+	TYPE_TAG_UINT64    = gitypes.TYPE_TAG_UINT64
+	TYPE_TAG_FLOAT     = gitypes.TYPE_TAG_FLOAT
+	TYPE_TAG_DOUBLE    = gitypes.TYPE_TAG_DOUBLE
+	TYPE_TAG_GTYPE     = gitypes.TYPE_TAG_GTYPE
+	TYPE_TAG_UTF8      = gitypes.TYPE_TAG_UTF8
+	TYPE_TAG_FILENAME  = gitypes.TYPE_TAG_FILENAME
+	TYPE_TAG_ARRAY     = gitypes.TYPE_TAG_ARRAY
+	TYPE_TAG_INTERFACE = gitypes.TYPE_TAG_INTERFACE
+	TYPE_TAG_GLIST     = gitypes.TYPE_TAG_GLIST
+	TYPE_TAG_GSLIST    = gitypes.TYPE_TAG_GSLIST
+	TYPE_TAG_GHASH     = gitypes.TYPE_TAG_GHASH
+	TYPE_TAG_ERROR     = gitypes.TYPE_TAG_ERROR
+	TYPE_TAG_UNICHAR   = gitypes.TYPE_TAG_UNICHAR
 )
 
-// unichar 是 guint32, rune 是 go 中的，也是 32 位的。
-
-// g_type_tag_to_string
-func (tt TypeTag) String() string {
-	ret := C.g_type_tag_to_string(C.GITypeTag(tt))
-	return _GStringToGoString(ret)
-}
-
 // g_type_info_is_pointer
 func (ti *TypeInfo) IsPointer() bool {
 	return C.g_type_info_is_pointer((*C.GITypeInfo)(ti.c)) != 0
@@ -991,15 +975,15 @@ type FunctionInfo struct {
 	CallableInfo
 }
 
-type FunctionInfoFlags int
+type FunctionInfoFlags = gitypes.FunctionInfoFlags
 
 const (
-	FUNCTION_IS_METHOD      FunctionInfoFlags = C.GI_FUNCTION_IS_METHOD
-	FUNCTION_IS_CONSTRUCTOR FunctionInfoFlags = C.GI_FUNCTION_IS_CONSTRUCTOR
-	FUNCTION_IS_GETTER      FunctionInfoFlags = C.GI_FUNCTION_IS_GETTER
-	FUNCTION_IS_SETTER      FunctionInfoFlags = C.GI_FUNCTION_IS_SETTER
-	FUNCTION_WRAPS_VFUNC    FunctionInfoFlags = C.GI_FUNCTION_WRAPS_VFUNC
-	FUNCTION_THROWS         FunctionInfoFlags = C.GI_FUNCTION_THROWS
+	FUNCTION_IS_METHOD      = gitypes.FUNCTION_IS_METHOD
+	FUNCTION_IS_CONSTRUCTOR = gitypes.FUNCTION_IS_CONSTRUCTOR
+	FUNCTION_IS_GETTER      = gitypes.FUNCTION_IS_GETTER
+	FUNCTION_IS_SETTER      = gitypes.FUNCTION_IS_SETTER
+	FUNCTION_WRAPS_VFUNC    = gitypes.FUNCTION_WRAPS_VFUNC
+	FUNCTION_THROWS         = gitypes.FUNCTION_THROWS
 )
 
 // g_function_info_get_symbol
@@ -1134,12 +1118,13 @@ type VFuncInfo struct {
 	CallableInfo
 }
 
-type VFuncInfoFlags int
+type VFuncInfoFlags = gitypes.VFuncInfoFlags
 
 const (
-	VFUNC_MUST_CHAIN_UP     VFuncInfoFlags = C.GI_VFUNC_MUST_CHAIN_UP
-	VFUNC_MUST_OVERRIDE     VFuncInfoFlags = C.GI_VFUNC_MUST_OVERRIDE
-	VFUNC_MUST_NOT_OVERRIDE VFuncInfoFlags = C.GI_VFUNC_MUST_NOT_OVERRIDE
+	VFUNC_MUST_CHAIN_UP     = gitypes.VFUNC_MUST_CHAIN_UP
+	VFUNC_MUST_OVERRIDE     = gitypes.VFUNC_MUST_OVERRIDE
+	VFUNC_MUST_NOT_OVERRIDE = gitypes.VFUNC_MUST_NOT_OVERRIDE
+	VFUNC_THROWS            = gitypes.VFUNC_THROWS
 )
 
 // g_vfunc_info_get_flags
@@ -1241,6 +1226,11 @@ func (ei *EnumInfo) StorageType() TypeTag {
 	return TypeTag(C.g_enum_info_get_storage_type((*C.GIEnumInfo)(ei.c)))
 }
 
+// g_enum_info_get_error_domain
+func (ei *EnumInfo) ErrorDomain() string {
+	return _GStringToGoString(C.g_enum_info_get_error_domain((*C.GIEnumInfo)(ei.c)))
+}
+
 // g_value_info_get_value
 func (vi *ValueInfo) Value() int64 {
 	return int64(C.g_value_info_get_value((*C.GIValueInfo)(vi.c)))
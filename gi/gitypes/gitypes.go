@@ -0,0 +1,277 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package gitypes 定义 libgirepository 中的枚举和标志类型，值和 girepository 的 gitypes.h 等头文件中的相同。
+// 它不使用 cgo，gi 包和 girgen 的 girepo 包共用这些类型，girgen 只使用 GIR 文件时不需要链接 libgirepository。
+// gi 包的 gitypes_check.go 在编译时检查这些值和头文件中的相同。
+package gitypes
+
+import "fmt"
+
+// InfoType 对应 GIInfoType
+type InfoType int
+
+const (
+	INFO_TYPE_INVALID InfoType = iota
+	INFO_TYPE_FUNCTION
+	INFO_TYPE_CALLBACK
+	INFO_TYPE_STRUCT
+	INFO_TYPE_BOXED
+	INFO_TYPE_ENUM
+	INFO_TYPE_FLAGS
+	INFO_TYPE_OBJECT
+	INFO_TYPE_INTERFACE
+	INFO_TYPE_CONSTANT
+	INFO_TYPE_INVALID_0
+	INFO_TYPE_UNION
+	INFO_TYPE_VALUE
+	INFO_TYPE_SIGNAL
+	INFO_TYPE_VFUNC
+	INFO_TYPE_PROPERTY
+	INFO_TYPE_FIELD
+	INFO_TYPE_ARG
+	INFO_TYPE_TYPE
+	INFO_TYPE_UNRESOLVED
+)
+
+var _infoTypeNames = [...]string{
+	INFO_TYPE_INVALID:    "invalid",
+	INFO_TYPE_FUNCTION:   "function",
+	INFO_TYPE_CALLBACK:   "callback",
+	INFO_TYPE_STRUCT:     "struct",
+	INFO_TYPE_BOXED:      "boxed",
+	INFO_TYPE_ENUM:       "enum",
+	INFO_TYPE_FLAGS:      "flags",
+	INFO_TYPE_OBJECT:     "object",
+	INFO_TYPE_INTERFACE:  "interface",
+	INFO_TYPE_CONSTANT:   "constant",
+	INFO_TYPE_INVALID_0:  "unknown",
+	INFO_TYPE_UNION:      "union",
+	INFO_TYPE_VALUE:      "value",
+	INFO_TYPE_SIGNAL:     "signal",
+	INFO_TYPE_VFUNC:      "vfunc",
+	INFO_TYPE_PROPERTY:   "property",
+	INFO_TYPE_FIELD:      "field",
+	INFO_TYPE_ARG:        "arg",
+	INFO_TYPE_TYPE:       "type",
+	INFO_TYPE_UNRESOLVED: "unresolved",
+}
+
+// String 和 g_info_type_to_string 的结果相同
+func (it InfoType) String() string {
+	if it >= 0 && int(it) < len(_infoTypeNames) {
+		return _infoTypeNames[it]
+	}
+	return "unknown"
+}
+
+// RepositoryLoadFlags 对应 GIRepositoryLoadFlags
+type RepositoryLoadFlags int
+
+const (
+	REPOSITORY_LOAD_FLAG_LAZY RepositoryLoadFlags = 1 << 0
+)
+
+// Direction 对应 GIDirection
+type Direction int
+
+const (
+	DIRECTION_IN Direction = iota
+	DIRECTION_OUT
+	DIRECTION_INOUT
+)
+
+func (d Direction) String() (str string) {
+	switch d {
+	case DIRECTION_IN:
+		str = "in"
+	case DIRECTION_OUT:
+		str = "out"
+	case DIRECTION_INOUT:
+		str = "inout"
+	default:
+		str = fmt.Sprintf("invalid-direction(%d)", d)
+	}
+	return
+}
+
+// ScopeType 对应 GIScopeType
+type ScopeType int
+
+const (
+	SCOPE_TYPE_INVALID ScopeType = iota
+	SCOPE_TYPE_CALL
+	SCOPE_TYPE_ASYNC
+	SCOPE_TYPE_NOTIFIED
+)
+
+func (s ScopeType) String() (str string) {
+	switch s {
+	case SCOPE_TYPE_INVALID:
+		str = "invalid"
+	case SCOPE_TYPE_CALL:
+		str = "call"
+	case SCOPE_TYPE_ASYNC:
+		str = "async"
+	case SCOPE_TYPE_NOTIFIED:
+		str = "notified"
+	default:
+		str = fmt.Sprintf("invalid-scope-type(%d)", s)
+	}
+	return
+}
+
+// Transfer 对应 GITransfer
+type Transfer int
+
+const (
+	TRANSFER_NOTHING Transfer = iota
+	TRANSFER_CONTAINER
+	TRANSFER_EVERYTHING
+)
+
+func (t Transfer) String() (str string) {
+	switch t {
+	case TRANSFER_NOTHING:
+		str = "nothing"
+	case TRANSFER_CONTAINER:
+		str = "container"
+	case TRANSFER_EVERYTHING:
+		str = "everything"
+	default:
+		str = fmt.Sprintf("invalid-transfer(%d)", t)
+	}
+	return
+}
+
+// FieldInfoFlags 对应 GIFieldInfoFlags
+type FieldInfoFlags int
+
+const (
+	FIELD_IS_READABLE FieldInfoFlags = 1 << 0
+	FIELD_IS_WRITABLE FieldInfoFlags = 1 << 1
+)
+
+// ParamFlags 对应 GParamFlags
+type ParamFlags int
+
+const (
+	PARAM_READABLE       ParamFlags = 1 << 0
+	PARAM_WRITABLE       ParamFlags = 1 << 1
+	PARAM_CONSTRUCT      ParamFlags = 1 << 2
+	PARAM_CONSTRUCT_ONLY ParamFlags = 1 << 3
+	PARAM_DEPRECATED     ParamFlags = 1 << 31
+)
+
+// ArrayType 对应 GIArrayType
+type ArrayType int
+
+const (
+	ARRAY_TYPE_C ArrayType = iota
+	ARRAY_TYPE_ARRAY
+	ARRAY_TYPE_PTR_ARRAY
+	ARRAY_TYPE_BYTE_ARRAY
+)
+
+// TypeTag 对应 GITypeTag
+type TypeTag int
+
+const (
+	TYPE_TAG_VOID TypeTag = iota
+	TYPE_TAG_BOOLEAN
+	TYPE_TAG_INT8
+	TYPE_TAG_UINT8
+	TYPE_TAG_INT16
+	TYPE_TAG_UINT16
+	TYPE_TAG_INT32
+	TYPE_TAG_UINT32
+	TYPE_TAG_INT64
+	TYPE_TAG_UINT64
+	TYPE_TAG_FLOAT
+	TYPE_TAG_DOUBLE
+	TYPE_TAG_GTYPE
+	TYPE_TAG_UTF8
+	TYPE_TAG_FILENAME
+	TYPE_TAG_ARRAY
+	TYPE_TAG_INTERFACE
+	TYPE_TAG_GLIST
+	TYPE_TAG_GSLIST
+	TYPE_TAG_GHASH
+	TYPE_TAG_ERROR
+	TYPE_TAG_UNICHAR
+)
+
+// unichar 是 guint32, rune 是 go 中的，也是 32 位的。
+
+var _typeTagNames = [...]string{
+	TYPE_TAG_VOID:      "void",
+	TYPE_TAG_BOOLEAN:   "gboolean",
+	TYPE_TAG_INT8:      "gint8",
+	TYPE_TAG_UINT8:     "guint8",
+	TYPE_TAG_INT16:     "gint16",
+	TYPE_TAG_UINT16:    "guint16",
+	TYPE_TAG_INT32:     "gint32",
+	TYPE_TAG_UINT32:    "guint32",
+	TYPE_TAG_INT64:     "gint64",
+	TYPE_TAG_UINT64:    "guint64",
+	TYPE_TAG_FLOAT:     "gfloat",
+	TYPE_TAG_DOUBLE:    "gdouble",
+	TYPE_TAG_GTYPE:     "GType",
+	TYPE_TAG_UTF8:      "utf8",
+	TYPE_TAG_FILENAME:  "filename",
+	TYPE_TAG_ARRAY:     "array",
+	TYPE_TAG_INTERFACE: "interface",
+	TYPE_TAG_GLIST:     "glist",
+	TYPE_TAG_GSLIST:    "gslist",
+	TYPE_TAG_GHASH:     "ghash",
+	TYPE_TAG_ERROR:     "GError",
+	TYPE_TAG_UNICHAR:   "gunichar",
+}
+
+// String 和 g_type_tag_to_string 的结果相同
+func (tt TypeTag) String() string {
+	if tt >= 0 && int(tt) < len(_typeTagNames) {
+		return _typeTagNames[tt]
+	}
+	return "unknown"
+}
+
+// FunctionInfoFlags 对应 GIFunctionInfoFlags
+type FunctionInfoFlags int
+
+const (
+	FUNCTION_IS_METHOD      FunctionInfoFlags = 1 << 0
+	FUNCTION_IS_CONSTRUCTOR FunctionInfoFlags = 1 << 1
+	FUNCTION_IS_GETTER      FunctionInfoFlags = 1 << 2
+	FUNCTION_IS_SETTER      FunctionInfoFlags = 1 << 3
+	FUNCTION_WRAPS_VFUNC    FunctionInfoFlags = 1 << 4
+	FUNCTION_THROWS         FunctionInfoFlags = 1 << 5
+)
+
+// VFuncInfoFlags 对应 GIVFuncInfoFlags
+type VFuncInfoFlags int
+
+const (
+	VFUNC_MUST_CHAIN_UP     VFuncInfoFlags = 1 << 0
+	VFUNC_MUST_OVERRIDE     VFuncInfoFlags = 1 << 1
+	VFUNC_MUST_NOT_OVERRIDE VFuncInfoFlags = 1 << 2
+	VFUNC_THROWS            VFuncInfoFlags = 1 << 3
+)
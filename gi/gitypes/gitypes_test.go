@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gitypes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInfoTypeString(t *testing.T) {
+	assert.Equal(t, "function", INFO_TYPE_FUNCTION.String())
+	assert.Equal(t, "invalid", INFO_TYPE_INVALID.String())
+	assert.Equal(t, "unknown", INFO_TYPE_INVALID_0.String())
+	assert.Equal(t, "unresolved", INFO_TYPE_UNRESOLVED.String())
+	assert.Equal(t, "unknown", InfoType(100).String())
+	assert.Equal(t, "unknown", InfoType(-1).String())
+}
+
+func TestTypeTagString(t *testing.T) {
+	assert.Equal(t, "void", TYPE_TAG_VOID.String())
+	assert.Equal(t, "GError", TYPE_TAG_ERROR.String())
+	assert.Equal(t, "gunichar", TYPE_TAG_UNICHAR.String())
+	assert.Equal(t, "unknown", TypeTag(100).String())
+}
@@ -0,0 +1,222 @@
+/*
+ * Copyright (C) 2019 ~ 2020 Uniontech Software Technology Co.,Ltd
+ *
+ * Author:
+ *
+ * Maintainer:
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gi
+
+/*
+#include <girepository.h>
+*/
+import "C"
+import "github.com/electricface/go-gir3/gi/gitypes"
+
+// gitypes 包不使用 cgo，它的常量值是手写的。这里在编译时检查它们和头文件中的值相同，
+// 两个方向的差都必须能转换为 uint，否则常量溢出，编译失败。
+// G_PARAM_DEPRECATED 在头文件中是 (gint)(1u << 31)，是负数，所以不检查。
+
+// InfoType
+const (
+	_ = uint(C.GI_INFO_TYPE_INVALID - gitypes.INFO_TYPE_INVALID)
+	_ = uint(gitypes.INFO_TYPE_INVALID - C.GI_INFO_TYPE_INVALID)
+	_ = uint(C.GI_INFO_TYPE_FUNCTION - gitypes.INFO_TYPE_FUNCTION)
+	_ = uint(gitypes.INFO_TYPE_FUNCTION - C.GI_INFO_TYPE_FUNCTION)
+	_ = uint(C.GI_INFO_TYPE_CALLBACK - gitypes.INFO_TYPE_CALLBACK)
+	_ = uint(gitypes.INFO_TYPE_CALLBACK - C.GI_INFO_TYPE_CALLBACK)
+	_ = uint(C.GI_INFO_TYPE_STRUCT - gitypes.INFO_TYPE_STRUCT)
+	_ = uint(gitypes.INFO_TYPE_STRUCT - C.GI_INFO_TYPE_STRUCT)
+	_ = uint(C.GI_INFO_TYPE_BOXED - gitypes.INFO_TYPE_BOXED)
+	_ = uint(gitypes.INFO_TYPE_BOXED - C.GI_INFO_TYPE_BOXED)
+	_ = uint(C.GI_INFO_TYPE_ENUM - gitypes.INFO_TYPE_ENUM)
+	_ = uint(gitypes.INFO_TYPE_ENUM - C.GI_INFO_TYPE_ENUM)
+	_ = uint(C.GI_INFO_TYPE_FLAGS - gitypes.INFO_TYPE_FLAGS)
+	_ = uint(gitypes.INFO_TYPE_FLAGS - C.GI_INFO_TYPE_FLAGS)
+	_ = uint(C.GI_INFO_TYPE_OBJECT - gitypes.INFO_TYPE_OBJECT)
+	_ = uint(gitypes.INFO_TYPE_OBJECT - C.GI_INFO_TYPE_OBJECT)
+	_ = uint(C.GI_INFO_TYPE_INTERFACE - gitypes.INFO_TYPE_INTERFACE)
+	_ = uint(gitypes.INFO_TYPE_INTERFACE - C.GI_INFO_TYPE_INTERFACE)
+	_ = uint(C.GI_INFO_TYPE_CONSTANT - gitypes.INFO_TYPE_CONSTANT)
+	_ = uint(gitypes.INFO_TYPE_CONSTANT - C.GI_INFO_TYPE_CONSTANT)
+	_ = uint(C.GI_INFO_TYPE_INVALID_0 - gitypes.INFO_TYPE_INVALID_0)
+	_ = uint(gitypes.INFO_TYPE_INVALID_0 - C.GI_INFO_TYPE_INVALID_0)
+	_ = uint(C.GI_INFO_TYPE_UNION - gitypes.INFO_TYPE_UNION)
+	_ = uint(gitypes.INFO_TYPE_UNION - C.GI_INFO_TYPE_UNION)
+	_ = uint(C.GI_INFO_TYPE_VALUE - gitypes.INFO_TYPE_VALUE)
+	_ = uint(gitypes.INFO_TYPE_VALUE - C.GI_INFO_TYPE_VALUE)
+	_ = uint(C.GI_INFO_TYPE_SIGNAL - gitypes.INFO_TYPE_SIGNAL)
+	_ = uint(gitypes.INFO_TYPE_SIGNAL - C.GI_INFO_TYPE_SIGNAL)
+	_ = uint(C.GI_INFO_TYPE_VFUNC - gitypes.INFO_TYPE_VFUNC)
+	_ = uint(gitypes.INFO_TYPE_VFUNC - C.GI_INFO_TYPE_VFUNC)
+	_ = uint(C.GI_INFO_TYPE_PROPERTY - gitypes.INFO_TYPE_PROPERTY)
+	_ = uint(gitypes.INFO_TYPE_PROPERTY - C.GI_INFO_TYPE_PROPERTY)
+	_ = uint(C.GI_INFO_TYPE_FIELD - gitypes.INFO_TYPE_FIELD)
+	_ = uint(gitypes.INFO_TYPE_FIELD - C.GI_INFO_TYPE_FIELD)
+	_ = uint(C.GI_INFO_TYPE_ARG - gitypes.INFO_TYPE_ARG)
+	_ = uint(gitypes.INFO_TYPE_ARG - C.GI_INFO_TYPE_ARG)
+	_ = uint(C.GI_INFO_TYPE_TYPE - gitypes.INFO_TYPE_TYPE)
+	_ = uint(gitypes.INFO_TYPE_TYPE - C.GI_INFO_TYPE_TYPE)
+	_ = uint(C.GI_INFO_TYPE_UNRESOLVED - gitypes.INFO_TYPE_UNRESOLVED)
+	_ = uint(gitypes.INFO_TYPE_UNRESOLVED - C.GI_INFO_TYPE_UNRESOLVED)
+)
+
+// RepositoryLoadFlags
+const (
+	_ = uint(C.GI_REPOSITORY_LOAD_FLAG_LAZY - gitypes.REPOSITORY_LOAD_FLAG_LAZY)
+	_ = uint(gitypes.REPOSITORY_LOAD_FLAG_LAZY - C.GI_REPOSITORY_LOAD_FLAG_LAZY)
+)
+
+// Direction
+const (
+	_ = uint(C.GI_DIRECTION_IN - gitypes.DIRECTION_IN)
+	_ = uint(gitypes.DIRECTION_IN - C.GI_DIRECTION_IN)
+	_ = uint(C.GI_DIRECTION_OUT - gitypes.DIRECTION_OUT)
+	_ = uint(gitypes.DIRECTION_OUT - C.GI_DIRECTION_OUT)
+	_ = uint(C.GI_DIRECTION_INOUT - gitypes.DIRECTION_INOUT)
+	_ = uint(gitypes.DIRECTION_INOUT - C.GI_DIRECTION_INOUT)
+)
+
+// ScopeType
+const (
+	_ = uint(C.GI_SCOPE_TYPE_INVALID - gitypes.SCOPE_TYPE_INVALID)
+	_ = uint(gitypes.SCOPE_TYPE_INVALID - C.GI_SCOPE_TYPE_INVALID)
+	_ = uint(C.GI_SCOPE_TYPE_CALL - gitypes.SCOPE_TYPE_CALL)
+	_ = uint(gitypes.SCOPE_TYPE_CALL - C.GI_SCOPE_TYPE_CALL)
+	_ = uint(C.GI_SCOPE_TYPE_ASYNC - gitypes.SCOPE_TYPE_ASYNC)
+	_ = uint(gitypes.SCOPE_TYPE_ASYNC - C.GI_SCOPE_TYPE_ASYNC)
+	_ = uint(C.GI_SCOPE_TYPE_NOTIFIED - gitypes.SCOPE_TYPE_NOTIFIED)
+	_ = uint(gitypes.SCOPE_TYPE_NOTIFIED - C.GI_SCOPE_TYPE_NOTIFIED)
+)
+
+// Transfer
+const (
+	_ = uint(C.GI_TRANSFER_NOTHING - gitypes.TRANSFER_NOTHING)
+	_ = uint(gitypes.TRANSFER_NOTHING - C.GI_TRANSFER_NOTHING)
+	_ = uint(C.GI_TRANSFER_CONTAINER - gitypes.TRANSFER_CONTAINER)
+	_ = uint(gitypes.TRANSFER_CONTAINER - C.GI_TRANSFER_CONTAINER)
+	_ = uint(C.GI_TRANSFER_EVERYTHING - gitypes.TRANSFER_EVERYTHING)
+	_ = uint(gitypes.TRANSFER_EVERYTHING - C.GI_TRANSFER_EVERYTHING)
+)
+
+// FieldInfoFlags
+const (
+	_ = uint(C.GI_FIELD_IS_READABLE - gitypes.FIELD_IS_READABLE)
+	_ = uint(gitypes.FIELD_IS_READABLE - C.GI_FIELD_IS_READABLE)
+	_ = uint(C.GI_FIELD_IS_WRITABLE - gitypes.FIELD_IS_WRITABLE)
+	_ = uint(gitypes.FIELD_IS_WRITABLE - C.GI_FIELD_IS_WRITABLE)
+)
+
+// ParamFlags
+const (
+	_ = uint(C.G_PARAM_READABLE - gitypes.PARAM_READABLE)
+	_ = uint(gitypes.PARAM_READABLE - C.G_PARAM_READABLE)
+	_ = uint(C.G_PARAM_WRITABLE - gitypes.PARAM_WRITABLE)
+	_ = uint(gitypes.PARAM_WRITABLE - C.G_PARAM_WRITABLE)
+	_ = uint(C.G_PARAM_CONSTRUCT - gitypes.PARAM_CONSTRUCT)
+	_ = uint(gitypes.PARAM_CONSTRUCT - C.G_PARAM_CONSTRUCT)
+	_ = uint(C.G_PARAM_CONSTRUCT_ONLY - gitypes.PARAM_CONSTRUCT_ONLY)
+	_ = uint(gitypes.PARAM_CONSTRUCT_ONLY - C.G_PARAM_CONSTRUCT_ONLY)
+)
+
+// ArrayType
+const (
+	_ = uint(C.GI_ARRAY_TYPE_C - gitypes.ARRAY_TYPE_C)
+	_ = uint(gitypes.ARRAY_TYPE_C - C.GI_ARRAY_TYPE_C)
+	_ = uint(C.GI_ARRAY_TYPE_ARRAY - gitypes.ARRAY_TYPE_ARRAY)
+	_ = uint(gitypes.ARRAY_TYPE_ARRAY - C.GI_ARRAY_TYPE_ARRAY)
+	_ = uint(C.GI_ARRAY_TYPE_PTR_ARRAY - gitypes.ARRAY_TYPE_PTR_ARRAY)
+	_ = uint(gitypes.ARRAY_TYPE_PTR_ARRAY - C.GI_ARRAY_TYPE_PTR_ARRAY)
+	_ = uint(C.GI_ARRAY_TYPE_BYTE_ARRAY - gitypes.ARRAY_TYPE_BYTE_ARRAY)
+	_ = uint(gitypes.ARRAY_TYPE_BYTE_ARRAY - C.GI_ARRAY_TYPE_BYTE_ARRAY)
+)
+
+// TypeTag
+const (
+	_ = uint(C.GI_TYPE_TAG_VOID - gitypes.TYPE_TAG_VOID)
+	_ = uint(gitypes.TYPE_TAG_VOID - C.GI_TYPE_TAG_VOID)
+	_ = uint(C.GI_TYPE_TAG_BOOLEAN - gitypes.TYPE_TAG_BOOLEAN)
+	_ = uint(gitypes.TYPE_TAG_BOOLEAN - C.GI_TYPE_TAG_BOOLEAN)
+	_ = uint(C.GI_TYPE_TAG_INT8 - gitypes.TYPE_TAG_INT8)
+	_ = uint(gitypes.TYPE_TAG_INT8 - C.GI_TYPE_TAG_INT8)
+	_ = uint(C.GI_TYPE_TAG_UINT8 - gitypes.TYPE_TAG_UINT8)
+	_ = uint(gitypes.TYPE_TAG_UINT8 - C.GI_TYPE_TAG_UINT8)
+	_ = uint(C.GI_TYPE_TAG_INT16 - gitypes.TYPE_TAG_INT16)
+	_ = uint(gitypes.TYPE_TAG_INT16 - C.GI_TYPE_TAG_INT16)
+	_ = uint(C.GI_TYPE_TAG_UINT16 - gitypes.TYPE_TAG_UINT16)
+	_ = uint(gitypes.TYPE_TAG_UINT16 - C.GI_TYPE_TAG_UINT16)
+	_ = uint(C.GI_TYPE_TAG_INT32 - gitypes.TYPE_TAG_INT32)
+	_ = uint(gitypes.TYPE_TAG_INT32 - C.GI_TYPE_TAG_INT32)
+	_ = uint(C.GI_TYPE_TAG_UINT32 - gitypes.TYPE_TAG_UINT32)
+	_ = uint(gitypes.TYPE_TAG_UINT32 - C.GI_TYPE_TAG_UINT32)
+	_ = uint(C.GI_TYPE_TAG_INT64 - gitypes.TYPE_TAG_INT64)
+	_ = uint(gitypes.TYPE_TAG_INT64 - C.GI_TYPE_TAG_INT64)
+	_ = uint(C.GI_TYPE_TAG_UINT64 - gitypes.TYPE_TAG_UINT64)
+	_ = uint(gitypes.TYPE_TAG_UINT64 - C.GI_TYPE_TAG_UINT64)
+	_ = uint(C.GI_TYPE_TAG_FLOAT - gitypes.TYPE_TAG_FLOAT)
+	_ = uint(gitypes.TYPE_TAG_FLOAT - C.GI_TYPE_TAG_FLOAT)
+	_ = uint(C.GI_TYPE_TAG_DOUBLE - gitypes.TYPE_TAG_DOUBLE)
+	_ = uint(gitypes.TYPE_TAG_DOUBLE - C.GI_TYPE_TAG_DOUBLE)
+	_ = uint(C.GI_TYPE_TAG_GTYPE - gitypes.TYPE_TAG_GTYPE)
+	_ = uint(gitypes.TYPE_TAG_GTYPE - C.GI_TYPE_TAG_GTYPE)
+	_ = uint(C.GI_TYPE_TAG_UTF8 - gitypes.TYPE_TAG_UTF8)
+	_ = uint(gitypes.TYPE_TAG_UTF8 - C.GI_TYPE_TAG_UTF8)
+	_ = uint(C.GI_TYPE_TAG_FILENAME - gitypes.TYPE_TAG_FILENAME)
+	_ = uint(gitypes.TYPE_TAG_FILENAME - C.GI_TYPE_TAG_FILENAME)
+	_ = uint(C.GI_TYPE_TAG_ARRAY - gitypes.TYPE_TAG_ARRAY)
+	_ = uint(gitypes.TYPE_TAG_ARRAY - C.GI_TYPE_TAG_ARRAY)
+	_ = uint(C.GI_TYPE_TAG_INTERFACE - gitypes.TYPE_TAG_INTERFACE)
+	_ = uint(gitypes.TYPE_TAG_INTERFACE - C.GI_TYPE_TAG_INTERFACE)
+	_ = uint(C.GI_TYPE_TAG_GLIST - gitypes.TYPE_TAG_GLIST)
+	_ = uint(gitypes.TYPE_TAG_GLIST - C.GI_TYPE_TAG_GLIST)
+	_ = uint(C.GI_TYPE_TAG_GSLIST - gitypes.TYPE_TAG_GSLIST)
+	_ = uint(gitypes.TYPE_TAG_GSLIST - C.GI_TYPE_TAG_GSLIST)
+	_ = uint(C.GI_TYPE_TAG_GHASH - gitypes.TYPE_TAG_GHASH)
+	_ = uint(gitypes.TYPE_TAG_GHASH - C.GI_TYPE_TAG_GHASH)
+	_ = uint(C.GI_TYPE_TAG_ERROR - gitypes.TYPE_TAG_ERROR)
+	_ = uint(gitypes.TYPE_TAG_ERROR - C.GI_TYPE_TAG_ERROR)
+	_ = uint(C.GI_TYPE_TAG_UNICHAR - gitypes.TYPE_TAG_UNICHAR)
+	_ = uint(gitypes.TYPE_TAG_UNICHAR - C.GI_TYPE_TAG_UNICHAR)
+)
+
+// FunctionInfoFlags
+const (
+	_ = uint(C.GI_FUNCTION_IS_METHOD - gitypes.FUNCTION_IS_METHOD)
+	_ = uint(gitypes.FUNCTION_IS_METHOD - C.GI_FUNCTION_IS_METHOD)
+	_ = uint(C.GI_FUNCTION_IS_CONSTRUCTOR - gitypes.FUNCTION_IS_CONSTRUCTOR)
+	_ = uint(gitypes.FUNCTION_IS_CONSTRUCTOR - C.GI_FUNCTION_IS_CONSTRUCTOR)
+	_ = uint(C.GI_FUNCTION_IS_GETTER - gitypes.FUNCTION_IS_GETTER)
+	_ = uint(gitypes.FUNCTION_IS_GETTER - C.GI_FUNCTION_IS_GETTER)
+	_ = uint(C.GI_FUNCTION_IS_SETTER - gitypes.FUNCTION_IS_SETTER)
+	_ = uint(gitypes.FUNCTION_IS_SETTER - C.GI_FUNCTION_IS_SETTER)
+	_ = uint(C.GI_FUNCTION_WRAPS_VFUNC - gitypes.FUNCTION_WRAPS_VFUNC)
+	_ = uint(gitypes.FUNCTION_WRAPS_VFUNC - C.GI_FUNCTION_WRAPS_VFUNC)
+	_ = uint(C.GI_FUNCTION_THROWS - gitypes.FUNCTION_THROWS)
+	_ = uint(gitypes.FUNCTION_THROWS - C.GI_FUNCTION_THROWS)
+)
+
+// VFuncInfoFlags
+const (
+	_ = uint(C.GI_VFUNC_MUST_CHAIN_UP - gitypes.VFUNC_MUST_CHAIN_UP)
+	_ = uint(gitypes.VFUNC_MUST_CHAIN_UP - C.GI_VFUNC_MUST_CHAIN_UP)
+	_ = uint(C.GI_VFUNC_MUST_OVERRIDE - gitypes.VFUNC_MUST_OVERRIDE)
+	_ = uint(gitypes.VFUNC_MUST_OVERRIDE - C.GI_VFUNC_MUST_OVERRIDE)
+	_ = uint(C.GI_VFUNC_MUST_NOT_OVERRIDE - gitypes.VFUNC_MUST_NOT_OVERRIDE)
+	_ = uint(gitypes.VFUNC_MUST_NOT_OVERRIDE - C.GI_VFUNC_MUST_NOT_OVERRIDE)
+	_ = uint(C.GI_VFUNC_THROWS - gitypes.VFUNC_THROWS)
+	_ = uint(gitypes.VFUNC_THROWS - C.GI_VFUNC_THROWS)
+)
@@ -23,6 +23,12 @@ _g_closure_add_finalize_notifier(GClosure *closure) {
 g_closure_add_finalize_notifier(closure, NULL, removeClosure);
 }
 
+static GHashTable *_g_hash_table_new(gboolean str_key, gboolean free_key, gboolean free_value) {
+	return g_hash_table_new_full(str_key ? g_str_hash : g_direct_hash,
+		str_key ? g_str_equal : g_direct_equal,
+		free_key ? g_free : NULL, free_value ? g_free : NULL);
+}
+
 static gboolean _g_is_value(GValue *val) {
 	return G_IS_VALUE(val);
 }
@@ -35,6 +41,182 @@ static GObjectClass * _g_object_get_class (GObject *object) {
 	return G_OBJECT_GET_CLASS(object);
 }
 
+static void _g_object_get_property_arg(GObject *object, const gchar *name, gpointer out,
+	gboolean transfer_nothing) {
+	g_object_get(object, name, out, NULL);
+	if (!transfer_nothing || *(gpointer*)out == NULL) {
+		return;
+	}
+	GParamSpec *pspec = g_object_class_find_property(G_OBJECT_GET_CLASS(object), name);
+	if (pspec != NULL && (G_TYPE_FUNDAMENTAL(pspec->value_type) == G_TYPE_OBJECT ||
+		G_TYPE_FUNDAMENTAL(pspec->value_type) == G_TYPE_INTERFACE)) {
+		// 属性的所有权不转移，去掉 g_object_get 增加的引用
+		g_object_unref(*(gpointer*)out);
+	}
+}
+
+// _g_value_get_arg 把 value 中的值按照 GIArgument 的布局保存在 out 中，不复制值。
+static void _g_value_get_arg(const GValue *value, gpointer out) {
+	GType type = G_VALUE_TYPE(value);
+	if (type == G_TYPE_GTYPE) {
+		*(GType*)out = g_value_get_gtype(value);
+		return;
+	}
+	switch (G_TYPE_FUNDAMENTAL(type)) {
+	case G_TYPE_CHAR:
+		*(gint8*)out = g_value_get_schar(value);
+		break;
+	case G_TYPE_UCHAR:
+		*(guchar*)out = g_value_get_uchar(value);
+		break;
+	case G_TYPE_BOOLEAN:
+		*(gboolean*)out = g_value_get_boolean(value);
+		break;
+	case G_TYPE_INT:
+		*(gint*)out = g_value_get_int(value);
+		break;
+	case G_TYPE_UINT:
+		*(guint*)out = g_value_get_uint(value);
+		break;
+	case G_TYPE_LONG:
+		*(glong*)out = g_value_get_long(value);
+		break;
+	case G_TYPE_ULONG:
+		*(gulong*)out = g_value_get_ulong(value);
+		break;
+	case G_TYPE_INT64:
+		*(gint64*)out = g_value_get_int64(value);
+		break;
+	case G_TYPE_UINT64:
+		*(guint64*)out = g_value_get_uint64(value);
+		break;
+	case G_TYPE_ENUM:
+		*(gint*)out = g_value_get_enum(value);
+		break;
+	case G_TYPE_FLAGS:
+		*(guint*)out = g_value_get_flags(value);
+		break;
+	case G_TYPE_FLOAT:
+		*(gfloat*)out = g_value_get_float(value);
+		break;
+	case G_TYPE_DOUBLE:
+		*(gdouble*)out = g_value_get_double(value);
+		break;
+	case G_TYPE_STRING:
+		*(const gchar**)out = g_value_get_string(value);
+		break;
+	case G_TYPE_POINTER:
+		*(gpointer*)out = g_value_get_pointer(value);
+		break;
+	case G_TYPE_BOXED:
+		*(gpointer*)out = g_value_get_boxed(value);
+		break;
+	case G_TYPE_PARAM:
+		*(GParamSpec**)out = g_value_get_param(value);
+		break;
+	case G_TYPE_INTERFACE:
+	case G_TYPE_OBJECT:
+		*(gpointer*)out = g_value_get_object(value);
+		break;
+	case G_TYPE_VARIANT:
+		*(GVariant**)out = g_value_get_variant(value);
+		break;
+	default:
+		*(gpointer*)out = value->data[0].v_pointer;
+	}
+}
+
+// _g_value_set_arg 把按照 GIArgument 的布局保存在 arg 中的值设置到已经初始化的 value 中。
+static gboolean _g_value_set_arg(GValue *value, gconstpointer arg) {
+	GType type = G_VALUE_TYPE(value);
+	if (type == G_TYPE_GTYPE) {
+		g_value_set_gtype(value, *(GType*)arg);
+		return TRUE;
+	}
+	switch (G_TYPE_FUNDAMENTAL(type)) {
+	case G_TYPE_CHAR:
+		g_value_set_schar(value, *(gint8*)arg);
+		break;
+	case G_TYPE_UCHAR:
+		g_value_set_uchar(value, *(guchar*)arg);
+		break;
+	case G_TYPE_BOOLEAN:
+		g_value_set_boolean(value, *(gboolean*)arg);
+		break;
+	case G_TYPE_INT:
+		g_value_set_int(value, *(gint*)arg);
+		break;
+	case G_TYPE_UINT:
+		g_value_set_uint(value, *(guint*)arg);
+		break;
+	case G_TYPE_LONG:
+		g_value_set_long(value, *(glong*)arg);
+		break;
+	case G_TYPE_ULONG:
+		g_value_set_ulong(value, *(gulong*)arg);
+		break;
+	case G_TYPE_INT64:
+		g_value_set_int64(value, *(gint64*)arg);
+		break;
+	case G_TYPE_UINT64:
+		g_value_set_uint64(value, *(guint64*)arg);
+		break;
+	case G_TYPE_ENUM:
+		g_value_set_enum(value, *(gint*)arg);
+		break;
+	case G_TYPE_FLAGS:
+		g_value_set_flags(value, *(guint*)arg);
+		break;
+	case G_TYPE_FLOAT:
+		g_value_set_float(value, *(gfloat*)arg);
+		break;
+	case G_TYPE_DOUBLE:
+		g_value_set_double(value, *(gdouble*)arg);
+		break;
+	case G_TYPE_STRING:
+		g_value_set_string(value, *(gchar**)arg);
+		break;
+	case G_TYPE_POINTER:
+		g_value_set_pointer(value, *(gpointer*)arg);
+		break;
+	case G_TYPE_BOXED:
+		g_value_set_boxed(value, *(gpointer*)arg);
+		break;
+	case G_TYPE_PARAM:
+		g_value_set_param(value, *(GParamSpec**)arg);
+		break;
+	case G_TYPE_INTERFACE:
+	case G_TYPE_OBJECT:
+		g_value_set_object(value, *(gpointer*)arg);
+		break;
+	case G_TYPE_VARIANT:
+		g_value_set_variant(value, *(GVariant**)arg);
+		break;
+	default:
+		return FALSE;
+	}
+	return TRUE;
+}
+
+static void _g_object_set_property_arg(GObject *object, const gchar *name, gpointer arg) {
+	GParamSpec *pspec = g_object_class_find_property(G_OBJECT_GET_CLASS(object), name);
+	if (pspec == NULL) {
+		g_warning("%s: object of type %s has no property named %s", G_STRFUNC,
+			G_OBJECT_TYPE_NAME(object), name);
+		return;
+	}
+	GValue value = G_VALUE_INIT;
+	g_value_init(&value, pspec->value_type);
+	if (!_g_value_set_arg(&value, arg)) {
+		g_warning("%s: unsupported type %s of property %s", G_STRFUNC,
+			g_type_name(pspec->value_type), name);
+		g_value_unset(&value);
+		return;
+	}
+	g_object_set_property(object, name, &value);
+	g_value_unset(&value);
+}
+
 */
 import "C"
 import (
@@ -85,6 +267,12 @@ func goMarshal(closure *C.GClosure, retValue *C.GValue,
 	cc := closures.m[unsafe.Pointer(closure)]
 	closures.RUnlock()
 
+	if fn, ok := cc.rf.Interface().(SignalFunc); ok {
+		// 生成的 ConnectXXX 方法连接的信号处理函数
+		callSignalFunc(fn, retValue, nParams, params)
+		return
+	}
+
 	var args []interface{}
 	nGLibParams := int(nParams)
 	if nGLibParams > 0 {
@@ -135,6 +323,42 @@ func goMarshal(closure *C.GClosure, retValue *C.GValue,
 	}
 }
 
+// SignalFunc 是生成的 ConnectXXX 方法使用的信号处理函数类型。
+// args 是信号的参数，args[0] 是发出信号的实例；信号有返回值时 ret.P 不为 nil。
+type SignalFunc func(args []gi.Argument, ret Value)
+
+func callSignalFunc(fn SignalFunc, retValue *C.GValue, nParams C.guint, params *C.GValue) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "func panic with error:", err)
+			debug.PrintStack()
+		}
+	}()
+
+	var args []gi.Argument
+	nGLibParams := int(nParams)
+	if nGLibParams > 0 {
+		args = make([]gi.Argument, nGLibParams)
+		gValues := gValueSlice(params, nGLibParams)
+		for i := 0; i < nGLibParams; i++ {
+			v := Value{unsafe.Pointer(&gValues[i])}
+			args[i] = v.Argument()
+		}
+	}
+
+	ret := Value{unsafe.Pointer(retValue)}
+	if retValue != nil && !ret.IsValid() {
+		ret.P = nil
+	}
+	fn(args, ret)
+}
+
+// ConnectSignal 给生成的 ConnectXXX 方法用，连接对象 obj 的信号 detailedSignal 到 fn。
+func ConnectSignal(obj unsafe.Pointer, detailedSignal string, after bool, fn SignalFunc) SignalHandle {
+	return Object{P: obj}.connectClosure(after, detailedSignal, fn)
+}
+
 // gValueSlice converts a C array of GValues to a Go slice.
 func gValueSlice(values *C.GValue, nValues int) (slice []C.GValue) {
 	header := (*reflect.SliceHeader)(unsafe.Pointer(&slice))
@@ -506,6 +730,52 @@ func (v SList) Index(data unsafe.Pointer) int {
 	return int(C.g_slist_index(v.p(), C.gconstpointer(data)))
 }
 
+// NewHashTable 创建新的哈希表，strKey 表示键是字符串，否则按指针比较键；
+// freeKey 和 freeValue 表示在移除元素或者销毁哈希表时是否用 g_free 释放键和值。
+func NewHashTable(strKey, freeKey, freeValue bool) HashTable {
+	ret := C._g_hash_table_new(C.gboolean(gi.Bool2Int(strKey)), C.gboolean(gi.Bool2Int(freeKey)),
+		C.gboolean(gi.Bool2Int(freeValue)))
+	return HashTable{P: unsafe.Pointer(ret)}
+}
+
+func (v HashTable) p() *C.GHashTable {
+	return (*C.GHashTable)(v.P)
+}
+
+// Insert 插入键值对，如果键已经存在，则替换它的值。
+func (v HashTable) Insert(key, value unsafe.Pointer) {
+	C.g_hash_table_insert(v.p(), C.gpointer(key), C.gpointer(value))
+}
+
+// ForEach 遍历哈希表中所有的键值对，v 可以为空的哈希表。
+func (v HashTable) ForEach(fn func(key, value unsafe.Pointer)) {
+	if v.P == nil {
+		return
+	}
+	var iter C.GHashTableIter
+	var key, value C.gpointer
+	C.g_hash_table_iter_init(&iter, v.p())
+	for C.g_hash_table_iter_next(&iter, &key, &value) != 0 {
+		fn(unsafe.Pointer(key), unsafe.Pointer(value))
+	}
+}
+
+// StealAll 从哈希表中移除所有的键值对，但不调用键和值的销毁函数。
+func (v HashTable) StealAll() {
+	if v.P == nil {
+		return
+	}
+	C.g_hash_table_steal_all(v.p())
+}
+
+// Unref 减少哈希表的引用计数，如果减到 0，则销毁所有的键值对并释放哈希表。
+func (v HashTable) Unref() {
+	if v.P == nil {
+		return
+	}
+	C.g_hash_table_unref(v.p())
+}
+
 const (
 	TYPE_INVALID   gi.GType = C.G_TYPE_INVALID
 	TYPE_NONE      gi.GType = C.G_TYPE_NONE
@@ -548,6 +818,21 @@ func (v Value) IsValid() bool {
 	return gi.Int2Bool(int(ret))
 }
 
+// Argument 把 Value 保存的值转换为 gi.Argument，字符串、对象等值不会被复制，所有权不转移。
+func (v Value) Argument() (arg gi.Argument) {
+	C._g_value_get_arg(v.p(), C.gpointer(&arg))
+	return
+}
+
+// SetArgument 按照 Value 的类型，把 arg 中的值设置到 Value 中，字符串、boxed 等值会被复制。
+func (v Value) SetArgument(arg gi.Argument) error {
+	ret := C._g_value_set_arg(v.p(), C.gconstpointer(&arg))
+	if ret == 0 {
+		return errTypeConvert
+	}
+	return nil
+}
+
 func (v Object) p() *C.GObject {
 	return (*C.GObject)(v.P)
 }
@@ -560,3 +845,22 @@ func (v Object) GetClass() ObjectClass {
 func (v ObjectClass) p() *C.GObjectClass {
 	return (*C.GObjectClass)(v.P)
 }
+
+// GetPropertyArgument 获取对象 obj 的名为 name 的属性值，保存在 ret 中，给生成的 GetPropXXX 方法用。
+// 保存的值和 g_object_get 取出的值一样，字符串、boxed 类型的值都是复制过的，
+// G_TYPE_POINTER 类型的值，比如 GList，不会被复制；
+// 如果 transferNothing 为 true，对象类型的值不会增加引用。
+func GetPropertyArgument(obj unsafe.Pointer, name string, ret *gi.Argument, transferNothing bool) {
+	cName := C.CString(name)
+	C._g_object_get_property_arg((*C.GObject)(obj), (*C.gchar)(cName), C.gpointer(ret),
+		C.gboolean(gi.Bool2Int(transferNothing)))
+	C.free(unsafe.Pointer(cName))
+}
+
+// SetPropertyArgument 把 arg 中的值设置为对象 obj 的名为 name 的属性值，给生成的 SetPropXXX 方法用。
+// arg 中的值按照属性的 GType 解释。
+func SetPropertyArgument(obj unsafe.Pointer, name string, arg gi.Argument) {
+	cName := C.CString(name)
+	C._g_object_set_property_arg((*C.GObject)(obj), (*C.gchar)(cName), C.gpointer(&arg))
+	C.free(unsafe.Pointer(cName))
+}
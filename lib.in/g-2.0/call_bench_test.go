@@ -0,0 +1,59 @@
+package g
+
+import (
+	"context"
+	"testing"
+
+	"github.com/electricface/go-gir/gi"
+)
+
+// 这些基准测试调用生成的函数，分别在 girgen -mode=ffi 和 -mode=cgo 生成的包中运行，可以比较两种模式的调用开销。
+// BenchmarkUnicharIsdigitInvoker 总是通过 libffi 调用，作为参照。
+
+func BenchmarkUnicharIsdigit(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if !UnicharIsdigit('7') {
+			b.Fatal("'7' is not digit")
+		}
+	}
+}
+
+func BenchmarkUnicharIsdigitInvoker(b *testing.B) {
+	bi := gi.DefaultRepository().FindByName("GLib", "unichar_isdigit")
+	if bi.P == nil {
+		b.Skip("not found GLib.unichar_isdigit")
+	}
+	defer bi.Unref()
+	invoker, err := gi.WrapFunctionInfo(bi.P).PrepInvoker()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		args := []gi.Argument{gi.NewUint32Argument('7')}
+		var ret gi.Argument
+		invoker.Call(args, &ret, nil)
+		if !ret.Bool() {
+			b.Fatal("'7' is not digit")
+		}
+	}
+}
+
+func BenchmarkStrHasPrefix(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if !StrHasPrefix("go-gir", "go") {
+			b.Fatal("wrong result")
+		}
+	}
+}
+
+func BenchmarkCancellableIsCancelled(b *testing.B) {
+	cancellable, release := CancellableFromContext(context.Background())
+	defer release()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if cancellable.IsCancelled() {
+			b.Fatal("cancelled")
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package g
+
+/*
+#cgo pkg-config: gio-2.0
+#include <gio/gio.h>
+*/
+import "C"
+import (
+	"context"
+	"sync"
+	"unsafe"
+)
+
+// CancellableFromContext 创建一个 Cancellable，在 ctx 结束时取消它。
+// 调用结束后必须调用 release，它停止监视 ctx 的 goroutine，并释放 Cancellable。
+func CancellableFromContext(ctx context.Context) (cancellable Cancellable, release func()) {
+	p := C.g_cancellable_new()
+	cancellable = WrapCancellable(unsafe.Pointer(p))
+
+	done := ctx.Done()
+	if done == nil {
+		// ctx 永远不会结束，不需要 goroutine
+		return cancellable, func() {
+			C.g_object_unref(C.gpointer(p))
+		}
+	}
+
+	stop := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		select {
+		case <-done:
+			C.g_cancellable_cancel(p)
+		case <-stop:
+		}
+	}()
+
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			close(stop)
+			// 等待 goroutine 退出之后才能释放 Cancellable
+			<-exited
+			C.g_object_unref(C.gpointer(p))
+		})
+	}
+	return
+}
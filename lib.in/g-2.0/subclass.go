@@ -0,0 +1,635 @@
+package g
+
+/*
+#include <glib-object.h>
+#include <stdlib.h>
+
+extern void goClassInit(gpointer g_class);
+extern void goInstanceInit(GTypeInstance *instance);
+extern void goGetProperty(GObject *object, GParamSpec *pspec, GValue *value);
+extern void goSetProperty(GObject *object, GParamSpec *pspec, GValue *value);
+extern void goFinalize(GObject *object);
+
+static void _g_go_get_property(GObject *object, guint property_id, GValue *value, GParamSpec *pspec) {
+	goGetProperty(object, pspec, value);
+}
+
+static void _g_go_set_property(GObject *object, guint property_id, const GValue *value,
+	GParamSpec *pspec) {
+	goSetProperty(object, pspec, (GValue*)value);
+}
+
+static void _g_go_finalize(GObject *object) {
+	goFinalize(object);
+	// 找到最近的不是 Go 定义的祖先类型的 finalize
+	GType type = G_OBJECT_TYPE(object);
+	GObjectClass *klass = G_OBJECT_CLASS(g_type_class_peek(type));
+	while (klass->finalize == _g_go_finalize) {
+		type = g_type_parent(type);
+		klass = G_OBJECT_CLASS(g_type_class_peek(type));
+	}
+	if (klass->finalize != NULL) {
+		klass->finalize(object);
+	}
+}
+
+static void _g_go_class_init(gpointer g_class, gpointer class_data) {
+	if (G_TYPE_IS_OBJECT(G_TYPE_FROM_CLASS(g_class))) {
+		// 属性的读写只会分派到安装属性的类，不影响父类型的属性。
+		GObjectClass *object_class = G_OBJECT_CLASS(g_class);
+		object_class->get_property = _g_go_get_property;
+		object_class->set_property = _g_go_set_property;
+		object_class->finalize = _g_go_finalize;
+	}
+	goClassInit(g_class);
+}
+
+static void _g_go_instance_init(GTypeInstance *instance, gpointer g_class) {
+	// 调用 instance_init 时，instance->g_class 被临时设置为正在初始化的类型的类，
+	// 所以在 Go 中可以通过 instance 知道是哪个类型的 instance_init。
+	goInstanceInit(instance);
+}
+
+static GType _g_register_subclass(const gchar *name, GType parent_type) {
+	GTypeQuery query;
+	g_type_query(parent_type, &query);
+	if (query.type == G_TYPE_INVALID) {
+		return G_TYPE_INVALID;
+	}
+	GTypeInfo info = {0};
+	info.class_size = query.class_size;
+	info.class_init = _g_go_class_init;
+	info.instance_size = query.instance_size;
+	info.instance_init = _g_go_instance_init;
+	return g_type_register_static(parent_type, name, &info, 0);
+}
+
+static GType _g_type_from_class(gpointer g_class) {
+	return G_TYPE_FROM_CLASS(g_class);
+}
+
+static GType _g_type_from_instance(gpointer instance) {
+	return G_TYPE_FROM_INSTANCE(instance);
+}
+
+static gboolean _g_value_is_valid(GValue *value) {
+	return G_IS_VALUE(value);
+}
+
+static GValue *_g_value_array_new(guint n) {
+	return g_new0(GValue, n);
+}
+
+static GValue *_g_value_array_index(GValue *values, guint i) {
+	return &values[i];
+}
+
+static GType _g_signal_query_param_type(GSignalQuery *query, guint i) {
+	return query->param_types[i] & ~G_SIGNAL_TYPE_STATIC_SCOPE;
+}
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/electricface/go-gir/gi"
+)
+
+// ClassInitFunc 在新类型的 class_init 中调用，klass 是新类型的类结构体的指针，
+// 可以在其中调用生成的 OverrideXXX 函数覆盖父类型的虚函数。
+type ClassInitFunc func(klass unsafe.Pointer)
+
+// InstanceInitFunc 在新类型的 instance_init 中调用，obj 是正在初始化的实例。
+type InstanceInitFunc func(obj Object)
+
+type subclassInfo struct {
+	classInit    ClassInitFunc
+	instanceInit InstanceInitFunc
+}
+
+var subclasses = struct {
+	sync.RWMutex
+	m map[gi.GType]*subclassInfo
+}{
+	m: make(map[gi.GType]*subclassInfo),
+}
+
+// RegisterSubclass 注册一个名为 name 的新 GType，父类型 parentType 可以是任何生成的对象类型，
+// classInit 和 instanceInit 可以为 nil。
+func RegisterSubclass(name string, parentType gi.GType, classInit ClassInitFunc,
+	instanceInit InstanceInitFunc) (gi.GType, error) {
+
+	cName := (*C.gchar)(C.CString(name))
+	defer C.free(unsafe.Pointer(cName))
+	if C.g_type_from_name(cName) != C.G_TYPE_INVALID {
+		return 0, fmt.Errorf("type %q already exists", name)
+	}
+
+	// class_init 在第一次使用类型的类时才会调用，所以先记录信息再注册类型。
+	subclasses.Lock()
+	defer subclasses.Unlock()
+	gType := gi.GType(C._g_register_subclass(cName, C.GType(parentType)))
+	if gType == 0 {
+		return 0, fmt.Errorf("failed to register type %q", name)
+	}
+	subclasses.m[gType] = &subclassInfo{
+		classInit:    classInit,
+		instanceInit: instanceInit,
+	}
+	return gType, nil
+}
+
+func getSubclassInfo(gType gi.GType) *subclassInfo {
+	subclasses.RLock()
+	info := subclasses.m[gType]
+	subclasses.RUnlock()
+	return info
+}
+
+//export goClassInit
+func goClassInit(klass C.gpointer) {
+	info := getSubclassInfo(gi.GType(C._g_type_from_class(klass)))
+	if info != nil && info.classInit != nil {
+		info.classInit(unsafe.Pointer(klass))
+	}
+}
+
+//export goInstanceInit
+func goInstanceInit(instance *C.GTypeInstance) {
+	info := getSubclassInfo(TypeFromInstance(unsafe.Pointer(instance)))
+	if info != nil && info.instanceInit != nil {
+		info.instanceInit(Object{P: unsafe.Pointer(instance)})
+	}
+}
+
+// TypeFromInstance 返回实例 instance 的类型
+func TypeFromInstance(instance unsafe.Pointer) gi.GType {
+	return gi.GType(C._g_type_from_instance(C.gpointer(instance)))
+}
+
+type vfuncKey struct {
+	gType gi.GType
+	name  string
+}
+
+var vfuncs = struct {
+	sync.RWMutex
+	m map[vfuncKey]func(interface{})
+}{
+	m: make(map[vfuncKey]func(interface{})),
+}
+
+// SetVFunc 记录类结构体 klass 所属的类型对虚函数 name 的 Go 实现，给生成的 OverrideXXX 函数用，
+// name 类似 GtkWidget.draw。
+func SetVFunc(klass unsafe.Pointer, name string, fn func(v interface{})) {
+	gType := gi.GType(C._g_type_from_class(C.gpointer(klass)))
+	vfuncs.Lock()
+	vfuncs.m[vfuncKey{gType: gType, name: name}] = fn
+	vfuncs.Unlock()
+}
+
+// FindVFunc 从类型 gType 开始向上查找虚函数 name 的 Go 实现，返回实现和实现它的类型。
+func FindVFunc(gType gi.GType, name string) (fn func(v interface{}), owner gi.GType) {
+	vfuncs.RLock()
+	defer vfuncs.RUnlock()
+	for gType != 0 {
+		fn = vfuncs.m[vfuncKey{gType: gType, name: name}]
+		if fn != nil {
+			return fn, gType
+		}
+		gType = gi.GType(C.g_type_parent(C.GType(gType)))
+	}
+	return nil, 0
+}
+
+// FindParentVFunc 从类型 gType 的父类型开始向上查找虚函数 name 的 Go 实现，用于链式调用父类型的实现。
+func FindParentVFunc(gType gi.GType, name string) (fn func(v interface{}), owner gi.GType) {
+	return FindVFunc(gi.GType(C.g_type_parent(C.GType(gType))), name)
+}
+
+var instances = struct {
+	sync.RWMutex
+	m map[unsafe.Pointer]interface{}
+}{
+	m: make(map[unsafe.Pointer]interface{}),
+}
+
+// SetInstanceData 把 Go 值 data 关联到 Go 定义的类型的实例 obj，一般在 InstanceInitFunc 中调用。
+// 属性的读写会分派给 data 实现的 PropertyGetter 和 PropertySetter 接口，实例被销毁时解除关联。
+func SetInstanceData(obj Object, data interface{}) {
+	instances.Lock()
+	instances.m[obj.P] = data
+	instances.Unlock()
+}
+
+// GetInstanceData 返回通过 SetInstanceData 关联到实例 obj 的 Go 值
+func GetInstanceData(obj Object) interface{} {
+	instances.RLock()
+	data := instances.m[obj.P]
+	instances.RUnlock()
+	return data
+}
+
+//export goFinalize
+func goFinalize(obj *C.GObject) {
+	instances.Lock()
+	delete(instances.m, unsafe.Pointer(obj))
+	instances.Unlock()
+}
+
+// PropertyGetter 由实例数据实现，用于读取 InstallProperties 安装的属性，name 是属性名。
+type PropertyGetter interface {
+	GetPropertyValue(name string) (interface{}, error)
+}
+
+// PropertySetter 由实例数据实现，用于设置 InstallProperties 安装的属性，name 是属性名。
+type PropertySetter interface {
+	SetPropertyValue(name string, value interface{}) error
+}
+
+func getParamSpecName(pspec *C.GParamSpec) string {
+	return C.GoString((*C.char)(unsafe.Pointer(C.g_param_spec_get_name(pspec))))
+}
+
+//export goGetProperty
+func goGetProperty(obj *C.GObject, pspec *C.GParamSpec, value *C.GValue) {
+	name := getParamSpecName(pspec)
+	getter, ok := GetInstanceData(Object{P: unsafe.Pointer(obj)}).(PropertyGetter)
+	if !ok {
+		_, _ = fmt.Fprintf(os.Stderr, "get property %q: instance data is not a PropertyGetter\n", name)
+		return
+	}
+	val, err := getter.GetPropertyValue(name)
+	if err == nil {
+		err = Value{P: unsafe.Pointer(value)}.Set(val)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "get property %q: %v\n", name, err)
+	}
+}
+
+//export goSetProperty
+func goSetProperty(obj *C.GObject, pspec *C.GParamSpec, value *C.GValue) {
+	name := getParamSpecName(pspec)
+	setter, ok := GetInstanceData(Object{P: unsafe.Pointer(obj)}).(PropertySetter)
+	if !ok {
+		_, _ = fmt.Fprintf(os.Stderr, "set property %q: instance data is not a PropertySetter\n", name)
+		return
+	}
+	val, err := Value{P: unsafe.Pointer(value)}.Get()
+	if err == nil {
+		err = setter.SetPropertyValue(name, val)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "set property %q: %v\n", name, err)
+	}
+}
+
+var propNextId uint32
+
+// InstallProperties 在 Go 定义的类型的类结构体 klass 中安装属性，需要在 ClassInitFunc 中调用。
+// props 是一个结构体或结构体指针，每个带有 gprop tag 的字段描述一个属性，字段的类型决定属性的类型，
+// 支持 bool, int8, uint8, int, int32, uint, uint32, int64, uint64, float32, float64, string,
+// gi.Enum, gi.Flags 和 Object。其他 tag 是可选的，比如：
+//
+//	Count int32 `gprop:"count" nick:"Count" blurb:"the count" min:"0" max:"100" default:"5"`
+//	Mode gi.Enum `gprop:"mode" gtype:"GtkOrientation" flags:"readable,writable,construct"`
+//
+// flags 默认为 readable,writable，gi.Enum 和 gi.Flags 类型的字段必须有 gtype tag。
+func InstallProperties(klass unsafe.Pointer, props interface{}) error {
+	rt := reflect.TypeOf(props)
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct {
+		return errors.New("props is not a struct")
+	}
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		name := field.Tag.Get("gprop")
+		if name == "" || name == "-" {
+			continue
+		}
+		pspec, err := newParamSpec(name, field)
+		if err != nil {
+			return fmt.Errorf("property %q: %v", name, err)
+		}
+		id := atomic.AddUint32(&propNextId, 1)
+		C.g_object_class_install_property((*C.GObjectClass)(klass), C.guint(id), pspec)
+	}
+	return nil
+}
+
+func getParamFlags(tag string) (flags C.GParamFlags, err error) {
+	if tag == "" {
+		return C.G_PARAM_READWRITE, nil
+	}
+	for _, word := range strings.Split(tag, ",") {
+		switch strings.TrimSpace(word) {
+		case "readable":
+			flags |= C.G_PARAM_READABLE
+		case "writable":
+			flags |= C.G_PARAM_WRITABLE
+		case "construct":
+			flags |= C.G_PARAM_CONSTRUCT
+		case "construct-only":
+			flags |= C.G_PARAM_CONSTRUCT_ONLY
+		case "explicit-notify":
+			flags |= C.G_PARAM_EXPLICIT_NOTIFY
+		case "deprecated":
+			flags |= C.G_PARAM_DEPRECATED
+		default:
+			return 0, fmt.Errorf("unknown flag %q", word)
+		}
+	}
+	return
+}
+
+// getTagGType 通过类型名获取 GType，类型必须已经注册了。
+func getTagGType(name string) (gi.GType, error) {
+	cName := (*C.gchar)(C.CString(name))
+	defer C.free(unsafe.Pointer(cName))
+	gType := gi.GType(C.g_type_from_name(cName))
+	if gType == 0 {
+		return 0, fmt.Errorf("unknown type %q", name)
+	}
+	return gType, nil
+}
+
+// tagNumbers 从 tag 中解析出最小值、最大值和默认值，没有设置的使用 min, max, def。
+func tagNumbers(tag reflect.StructTag, min, max, def float64) (float64, float64, float64, error) {
+	var err error
+	vals := []float64{min, max, def}
+	for i, key := range []string{"min", "max", "default"} {
+		str := tag.Get(key)
+		if str == "" {
+			continue
+		}
+		vals[i], err = strconv.ParseFloat(str, 64)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("bad %s %q: %v", key, str, err)
+		}
+	}
+	return vals[0], vals[1], vals[2], nil
+}
+
+// tagStrings 从 tag 中取出最小值、最大值和默认值的字符串，没有设置的使用 min, max, def。
+func tagStrings(tag reflect.StructTag, min, max, def string) []string {
+	vals := []string{min, max, def}
+	for i, key := range []string{"min", "max", "default"} {
+		if str := tag.Get(key); str != "" {
+			vals[i] = str
+		}
+	}
+	return vals
+}
+
+// tagInt64s 和 tagNumbers 类似，但是不经过 float64，避免丢失精度。
+func tagInt64s(tag reflect.StructTag) (vals [3]int64, err error) {
+	strs := tagStrings(tag, strconv.FormatInt(math.MinInt64, 10),
+		strconv.FormatInt(math.MaxInt64, 10), "0")
+	for i, str := range strs {
+		vals[i], err = strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// tagUint64s 和 tagNumbers 类似，但是不经过 float64，避免丢失精度。
+func tagUint64s(tag reflect.StructTag) (vals [3]uint64, err error) {
+	strs := tagStrings(tag, "0", strconv.FormatUint(math.MaxUint64, 10), "0")
+	for i, str := range strs {
+		vals[i], err = strconv.ParseUint(str, 10, 64)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func newParamSpec(name string, field reflect.StructField) (*C.GParamSpec, error) {
+	tag := field.Tag
+	flags, err := getParamFlags(tag.Get("flags"))
+	if err != nil {
+		return nil, err
+	}
+	nick := tag.Get("nick")
+	if nick == "" {
+		nick = name
+	}
+	cName := (*C.gchar)(C.CString(name))
+	defer C.free(unsafe.Pointer(cName))
+	cNick := (*C.gchar)(C.CString(nick))
+	defer C.free(unsafe.Pointer(cNick))
+	cBlurb := (*C.gchar)(C.CString(tag.Get("blurb")))
+	defer C.free(unsafe.Pointer(cBlurb))
+	defStr := tag.Get("default")
+
+	var pspec *C.GParamSpec
+	switch field.Type {
+	case reflect.TypeOf(Object{}):
+		objType := TYPE_OBJECT
+		if typeName := tag.Get("gtype"); typeName != "" {
+			objType, err = getTagGType(typeName)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return C.g_param_spec_object(cName, cNick, cBlurb, C.GType(objType), flags), nil
+
+	case reflect.TypeOf(gi.Enum(0)), reflect.TypeOf(gi.Flags(0)):
+		gType, err := getTagGType(tag.Get("gtype"))
+		if err != nil {
+			return nil, err
+		}
+		_, _, def, err := tagNumbers(tag, 0, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		if field.Type == reflect.TypeOf(gi.Enum(0)) {
+			pspec = C.g_param_spec_enum(cName, cNick, cBlurb, C.GType(gType), C.gint(def), flags)
+		} else {
+			pspec = C.g_param_spec_flags(cName, cNick, cBlurb, C.GType(gType), C.guint(def), flags)
+		}
+		return pspec, nil
+	}
+
+	switch field.Type.Kind() {
+	case reflect.Bool:
+		def := false
+		if defStr != "" {
+			def, err = strconv.ParseBool(defStr)
+			if err != nil {
+				return nil, fmt.Errorf("bad default %q: %v", defStr, err)
+			}
+		}
+		pspec = C.g_param_spec_boolean(cName, cNick, cBlurb, C.gboolean(gi.Bool2Int(def)), flags)
+
+	case reflect.String:
+		cDef := (*C.gchar)(C.CString(defStr))
+		defer C.free(unsafe.Pointer(cDef))
+		if defStr == "" {
+			cDef = nil
+		}
+		pspec = C.g_param_spec_string(cName, cNick, cBlurb, cDef, flags)
+
+	case reflect.Int8:
+		min, max, def, err := tagNumbers(tag, math.MinInt8, math.MaxInt8, 0)
+		if err != nil {
+			return nil, err
+		}
+		pspec = C.g_param_spec_char(cName, cNick, cBlurb, C.gint8(min), C.gint8(max), C.gint8(def), flags)
+
+	case reflect.Uint8:
+		min, max, def, err := tagNumbers(tag, 0, math.MaxUint8, 0)
+		if err != nil {
+			return nil, err
+		}
+		pspec = C.g_param_spec_uchar(cName, cNick, cBlurb, C.guint8(min), C.guint8(max), C.guint8(def), flags)
+
+	case reflect.Int, reflect.Int32:
+		min, max, def, err := tagNumbers(tag, math.MinInt32, math.MaxInt32, 0)
+		if err != nil {
+			return nil, err
+		}
+		pspec = C.g_param_spec_int(cName, cNick, cBlurb, C.gint(min), C.gint(max), C.gint(def), flags)
+
+	case reflect.Uint, reflect.Uint32:
+		min, max, def, err := tagNumbers(tag, 0, math.MaxUint32, 0)
+		if err != nil {
+			return nil, err
+		}
+		pspec = C.g_param_spec_uint(cName, cNick, cBlurb, C.guint(min), C.guint(max), C.guint(def), flags)
+
+	case reflect.Int64:
+		vals, err := tagInt64s(tag)
+		if err != nil {
+			return nil, err
+		}
+		pspec = C.g_param_spec_int64(cName, cNick, cBlurb, C.gint64(vals[0]), C.gint64(vals[1]),
+			C.gint64(vals[2]), flags)
+
+	case reflect.Uint64:
+		vals, err := tagUint64s(tag)
+		if err != nil {
+			return nil, err
+		}
+		pspec = C.g_param_spec_uint64(cName, cNick, cBlurb, C.guint64(vals[0]), C.guint64(vals[1]),
+			C.guint64(vals[2]), flags)
+
+	case reflect.Float32:
+		min, max, def, err := tagNumbers(tag, -math.MaxFloat32, math.MaxFloat32, 0)
+		if err != nil {
+			return nil, err
+		}
+		pspec = C.g_param_spec_float(cName, cNick, cBlurb, C.gfloat(min), C.gfloat(max), C.gfloat(def), flags)
+
+	case reflect.Float64:
+		min, max, def, err := tagNumbers(tag, -math.MaxFloat64, math.MaxFloat64, 0)
+		if err != nil {
+			return nil, err
+		}
+		pspec = C.g_param_spec_double(cName, cNick, cBlurb, C.gdouble(min), C.gdouble(max), C.gdouble(def), flags)
+
+	default:
+		return nil, fmt.Errorf("unsupported field type %v", field.Type)
+	}
+	return pspec, nil
+}
+
+// NewSignal 为 Go 定义的类型 gType 创建新信号 name，返回信号的 id，paramTypes 是信号参数的类型。
+// classHandler 是信号的默认处理函数，可以为 nil，它的要求和 ClosureNew 的参数 f 一样。
+func NewSignal(name string, gType gi.GType, flags SignalFlags, classHandler interface{},
+	returnType gi.GType, paramTypes ...gi.GType) (uint, error) {
+
+	cName := (*C.gchar)(C.CString(name))
+	defer C.free(unsafe.Pointer(cName))
+
+	var classClosure *C.GClosure
+	if classHandler != nil {
+		classClosure = ClosureNew(classHandler).native()
+	}
+
+	var cParamTypes *C.GType
+	if len(paramTypes) > 0 {
+		types := make([]C.GType, len(paramTypes))
+		for i, paramType := range paramTypes {
+			types[i] = C.GType(paramType)
+		}
+		cParamTypes = &types[0]
+	}
+
+	// c_marshaller 为 NULL，没有设置 marshal 的闭包使用 g_cclosure_marshal_generic。
+	id := C.g_signal_newv(cName, C.GType(gType), C.GSignalFlags(flags), classClosure,
+		nil, nil, nil, C.GType(returnType), C.guint(len(paramTypes)), cParamTypes)
+	if id == 0 {
+		return 0, fmt.Errorf("failed to create signal %q", name)
+	}
+	return uint(id), nil
+}
+
+// Emit 发出对象 v 的信号 detailedSignal，args 是信号的参数，返回信号处理函数的返回值。
+func (v Object) Emit(detailedSignal string, args ...interface{}) (interface{}, error) {
+	cName := (*C.gchar)(C.CString(detailedSignal))
+	defer C.free(unsafe.Pointer(cName))
+
+	var signalId C.guint
+	var detail C.GQuark
+	gType := C._g_type_from_instance(C.gpointer(v.P))
+	if C.g_signal_parse_name(cName, gType, &signalId, &detail, C.FALSE) == 0 {
+		return nil, fmt.Errorf("unknown signal %q", detailedSignal)
+	}
+	var query C.GSignalQuery
+	C.g_signal_query(signalId, &query)
+	if int(query.n_params) != len(args) {
+		return nil, fmt.Errorf("signal %q needs %d args, but got %d", detailedSignal,
+			query.n_params, len(args))
+	}
+
+	nValues := C.guint(len(args) + 1)
+	values := C._g_value_array_new(nValues)
+	defer func() {
+		for i := C.guint(0); i < nValues; i++ {
+			value := C._g_value_array_index(values, i)
+			if C._g_value_is_valid(value) != 0 {
+				C.g_value_unset(value)
+			}
+		}
+		C.g_free(C.gpointer(values))
+	}()
+
+	instance := C._g_value_array_index(values, 0)
+	C.g_value_init(instance, gType)
+	C.g_value_set_instance(instance, C.gpointer(v.P))
+	for i, arg := range args {
+		value := C._g_value_array_index(values, C.guint(i+1))
+		C.g_value_init(value, C._g_signal_query_param_type(&query, C.guint(i)))
+		err := Value{P: unsafe.Pointer(value)}.Set(arg)
+		if err != nil {
+			return nil, fmt.Errorf("signal %q arg %d: %v", detailedSignal, i, err)
+		}
+	}
+
+	retType := query.return_type &^ C.G_SIGNAL_TYPE_STATIC_SCOPE
+	if retType == C.G_TYPE_NONE {
+		C.g_signal_emitv(values, signalId, detail, nil)
+		return nil, nil
+	}
+	var ret C.GValue
+	C.g_value_init(&ret, retType)
+	defer C.g_value_unset(&ret)
+	C.g_signal_emitv(values, signalId, detail, &ret)
+	return Value{P: unsafe.Pointer(&ret)}.Get()
+}